@@ -4,7 +4,6 @@ import (
 	"archive/zip"
 	"fmt"
 	"github.com/arran4/arrans_overlay_workflow_builder/util"
-	"github.com/google/go-github/v62/github"
 	"github.com/probonopd/go-appimage/src/goappimage"
 	"log"
 	"os"
@@ -42,8 +41,8 @@ type AppImageFileInfo struct {
 	SuffixOnly       bool
 	CaseInsensitive  bool
 	KeywordDefaulted bool
-	// Required for the URL only atm:
-	ReleaseAsset *github.ReleaseAsset
+	// DownloadUrl is where the release file, or the archive it is in, is downloaded from
+	DownloadUrl string
 	// Unmatched
 	Unmatched []string
 
@@ -65,11 +64,7 @@ func ConfigAddAppImageGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix s
 		return fmt.Errorf("reading configuration file: %s: %w", toConfig, err)
 	}
 
-	for _, entry := range config {
-		if entry.EntryNumber >= ic.EntryNumber {
-			ic.EntryNumber = entry.EntryNumber + 1
-		}
-	}
+	ic.EntryNumber = NextEntryId(config)
 
 	log.Printf("Appending to config as entry id: %d", ic.EntryNumber)
 	if err := AppendToConfigurationFile(toConfig, ic); err != nil {
@@ -91,20 +86,31 @@ func ConfigViewAppImageGithubReleases(gitRepo, tagOverride, tagPrefix string) er
 }
 
 func GenerateAppImageGithubReleaseConfigEntry(gitRepo, tagOverride, tagPrefix string) (*InputConfig, error) {
-	repoName, ic, versions, tags, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, "-appimage", "Github AppImage Release")
+	return generateAppImageRepoReleaseConfigEntry(gitRepo, tagOverride, tagPrefix, "Github AppImage Release")
+}
+
+// generateAppImageRepoReleaseConfigEntry detects the entry from a release of a repository with a GitHub compatible API.
+func generateAppImageRepoReleaseConfigEntry(gitRepo, tagOverride, tagPrefix, sourceType string) (*InputConfig, error) {
+	repoName, ic, versions, tags, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, "-appimage", sourceType)
 	if err != nil {
 		return config, err
 	}
 
-	var wordMap = GroupAndSort(GenerateWordMeanings(repoName, versions, tags))
-
 	var files []*AppImageFileInfo
 	for _, asset := range releaseInfo.Assets {
 		files = append(files, &AppImageFileInfo{
-			Filename:     asset.GetName(),
-			ReleaseAsset: asset,
+			Filename:    asset.GetName(),
+			DownloadUrl: asset.GetBrowserDownloadURL(),
 		})
 	}
+	return detectAppImageReleaseConfigEntry(repoName, ic, versions, tags, files)
+}
+
+// detectAppImageReleaseConfigEntry works out the programs of the entry from the files of the release, archives are
+// searched if there are no app images outside them.
+func detectAppImageReleaseConfigEntry(repoName string, ic *InputConfig, versions, tags []string, files []*AppImageFileInfo) (*InputConfig, error) {
+	var wordMap = GroupAndSort(GenerateWordMeanings(repoName, versions, tags))
+
 	appImages, containers := AppImageFiles(files).ExtractAppImagesAndContainers(wordMap)
 	if len(appImages) == 0 && len(containers) > 0 {
 		log.Printf("No app images found, but some archives / compressed files")
@@ -151,7 +157,7 @@ func GenerateAppImageGithubReleaseConfigEntry(gitRepo, tagOverride, tagPrefix st
 }
 
 func (appImage *AppImageFileInfo) GetInformationFromAppImage(repoName string, ic *InputConfig) error {
-	url := appImage.ReleaseAsset.GetBrowserDownloadURL()
+	url := appImage.DownloadUrl
 	if appImage.tempFile == "" {
 		var err error
 		log.Printf("Downloading %s", url)
@@ -243,7 +249,7 @@ func (container *AppImageFileInfo) SearchArchiveForAppImageFiles() ([]*AppImageF
 		// Skip repo archives for the moment.
 		return nil, nil
 	}
-	url := container.ReleaseAsset.GetBrowserDownloadURL()
+	url := container.DownloadUrl
 	log.Printf("Downloading %s", url)
 	var err error
 	container.tempFile, err = util.DownloadUrlToTempFile(url)
@@ -287,10 +293,10 @@ func (container *AppImageFileInfo) SearchArchiveForAppImageFiles() ([]*AppImageF
 				}
 			}()
 			archivedFiles = append(archivedFiles, &AppImageFileInfo{
-				Container:    container.Filename,
-				Filename:     f.Name,
-				tempFile:     tmpFile,
-				ReleaseAsset: container.ReleaseAsset,
+				Container:   container.Filename,
+				Filename:    f.Name,
+				tempFile:    tmpFile,
+				DownloadUrl: container.DownloadUrl,
 			})
 		}
 	}
@@ -351,7 +357,7 @@ func (base *AppImageFileInfo) CompileMeanings(input []*FilenamePartMeaning) (*Ap
 		SuffixOnly: true,
 	}
 	if base != nil {
-		result.ReleaseAsset = base.ReleaseAsset
+		result.DownloadUrl = base.DownloadUrl
 		result.Container = base.Container
 		result.OriginalFilename = base.Filename
 		result.OS = base.OS
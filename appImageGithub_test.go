@@ -3,18 +3,17 @@ package arrans_overlay_workflow_builder
 import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
-	"github.com/google/go-github/v62/github"
 	"testing"
 )
 
 func TestCompileMeanings(t *testing.T) {
 	tests := []struct {
-		name         string
-		input        []*FilenamePartMeaning
-		releaseAsset *github.ReleaseAsset
-		filename     string
-		want         *AppImageFileInfo
-		ok           bool
+		name        string
+		input       []*FilenamePartMeaning
+		downloadUrl string
+		filename    string
+		want        *AppImageFileInfo
+		ok          bool
 	}{
 		{
 			name: "jan-linux-x86_64-0.5.1.AppImage",
@@ -29,8 +28,8 @@ func TestCompileMeanings(t *testing.T) {
 				{Separator: true, Captured: "."},
 				{AppImage: true, SuffixOnly: true, OS: "linux", Captured: "AppImage"},
 			},
-			releaseAsset: nil,
-			filename:     "jan-linux-x86_64-0.5.1.AppImage",
+			downloadUrl: "",
+			filename:    "jan-linux-x86_64-0.5.1.AppImage",
 			want: &AppImageFileInfo{
 				Keyword:          "~amd64",
 				OS:               "linux",
@@ -44,7 +43,7 @@ func TestCompileMeanings(t *testing.T) {
 				ProjectName:      true,
 				SuffixOnly:       true,
 				CaseInsensitive:  false,
-				ReleaseAsset:     nil,
+				DownloadUrl:      "",
 			},
 			ok: true,
 		},
@@ -57,8 +56,8 @@ func TestCompileMeanings(t *testing.T) {
 				{Separator: true, Captured: "."},
 				{AppImage: true, SuffixOnly: true, OS: "linux", Captured: "AppImage"},
 			},
-			releaseAsset: nil,
-			filename:     "appimaged-838-aarch64.AppImage",
+			downloadUrl: "",
+			filename:    "appimaged-838-aarch64.AppImage",
 			want: &AppImageFileInfo{
 				Keyword:          "~arm64",
 				OS:               "linux",
@@ -71,7 +70,7 @@ func TestCompileMeanings(t *testing.T) {
 				AppImage:         true,
 				SuffixOnly:       true,
 				CaseInsensitive:  false,
-				ReleaseAsset:     nil,
+				DownloadUrl:      "",
 			},
 			ok: true,
 		},
@@ -86,8 +85,8 @@ func TestCompileMeanings(t *testing.T) {
 				{Separator: true, Captured: "."},
 				{Unmatched: true, Captured: "zsync", SuffixOnly: true},
 			},
-			releaseAsset: nil,
-			filename:     "appimaged-838-aarch64.AppImage.zsync",
+			downloadUrl: "",
+			filename:    "appimaged-838-aarch64.AppImage.zsync",
 			want: &AppImageFileInfo{
 				Keyword:          "~arm64",
 				OS:               "linux",
@@ -100,7 +99,7 @@ func TestCompileMeanings(t *testing.T) {
 				AppImage:         true,
 				SuffixOnly:       true,
 				CaseInsensitive:  false,
-				ReleaseAsset:     nil,
+				DownloadUrl:      "",
 				Unmatched:        []string{"zsync"},
 			},
 			ok: true,
@@ -116,8 +115,8 @@ func TestCompileMeanings(t *testing.T) {
 				{Separator: true, Captured: "."},
 				{AppImage: true, SuffixOnly: true, OS: "linux", Captured: "AppImage"},
 			},
-			releaseAsset: nil,
-			filename:     "appimaged-838-aarch64-asdf.AppImage",
+			downloadUrl: "",
+			filename:    "appimaged-838-aarch64-asdf.AppImage",
 			want: &AppImageFileInfo{
 				Keyword:          "~arm64",
 				OS:               "linux",
@@ -130,7 +129,7 @@ func TestCompileMeanings(t *testing.T) {
 				AppImage:         true,
 				SuffixOnly:       true,
 				CaseInsensitive:  false,
-				ReleaseAsset:     nil,
+				DownloadUrl:      "",
 				Unmatched:        []string{"asdf"},
 			},
 			ok: true,
@@ -139,8 +138,8 @@ func TestCompileMeanings(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			base := &AppImageFileInfo{
-				ReleaseAsset: tt.releaseAsset,
-				Filename:     tt.filename,
+				DownloadUrl: tt.downloadUrl,
+				Filename:    tt.filename,
 			}
 			got, gotOk := base.CompileMeanings(tt.input)
 			if diff := cmp.Diff(got, tt.want, cmpopts.IgnoreUnexported(AppImageFileInfo{})); diff != "" {
@@ -9,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/arran4/arrans_overlay_workflow_builder/util"
-	"github.com/google/go-github/v62/github"
 	"io"
 	"log"
 	"os"
@@ -60,8 +59,8 @@ type BinaryReleaseFileInfo struct {
 	SuffixOnly       bool
 	CaseInsensitive  bool
 	KeywordDefaulted bool
-	// Required for the URL only atm:
-	ReleaseAsset *github.ReleaseAsset
+	// DownloadUrl is where the release file, or the archive it is in, is downloaded from
+	DownloadUrl string
 	// Unmatched
 	Unmatched []string
 
@@ -83,11 +82,7 @@ func ConfigAddBinaryGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix str
 		return fmt.Errorf("reading configuration file: %s: %w", toConfig, err)
 	}
 
-	for _, entry := range config {
-		if entry.EntryNumber >= ic.EntryNumber {
-			ic.EntryNumber = entry.EntryNumber + 1
-		}
-	}
+	ic.EntryNumber = NextEntryId(config)
 
 	log.Printf("Appending to config as entry id: %d", ic.EntryNumber)
 	if err := AppendToConfigurationFile(toConfig, ic); err != nil {
@@ -109,31 +104,65 @@ func ConfigViewBinaryGithubReleases(gitRepo, tagOverride, tagPrefix string) erro
 }
 
 func GenerateBinaryGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string) (*InputConfig, error) {
-	repoName, ic, versions, tags, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "-bin", "Github Binary Release")
+	return generateBinaryRepoReleaseConfigEntry(gitRepo, tagOverride, prefix, "Github Binary Release")
+}
+
+// generateBinaryRepoReleaseConfigEntry detects the entry from a release of a repository with a GitHub compatible API.
+func generateBinaryRepoReleaseConfigEntry(gitRepo, tagOverride, prefix, sourceType string) (*InputConfig, error) {
+	repoName, ic, versions, tags, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "-bin", sourceType)
 	if err != nil {
 		return config, err
 	}
 
-	var wordMap = GroupAndSort(GenerateWordMeanings(repoName, versions, tags))
-
 	var files []*BinaryReleaseFileInfo
 	for _, asset := range releaseInfo.Assets {
 		files = append(files, &BinaryReleaseFileInfo{
-			Filename:     asset.GetName(),
-			ReleaseAsset: asset,
+			Filename:    asset.GetName(),
+			DownloadUrl: asset.GetBrowserDownloadURL(),
 		})
 	}
+	return detectBinaryReleaseConfigEntry(repoName, ic, versions, tags, files)
+}
+
+// detectBinaryReleaseConfigEntry works out the programs of the entry from the files of the release, archives are searched
+// and suspected binaries are downloaded to check them.
+func detectBinaryReleaseConfigEntry(repoName string, ic *InputConfig, versions, tags []string, files []*BinaryReleaseFileInfo) (*InputConfig, error) {
+	var wordMap = GroupAndSort(GenerateWordMeanings(repoName, versions, tags))
+
 	rootFiles := BinaryReleaseFiles(files).FindFiles(wordMap, nil)
 	defer rootFiles.Free()
 	if len(rootFiles.Binaries) == 0 && len(rootFiles.CompressedArchives) > 0 {
 		log.Printf("No binaries found, but some archives / compressed files")
-		for _, container := range rootFiles.CompressedArchives {
+		// Debian and RPM packages are only used when the other archives have no binaries
+		containers := slices.Clone(rootFiles.CompressedArchives)
+		slices.SortStableFunc(containers, func(a, b *BinaryReleaseFileInfo) int {
+			switch {
+			case a.IsPackage() == b.IsPackage():
+				return 0
+			case a.IsPackage():
+				return 1
+			default:
+				return -1
+			}
+		})
+		archiveBinaries := 0
+		for _, container := range containers {
+			if container.IsPackage() && archiveBinaries > 0 {
+				log.Printf("Skipping: %s, already have binaries", container.Filename)
+				continue
+			}
 			log.Printf("Searching: %s", container.Filename)
 			archivedFiles, err := container.SearchArchiveForFiles()
 			if err != nil {
 				return nil, err
 			}
-			containerFiles := BinaryReleaseFiles(archivedFiles).FindFiles(wordMap, rootFiles)
+			var containerFiles *FileTypes
+			if container.IsPackage() {
+				containerFiles = BinaryReleaseFiles(archivedFiles).FindPackageFiles(container, rootFiles)
+			} else {
+				containerFiles = BinaryReleaseFiles(archivedFiles).FindFiles(wordMap, rootFiles)
+				archiveBinaries += len(containerFiles.Binaries)
+			}
 			for _, nce := range containerFiles.CompressedArchives {
 				if len(nce.tempFile) == 0 {
 					continue
@@ -188,11 +217,11 @@ func GenerateBinaryGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string)
 		otherProject, ok := archBinaryProgram[key]
 		if ok {
 			useFlag := p.ProgramName
-			if useFlag == ic.GithubRepo || useFlag == "" {
+			if useFlag == ic.RepoName() || useFlag == "" {
 				useFlag = otherProject.ProgramName
 				archBinaryProgram[key] = p
 			}
-			if useFlag != "" && useFlag != ic.GithubRepo {
+			if useFlag != "" && useFlag != ic.RepoName() {
 				alternativeUses = append(alternativeUses, keyword+":"+useFlag)
 			}
 		} else {
@@ -207,6 +236,14 @@ func GenerateBinaryGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string)
 			return nil, fmt.Errorf("unknown %s dependencies: %s", binary.Filename, strings.Join(unknownSymbols, ", "))
 		}
 		if binary.container != nil {
+			if p.DesktopFile == "" {
+				p.DesktopFile = binary.container.DesktopFileFor(binary.InstalledName)
+			}
+			for _, icon := range binary.container.Icons {
+				if !slices.Contains(p.Icons, icon) {
+					p.Icons = append(p.Icons, icon)
+				}
+			}
 			for _, doc := range binary.container.Documents {
 				if doc.Container != nil {
 					p.Documents[keyword] = append(p.Documents[keyword], []string{doc.Container.Filename, doc.ArchivePathname, doc.InstalledName})
@@ -248,11 +285,6 @@ func GenerateBinaryGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string)
 }
 
 func (brfi *BinaryReleaseFileInfo) SearchArchiveForFiles() ([]*BinaryReleaseFileInfo, error) {
-	switch strings.ToLower(strings.Join(brfi.Containers, ".")) {
-	case "deb", "rpm":
-		// Skip repo archives for the moment.
-		return nil, nil
-	}
 	url, err := brfi.FetchContent()
 	if err != nil {
 		return nil, err
@@ -312,10 +344,30 @@ func (brfi *BinaryReleaseFileInfo) SearchArchiveForFiles() ([]*BinaryReleaseFile
 				DirectoryName:   dir,
 				Filename:        fn,
 				tempFile:        tmpFile,
-				ReleaseAsset:    brfi.ReleaseAsset,
+				DownloadUrl:     brfi.DownloadUrl,
 				ExecutableBit:   (zfh.Mode & 0o0500) == 0o0500,
 			})
 		}
+	case "deb", "rpm":
+		f, err := os.Open(brfi.tempFile)
+		if err != nil {
+			return archivedFiles, fmt.Errorf("opening file: %s: %w", url, err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Printf("Error closing file: %s: %s", brfi.tempFile, err)
+			}
+		}()
+		pf := newPackageFiles(brfi)
+		if brfi.IsDeb() {
+			err = pf.readDeb(f)
+		} else {
+			err = pf.readRpm(f)
+		}
+		if err != nil {
+			return archivedFiles, fmt.Errorf("reading package: %s: %w", url, err)
+		}
+		archivedFiles = pf.Files()
 	case "zip":
 		zf, err := zip.OpenReader(brfi.tempFile)
 		if err != nil {
@@ -350,7 +402,7 @@ func (brfi *BinaryReleaseFileInfo) SearchArchiveForFiles() ([]*BinaryReleaseFile
 				Filename:        fn,
 				DirectoryName:   dir,
 				tempFile:        tmpFile,
-				ReleaseAsset:    brfi.ReleaseAsset,
+				DownloadUrl:     brfi.DownloadUrl,
 				ExecutableBit:   (f.Mode().Perm() & 0o500) == 0o500,
 			})
 		}
@@ -380,7 +432,7 @@ func (brfi *BinaryReleaseFileInfo) FetchContent() (string, error) {
 		return brfi.tempFile, nil
 	}
 	brfi.tempFileUsage++
-	url := brfi.ReleaseAsset.GetBrowserDownloadURL()
+	url := brfi.DownloadUrl
 	log.Printf("Downloading %s", url)
 	var err error
 	brfi.tempFile, err = util.DownloadUrlToTempFile(url)
@@ -403,6 +455,23 @@ type FileTypes struct {
 	Root                     *FileTypes
 	MightBeBinaries          []*BinaryReleaseFileInfo
 	Documents                []*BinaryReleaseFileInfo
+	// DesktopFiles are the desktop entries of a .deb or .rpm
+	DesktopFiles []*BinaryReleaseFileInfo
+	// Icons are where the icons of a .deb or .rpm are, hicolor-apps or pixmaps
+	Icons []string
+}
+
+// DesktopFileFor is the desktop file of the program, the one named after it, otherwise the first one.
+func (t *FileTypes) DesktopFileFor(installedName string) string {
+	for _, each := range t.DesktopFiles {
+		if each.Filename == installedName+".desktop" {
+			return each.Filename
+		}
+	}
+	if len(t.DesktopFiles) > 0 {
+		return t.DesktopFiles[0].Filename
+	}
+	return ""
 }
 
 func (t *FileTypes) CountBinaries() int {
@@ -560,7 +629,7 @@ func (bases BinaryReleaseFiles) FindFiles(wordMap map[string][]*GroupedFilenameP
 		switch {
 		case slices.ContainsFunc(compiled.Containers, func(s string) bool {
 			switch strings.ToLower(s) {
-			case "tar", "zip":
+			case "tar", "zip", "deb", "rpm":
 				return true
 			default:
 				return false
@@ -603,7 +672,7 @@ func (brfi *BinaryReleaseFileInfo) CompileMeanings(input []*FilenamePartMeaning,
 		container:  container,
 	}
 	if brfi != nil {
-		result.ReleaseAsset = brfi.ReleaseAsset
+		result.DownloadUrl = brfi.DownloadUrl
 		result.OriginalFilename = brfi.Filename
 		result.ArchivePathname = brfi.ArchivePathname
 		// So we can get `extended` and the like through
@@ -760,6 +829,21 @@ func (brfi *BinaryReleaseFileInfo) CheckMaybe() (bool, error) {
 	return true, nil
 }
 
+// IsDeb is true if the file is a Debian package.
+func (brfi *BinaryReleaseFileInfo) IsDeb() bool {
+	return strings.EqualFold(strings.Join(brfi.Containers, "."), "deb")
+}
+
+// IsRpm is true if the file is an RPM package.
+func (brfi *BinaryReleaseFileInfo) IsRpm() bool {
+	return strings.EqualFold(strings.Join(brfi.Containers, "."), "rpm")
+}
+
+// IsPackage is true if the file is a Debian or RPM package, which are searched by where the files are installed.
+func (brfi *BinaryReleaseFileInfo) IsPackage() bool {
+	return brfi.IsDeb() || brfi.IsRpm()
+}
+
 func (brfi *BinaryReleaseFileInfo) Free() {
 	brfi.close()
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/arran4/arrans_overlay_workflow_builder"
 	"log"
 	"os"
+	"strings"
 )
 
 var (
@@ -100,6 +101,7 @@ func (mac *MainArgConfig) cmdGenerate(args []string) error {
 type CmdGenerateGithubWorkflowsArgConfig struct {
 	*CmdGenerateArgConfig
 	InputFile *string
+	InputDir  *string
 	OutputDir *string
 }
 
@@ -109,12 +111,16 @@ func (mac *CmdGenerateArgConfig) cmdGenerateGithubWorkflows(args []string) error
 	}
 	fs := flag.NewFlagSet("", flag.ExitOnError)
 	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.InputDir = fs.String("input-dir", "", "A directory of .config files to use instead of the input file")
 	config.OutputDir = fs.String("output-dir", "./output", "Directory to output workflows")
 	if err := fs.Parse(args); err != nil {
 		return fmt.Errorf("parsing flags: %w", err)
 	}
 	switch fs.Arg(0) {
 	case "":
+		if config.InputDir != nil && *config.InputDir != "" {
+			return arrans_overlay_workflow_builder.GenerateGithubWorkflowsFromDir(*config.InputDir, *config.OutputDir, config.Version)
+		}
 		if config.InputFile == nil || *config.InputFile == "" {
 			return fmt.Errorf("input file argument missing")
 		}
@@ -147,10 +153,76 @@ func (mac *MainArgConfig) cmdConfig(args []string) error {
 		if err := config.cmdConfigView(fs.Args()[1:]); err != nil {
 			return fmt.Errorf("config view: %w", err)
 		}
+	case "lint":
+		if err := config.cmdConfigLint(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config lint: %w", err)
+		}
+	case "update":
+		if err := config.cmdConfigUpdate(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config update: %w", err)
+		}
+	case "remove":
+		if err := config.cmdConfigRemove(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config remove: %w", err)
+		}
+	case "rename":
+		if err := config.cmdConfigRename(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config rename: %w", err)
+		}
+	case "set":
+		if err := config.cmdConfigSet(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config set: %w", err)
+		}
+	case "export":
+		if err := config.cmdConfigExport(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config export: %w", err)
+		}
+	case "import":
+		if err := config.cmdConfigImport(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config import: %w", err)
+		}
+	case "list", "query":
+		if err := config.cmdConfigList(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config %s: %w", fs.Arg(0), err)
+		}
+	case "diff":
+		if err := config.cmdConfigDiff(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config diff: %w", err)
+		}
+	case "fmt":
+		if err := config.cmdConfigFmt(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config fmt: %w", err)
+		}
+	case "migrate":
+		if err := config.cmdConfigMigrate(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config migrate: %w", err)
+		}
+	case "schema":
+		if _, err := os.Stdout.Write(arrans_overlay_workflow_builder.InputConfigJsonSchema); err != nil {
+			return fmt.Errorf("config schema: %w", err)
+		}
+	case "workarounds":
+		if _, err := fmt.Print(arrans_overlay_workflow_builder.WorkaroundHelp()); err != nil {
+			return fmt.Errorf("config workarounds: %w", err)
+		}
 	default:
 		log.Printf("Unknown command %s", fs.Arg(0))
 		log.Printf("Try %s for %s", "add", "Adds an configuration to a configuration file.")
 		log.Printf("Try %s for %s", "view", "Provides a bunch of options for viewing.")
+		log.Printf("Try %s for %s", "lint", "Reports every problem in a configuration file.")
+		log.Printf("Try %s for %s", "update", "Re-detects the release files of an entry in a configuration file.")
+		log.Printf("Try %s for %s", "remove", "Removes an entry from a configuration file.")
+		log.Printf("Try %s for %s", "rename", "Changes the ebuild name or category of an entry in a configuration file.")
+		log.Printf("Try %s for %s", "set", "Sets or unsets a field or workaround of an entry in a configuration file.")
+		log.Printf("Try %s for %s", "export", "Writes a configuration file as JSON or YAML.")
+		log.Printf("Try %s for %s", "import", "Appends the entries in a JSON or YAML file to a configuration file.")
+		log.Printf("Try %s for %s", "list", "Lists the entries of a configuration file, filtered by type, category, name, workaround or dependency.")
+		log.Printf("Try %s for %s", "query", "The same as list.")
+		log.Printf("Try %s for %s", "diff", "Shows the entries added, removed and changed between two configuration files.")
+		log.Printf("Try %s for %s", "fmt", "Rewrites a configuration file in the canonical layout, -check only reports if it isn't.")
+		log.Printf("Try %s for %s", "migrate", "Rewrites a configuration file written for an older version in the current format.")
+		log.Printf("Try %s for %s", "schema", "Prints the JSON Schema of the export and import format.")
+		log.Printf("Try %s for %s", "workarounds", "Lists the workarounds an entry can use.")
 		os.Exit(-1)
 	}
 	return nil
@@ -177,10 +249,50 @@ func (mac *CmdConfigArgConfig) cmdConfigAdd(args []string) error {
 		if err := config.cmdConfigAddBinaryGithubReleases(fs.Args()[1:]); err != nil {
 			return fmt.Errorf("config add: %w", err)
 		}
+	case "github-release-source":
+		if err := config.cmdConfigAddSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "github-release-go-source":
+		if err := config.cmdConfigAddGoSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "github-release-rust-source":
+		if err := config.cmdConfigAddRustSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "github-release-llamafile":
+		if err := config.cmdConfigAddLlamafileGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "gitlab-release-appimage":
+		if err := config.cmdConfigAddGitlabReleases("GitLab AppImage Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "gitlab-release-binary":
+		if err := config.cmdConfigAddGitlabReleases("GitLab Binary Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "gitea-release-appimage":
+		if err := config.cmdConfigAddGiteaReleases("Gitea AppImage Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
+	case "gitea-release-binary":
+		if err := config.cmdConfigAddGiteaReleases("Gitea Binary Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config add: %w", err)
+		}
 	default:
 		log.Printf("Unknown command %s", fs.Arg(0))
 		log.Printf("Try %s for %s", "github-release-appimage", "To generate a config file from a github release with semantic version for AppImages.")
 		log.Printf("Try %s for %s", "github-release-binary", "To generate a config file from a github release with semantic version for Binary Releases.")
+		log.Printf("Try %s for %s", "github-release-source", "To generate a config file from a github release with semantic version built from source.")
+		log.Printf("Try %s for %s", "github-release-go-source", "To generate a config file from a github release with semantic version built from Go module source.")
+		log.Printf("Try %s for %s", "github-release-rust-source", "To generate a config file from a github release with semantic version built from Rust crate source.")
+		log.Printf("Try %s for %s", "github-release-llamafile", "To generate a config file from a github release with semantic version for llamafiles and other Actually Portable Executables.")
+		log.Printf("Try %s for %s", "gitlab-release-appimage", "To generate a config file from a gitlab release with semantic version for AppImages.")
+		log.Printf("Try %s for %s", "gitlab-release-binary", "To generate a config file from a gitlab release with semantic version for Binary Releases.")
+		log.Printf("Try %s for %s", "gitea-release-appimage", "To generate a config file from a gitea release with semantic version for AppImages.")
+		log.Printf("Try %s for %s", "gitea-release-binary", "To generate a config file from a gitea release with semantic version for Binary Releases.")
 		os.Exit(-1)
 	}
 	return nil
@@ -260,27 +372,35 @@ func (mac *CmdConfigAddArgConfig) cmdConfigAddBinaryGithubReleases(args []string
 	return nil
 }
 
-type CmdConfigViewArgConfig struct {
-	*CmdConfigArgConfig
+type CmdConfigAddSourceGithubReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
+	GithubUrl          *string
+	ConfigFile         *string
+	SelectedVersionTag *string
+	TagPrefix          *string
 }
 
-func (mac *CmdConfigArgConfig) cmdConfigView(args []string) error {
-	config := &CmdConfigViewArgConfig{
-		CmdConfigArgConfig: mac,
+func (mac *CmdConfigAddArgConfig) cmdConfigAddSourceGithubReleases(args []string) error {
+	config := &CmdConfigAddSourceGithubReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
 	}
 	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to add")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
 	if err := fs.Parse(args); err != nil {
 		return fmt.Errorf("parsing flags: %w", err)
 	}
 	switch fs.Arg(0) {
-	case "github-release-appimage":
-		if err := config.cmdConfigViewAppImageGithubReleases(fs.Args()[1:]); err != nil {
-			return fmt.Errorf("config view: %w", err)
+	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
 		}
-	case "github-release-binary":
-		if err := config.cmdConfigViewBinaryGithubReleases(fs.Args()[1:]); err != nil {
-			return fmt.Errorf("config view: %w", err)
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to add is missing")
 		}
+		return arrans_overlay_workflow_builder.ConfigAddSourceGithubReleases(*config.ConfigFile, *config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
 	default:
 		log.Printf("Unknown command %s", fs.Arg(0))
 		os.Exit(-1)
@@ -288,19 +408,21 @@ func (mac *CmdConfigArgConfig) cmdConfigView(args []string) error {
 	return nil
 }
 
-type CmdConfigViewAppImageGithubReleasesArgConfig struct {
-	*CmdConfigViewArgConfig
+type CmdConfigAddGoSourceGithubReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
 	GithubUrl          *string
+	ConfigFile         *string
 	SelectedVersionTag *string
 	TagPrefix          *string
 }
 
-func (mac *CmdConfigViewArgConfig) cmdConfigViewAppImageGithubReleases(args []string) error {
-	config := &CmdConfigViewAppImageGithubReleasesArgConfig{
-		CmdConfigViewArgConfig: mac,
+func (mac *CmdConfigAddArgConfig) cmdConfigAddGoSourceGithubReleases(args []string) error {
+	config := &CmdConfigAddGoSourceGithubReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
 	}
 	fs := flag.NewFlagSet("", flag.ExitOnError)
-	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to add")
 	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
 	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
 	if err := fs.Parse(args); err != nil {
@@ -308,31 +430,35 @@ func (mac *CmdConfigViewArgConfig) cmdConfigViewAppImageGithubReleases(args []st
 	}
 	switch fs.Arg(0) {
 	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
 		if config.GithubUrl == nil || *config.GithubUrl == "" {
-			return fmt.Errorf("github URL to view is missing")
+			return fmt.Errorf("github URL to add is missing")
 		}
-		return arrans_overlay_workflow_builder.ConfigViewAppImageGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+		return arrans_overlay_workflow_builder.ConfigAddGoSourceGithubReleases(*config.ConfigFile, *config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
 	default:
 		log.Printf("Unknown command %s", fs.Arg(0))
-		log.Printf("Try %s for %s", "github-appimage", "Views an addition to a configuration file for a particular query.")
 		os.Exit(-1)
 	}
 	return nil
 }
 
-type CmdConfigViewBinaryGithubReleasesArgConfig struct {
-	*CmdConfigViewArgConfig
+type CmdConfigAddRustSourceGithubReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
 	GithubUrl          *string
+	ConfigFile         *string
 	SelectedVersionTag *string
 	TagPrefix          *string
 }
 
-func (mac *CmdConfigViewArgConfig) cmdConfigViewBinaryGithubReleases(args []string) error {
-	config := &CmdConfigViewBinaryGithubReleasesArgConfig{
-		CmdConfigViewArgConfig: mac,
+func (mac *CmdConfigAddArgConfig) cmdConfigAddRustSourceGithubReleases(args []string) error {
+	config := &CmdConfigAddRustSourceGithubReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
 	}
 	fs := flag.NewFlagSet("", flag.ExitOnError)
-	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to add")
 	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
 	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
 	if err := fs.Parse(args); err != nil {
@@ -340,13 +466,806 @@ func (mac *CmdConfigViewArgConfig) cmdConfigViewBinaryGithubReleases(args []stri
 	}
 	switch fs.Arg(0) {
 	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
 		if config.GithubUrl == nil || *config.GithubUrl == "" {
-			return fmt.Errorf("github URL to view is missing")
+			return fmt.Errorf("github URL to add is missing")
 		}
-		return arrans_overlay_workflow_builder.ConfigViewBinaryGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+		return arrans_overlay_workflow_builder.ConfigAddRustSourceGithubReleases(*config.ConfigFile, *config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigAddLlamafileGithubReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
+	GithubUrl          *string
+	ConfigFile         *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigAddArgConfig) cmdConfigAddLlamafileGithubReleases(args []string) error {
+	config := &CmdConfigAddLlamafileGithubReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to add")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to add is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigAddLlamafileGithubReleases(*config.ConfigFile, *config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigAddGitlabReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
+	GitlabUrl          *string
+	ConfigFile         *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigAddArgConfig) cmdConfigAddGitlabReleases(sourceType string, args []string) error {
+	config := &CmdConfigAddGitlabReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GitlabUrl = fs.String("gitlab-url", "https://gitlab.com/group/project/", "The gitlab project URL to add, self-hosted instances work too")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
+		if config.GitlabUrl == nil || *config.GitlabUrl == "" {
+			return fmt.Errorf("gitlab URL to add is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigAddGitlabReleases(*config.ConfigFile, sourceType, *config.GitlabUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigAddGiteaReleasesArgConfig struct {
+	*CmdConfigAddArgConfig
+	GiteaUrl           *string
+	ConfigFile         *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigAddArgConfig) cmdConfigAddGiteaReleases(sourceType string, args []string) error {
+	config := &CmdConfigAddGiteaReleasesArgConfig{
+		CmdConfigAddArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.ConfigFile = fs.String("to", "input.config", "The input with config")
+	config.GiteaUrl = fs.String("gitea-url", "https://codeberg.org/owner/repo/", "The Gitea, Forgejo or Codeberg repository URL to add, self-hosted instances work too")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
+		if config.GiteaUrl == nil || *config.GiteaUrl == "" {
+			return fmt.Errorf("gitea URL to add is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigAddGiteaReleases(*config.ConfigFile, sourceType, *config.GiteaUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigLintArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile *string
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigLint(args []string) error {
+	config := &CmdConfigLintArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigLint(*config.InputFile)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigDiff(args []string) error {
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() != 2 {
+		log.Printf("Usage: config diff old.config new.config")
+		os.Exit(-1)
+	}
+	return arrans_overlay_workflow_builder.ConfigDiff(fs.Arg(0), fs.Arg(1))
+}
+
+type CmdConfigFmtArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile *string
+	Check     *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigFmt(args []string) error {
+	config := &CmdConfigFmtArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Check = fs.Bool("check", false, "Only show the changes and fail if there are any")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigFmt(*config.InputFile, *config.Check)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigMigrateArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile *string
+	DryRun    *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigMigrate(args []string) error {
+	config := &CmdConfigMigrateArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.DryRun = fs.Bool("dry-run", false, "Only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigMigrate(*config.InputFile, *config.DryRun)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigUpdateArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile  *string
+	Id         *int
+	EbuildName *string
+	GithubUrl  *string
+	DryRun     *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigUpdate(args []string) error {
+	config := &CmdConfigUpdateArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Id = fs.Int("id", 0, "The Id of the entry to update")
+	config.EbuildName = fs.String("ebuild", "", "The ebuild name of the entry to update")
+	config.GithubUrl = fs.String("github-url", "", "The github URL of the entry to update")
+	config.DryRun = fs.Bool("dry-run", false, "Only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		selector := &arrans_overlay_workflow_builder.EntrySelector{Id: *config.Id, EbuildName: *config.EbuildName, GithubUrl: *config.GithubUrl}
+		return arrans_overlay_workflow_builder.ConfigUpdate(*config.InputFile, selector, *config.DryRun)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigRemoveArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile  *string
+	Id         *int
+	EbuildName *string
+	GithubUrl  *string
+	DryRun     *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigRemove(args []string) error {
+	config := &CmdConfigRemoveArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Id = fs.Int("id", 0, "The Id of the entry to remove")
+	config.EbuildName = fs.String("ebuild", "", "The ebuild name of the entry to remove")
+	config.GithubUrl = fs.String("github-url", "", "The github URL of the entry to remove")
+	config.DryRun = fs.Bool("dry-run", false, "Only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		selector := &arrans_overlay_workflow_builder.EntrySelector{Id: *config.Id, EbuildName: *config.EbuildName, GithubUrl: *config.GithubUrl}
+		return arrans_overlay_workflow_builder.ConfigRemove(*config.InputFile, selector, *config.DryRun)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigRenameArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile     *string
+	Id            *int
+	EbuildName    *string
+	GithubUrl     *string
+	NewEbuildName *string
+	NewCategory   *string
+	DryRun        *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigRename(args []string) error {
+	config := &CmdConfigRenameArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Id = fs.Int("id", 0, "The Id of the entry to rename")
+	config.EbuildName = fs.String("ebuild", "", "The ebuild name of the entry to rename")
+	config.GithubUrl = fs.String("github-url", "", "The github URL of the entry to rename")
+	config.NewEbuildName = fs.String("to-ebuild", "", "The new ebuild name")
+	config.NewCategory = fs.String("to-category", "", "The new category")
+	config.DryRun = fs.Bool("dry-run", false, "Only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		selector := &arrans_overlay_workflow_builder.EntrySelector{Id: *config.Id, EbuildName: *config.EbuildName, GithubUrl: *config.GithubUrl}
+		return arrans_overlay_workflow_builder.ConfigRename(*config.InputFile, selector, *config.NewEbuildName, *config.NewCategory, *config.DryRun)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigSetArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile  *string
+	Id         *int
+	EbuildName *string
+	GithubUrl  *string
+	Field      *string
+	Workaround *string
+	Value      *string
+	Unset      *bool
+	DryRun     *bool
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigSet(args []string) error {
+	config := &CmdConfigSetArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Id = fs.Int("id", 0, "The Id of the entry to change")
+	config.EbuildName = fs.String("ebuild", "", "The ebuild name of the entry to change")
+	config.GithubUrl = fs.String("github-url", "", "The github URL of the entry to change")
+	config.Field = fs.String("field", "", "The entry field to set, such as Description")
+	config.Workaround = fs.String("workaround", "", fmt.Sprintf("The workaround to set, one of: %s", strings.Join(arrans_overlay_workflow_builder.WorkaroundNames(), ", ")))
+	config.Value = fs.String("value", "", "The value to set")
+	config.Unset = fs.Bool("unset", false, "Remove the field or workaround instead of setting it")
+	config.DryRun = fs.Bool("dry-run", false, "Only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		selector := &arrans_overlay_workflow_builder.EntrySelector{Id: *config.Id, EbuildName: *config.EbuildName, GithubUrl: *config.GithubUrl}
+		switch {
+		case *config.Field != "" && *config.Workaround != "":
+			return fmt.Errorf("only one of field or workaround can be set at a time")
+		case *config.Field != "":
+			return arrans_overlay_workflow_builder.ConfigSetField(*config.InputFile, selector, *config.Field, *config.Value, *config.Unset, *config.DryRun)
+		case *config.Workaround != "":
+			return arrans_overlay_workflow_builder.ConfigSetWorkaround(*config.InputFile, selector, *config.Workaround, *config.Value, *config.Unset, *config.DryRun)
+		default:
+			return fmt.Errorf("field or workaround to set is missing")
+		}
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigExportArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile  *string
+	Format     *string
+	OutputFile *string
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigExport(args []string) error {
+	config := &CmdConfigExportArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Format = fs.String("format", "json", "The format to export as: json or yaml")
+	config.OutputFile = fs.String("output-file", "-", "The file to write to, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigExportFile(*config.InputFile, *config.Format, *config.OutputFile)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigListArgConfig struct {
+	*CmdConfigArgConfig
+	InputFile  *string
+	Format     *string
+	Type       *string
+	Category   *string
+	Name       *string
+	Workaround *string
+	Dependency *string
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigList(args []string) error {
+	config := &CmdConfigListArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.InputFile = fs.String("input-file", "input.config", "The input with config")
+	config.Format = fs.String("format", "table", fmt.Sprintf("The format to write the entries in: %s", strings.Join(arrans_overlay_workflow_builder.ConfigQueryFormats, ", ")))
+	config.Type = fs.String("type", "", "Only entries of this Type, such as \"Github Binary Release\"")
+	config.Category = fs.String("category", "", "Only entries in this Category")
+	config.Name = fs.String("name", "", "Only entries with an ebuild name matching this glob, such as \"*-appimage\"")
+	config.Workaround = fs.String("workaround", "", "Only entries with this workaround, such as \"Tag Prefix\"")
+	config.Dependency = fs.String("dependency", "", "Only entries with a program depending on this package, such as x11-libs/gtk+")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.InputFile == nil || *config.InputFile == "" {
+			return fmt.Errorf("input file argument missing")
+		}
+		query := &arrans_overlay_workflow_builder.ConfigQuery{
+			Type:       *config.Type,
+			Category:   *config.Category,
+			Name:       *config.Name,
+			Workaround: *config.Workaround,
+			Dependency: *config.Dependency,
+		}
+		return arrans_overlay_workflow_builder.ConfigList(*config.InputFile, query, *config.Format)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigImportArgConfig struct {
+	*CmdConfigArgConfig
+	ConfigFile *string
+	Format     *string
+	From       *string
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigImport(args []string) error {
+	config := &CmdConfigImportArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.ConfigFile = fs.String("to", "input.config", "The config to append to")
+	config.Format = fs.String("format", "", "The format of the file to import: json or yaml, worked out from the extension if empty")
+	config.From = fs.String("from", "", "The JSON or YAML file to import")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.ConfigFile == nil || *config.ConfigFile == "" {
+			return fmt.Errorf("config file to modify argument missing")
+		}
+		if config.From == nil || *config.From == "" {
+			return fmt.Errorf("file to import is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigImportFile(*config.ConfigFile, *config.Format, *config.From)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewArgConfig struct {
+	*CmdConfigArgConfig
+}
+
+func (mac *CmdConfigArgConfig) cmdConfigView(args []string) error {
+	config := &CmdConfigViewArgConfig{
+		CmdConfigArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "github-release-appimage":
+		if err := config.cmdConfigViewAppImageGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "github-release-binary":
+		if err := config.cmdConfigViewBinaryGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "github-release-source":
+		if err := config.cmdConfigViewSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "github-release-go-source":
+		if err := config.cmdConfigViewGoSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "github-release-rust-source":
+		if err := config.cmdConfigViewRustSourceGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "github-release-llamafile":
+		if err := config.cmdConfigViewLlamafileGithubReleases(fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "gitlab-release-appimage":
+		if err := config.cmdConfigViewGitlabReleases("GitLab AppImage Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "gitlab-release-binary":
+		if err := config.cmdConfigViewGitlabReleases("GitLab Binary Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "gitea-release-appimage":
+		if err := config.cmdConfigViewGiteaReleases("Gitea AppImage Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	case "gitea-release-binary":
+		if err := config.cmdConfigViewGiteaReleases("Gitea Binary Release", fs.Args()[1:]); err != nil {
+			return fmt.Errorf("config view: %w", err)
+		}
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewGitlabReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GitlabUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewGitlabReleases(sourceType string, args []string) error {
+	config := &CmdConfigViewGitlabReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GitlabUrl = fs.String("gitlab-url", "https://gitlab.com/group/project/", "The gitlab project URL to view, self-hosted instances work too")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GitlabUrl == nil || *config.GitlabUrl == "" {
+			return fmt.Errorf("gitlab URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewGitlabReleases(sourceType, *config.GitlabUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewGiteaReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GiteaUrl           *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewGiteaReleases(sourceType string, args []string) error {
+	config := &CmdConfigViewGiteaReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GiteaUrl = fs.String("gitea-url", "https://codeberg.org/owner/repo/", "The Gitea, Forgejo or Codeberg repository URL to view, self-hosted instances work too")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GiteaUrl == nil || *config.GiteaUrl == "" {
+			return fmt.Errorf("gitea URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewGiteaReleases(sourceType, *config.GiteaUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewAppImageGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewAppImageGithubReleases(args []string) error {
+	config := &CmdConfigViewAppImageGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewAppImageGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		log.Printf("Try %s for %s", "github-appimage", "Views an addition to a configuration file for a particular query.")
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewBinaryGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewBinaryGithubReleases(args []string) error {
+	config := &CmdConfigViewBinaryGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewBinaryGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		log.Printf("Try %s for %s", "github-binary", "Views an addition to a configuration file for a particular query.")
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewSourceGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewSourceGithubReleases(args []string) error {
+	config := &CmdConfigViewSourceGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewSourceGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewGoSourceGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewGoSourceGithubReleases(args []string) error {
+	config := &CmdConfigViewGoSourceGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewGoSourceGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewRustSourceGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewRustSourceGithubReleases(args []string) error {
+	config := &CmdConfigViewRustSourceGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewRustSourceGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
+	default:
+		log.Printf("Unknown command %s", fs.Arg(0))
+		os.Exit(-1)
+	}
+	return nil
+}
+
+type CmdConfigViewLlamafileGithubReleasesArgConfig struct {
+	*CmdConfigViewArgConfig
+	GithubUrl          *string
+	SelectedVersionTag *string
+	TagPrefix          *string
+}
+
+func (mac *CmdConfigViewArgConfig) cmdConfigViewLlamafileGithubReleases(args []string) error {
+	config := &CmdConfigViewLlamafileGithubReleasesArgConfig{
+		CmdConfigViewArgConfig: mac,
+	}
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	config.GithubUrl = fs.String("github-url", "https://github.com/owner/repo/", "The github URL to view")
+	config.SelectedVersionTag = fs.String("version-tag", "", "Version / tag override")
+	config.TagPrefix = fs.String("tag-prefix", "", "Tag prefix for app to select on and remove")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	switch fs.Arg(0) {
+	case "":
+		if config.GithubUrl == nil || *config.GithubUrl == "" {
+			return fmt.Errorf("github URL to view is missing")
+		}
+		return arrans_overlay_workflow_builder.ConfigViewLlamafileGithubReleases(*config.GithubUrl, *config.SelectedVersionTag, *config.TagPrefix)
 	default:
 		log.Printf("Unknown command %s", fs.Arg(0))
-		log.Printf("Try %s for %s", "github-binary", "Views an addition to a configuration file for a particular query.")
 		os.Exit(-1)
 	}
 	return nil
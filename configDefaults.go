@@ -0,0 +1,231 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// defaultsFieldKeys are the fields a `Defaults` block can give a value to.
+	defaultsFieldKeys = []string{
+		"Category",
+		"License",
+		"Dependencies",
+		"Workaround",
+		"EbuildVariable",
+	}
+	ebuildVariableNameRegexp = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+	// generatedEbuildVariables are written by the templates from other fields so can't be set with `EbuildVariable`.
+	generatedEbuildVariables = []string{
+		"EAPI",
+		"DESCRIPTION",
+		"HOMEPAGE",
+		"IUSE",
+		"REQUIRED_USE",
+		"RDEPEND",
+		"SRC_URI",
+		"S",
+	}
+)
+
+// InputConfigDefaults are the values a `Defaults` block gives the entries after it, including the entries of files
+// included after it. An entry overrides a default by setting the field itself, workarounds and ebuild variables are
+// overridden by name and a workaround is removed with `Workaround -Name`.
+type InputConfigDefaults struct {
+	Category        string
+	License         string
+	Dependencies    []string
+	Workarounds     map[string]string
+	EbuildVariables map[string]string
+}
+
+func (d *InputConfigDefaults) clone() *InputConfigDefaults {
+	if d == nil {
+		return &InputConfigDefaults{}
+	}
+	return &InputConfigDefaults{
+		Category:        d.Category,
+		License:         d.License,
+		Dependencies:    slices.Clone(d.Dependencies),
+		Workarounds:     maps.Clone(d.Workarounds),
+		EbuildVariables: maps.Clone(d.EbuildVariables),
+	}
+}
+
+// Merge returns a copy of the defaults with the fields of a `Defaults` block set over the top of them.
+func (d *InputConfigDefaults) Merge(fields map[string][]string) (*InputConfigDefaults, error) {
+	result := d.clone()
+	for key := range fields {
+		if !slices.Contains(defaultsFieldKeys, key) {
+			return nil, fmt.Errorf("%s can't be used in Defaults, use one of: %s", key, strings.Join(defaultsFieldKeys, ", "))
+		}
+	}
+	if category, _ := emptyOrLast(fields["Category"]); category != "" {
+		result.Category = category
+	}
+	if license, _ := emptyOrLast(fields["License"]); license != "" {
+		result.License = license
+	}
+	if len(fields["Dependencies"]) > 0 {
+		result.Dependencies, _ = emptyOrAppendStringArray(nil, fields["Dependencies"])
+	}
+	workarounds, err := parseOptionalMapType1(fields["Workaround"])
+	if err != nil {
+		return nil, fmt.Errorf("on Workaround: %v: %w", fields["Workaround"], err)
+	}
+	ebuildVariables, err := parseEbuildVariables(fields["EbuildVariable"])
+	if err != nil {
+		return nil, fmt.Errorf("on EbuildVariable: %v: %w", fields["EbuildVariable"], err)
+	}
+	for _, name := range cutWorkaroundRemovals(workarounds) {
+		delete(workarounds, name)
+		delete(result.Workarounds, name)
+	}
+	if err := validateWorkarounds(workarounds); err != nil {
+		return nil, err
+	}
+	if len(workarounds) > 0 {
+		if result.Workarounds == nil {
+			result.Workarounds = map[string]string{}
+		}
+		maps.Copy(result.Workarounds, workarounds)
+	}
+	if len(ebuildVariables) > 0 {
+		if result.EbuildVariables == nil {
+			result.EbuildVariables = map[string]string{}
+		}
+		maps.Copy(result.EbuildVariables, ebuildVariables)
+	}
+	return result, nil
+}
+
+func (d *InputConfigDefaults) category() string {
+	if d == nil || d.Category == "" {
+		return DefaultCategory
+	}
+	return d.Category
+}
+
+func (d *InputConfigDefaults) license() string {
+	if d == nil || d.License == "" {
+		return DefaultLicense
+	}
+	return d.License
+}
+
+func (d *InputConfigDefaults) workarounds() map[string]string {
+	if d == nil {
+		return nil
+	}
+	return d.Workarounds
+}
+
+// workaroundLines returns the default workarounds as `Workaround` values so the entry's own lines can override them.
+func (d *InputConfigDefaults) workaroundLines() []string {
+	if d == nil {
+		return nil
+	}
+	return arrowLines(d.Workarounds, true)
+}
+
+func (d *InputConfigDefaults) ebuildVariableLines() []string {
+	if d == nil {
+		return nil
+	}
+	return arrowLines(d.EbuildVariables, false)
+}
+
+func arrowLines(values map[string]string, optional bool) []string {
+	var result []string
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if optional && values[name] == "" {
+			result = append(result, name)
+		} else {
+			result = append(result, fmt.Sprintf("%s => %s", name, values[name]))
+		}
+	}
+	return result
+}
+
+// applyDependencies gives every program of an entry which doesn't have its own `Dependencies` the default ones. The
+// unnamed program only gets them if it has other fields, otherwise it would become a program of its own.
+func (d *InputConfigDefaults) applyDependencies(parseFields map[string][]string, parseProgramFields map[string]map[string][]string) {
+	if d == nil || len(d.Dependencies) == 0 {
+		return
+	}
+	dependencies := strings.Join(d.Dependencies, " ")
+	hasUnnamedProgram := slices.ContainsFunc(programFieldKeys, func(key string) bool {
+		return key != "ProgramName" && len(parseFields[key]) > 0
+	})
+	if hasUnnamedProgram && len(parseFields["Dependencies"]) == 0 {
+		parseFields["Dependencies"] = []string{dependencies}
+	}
+	for _, programFields := range parseProgramFields {
+		if len(programFields["Dependencies"]) == 0 {
+			programFields["Dependencies"] = []string{dependencies}
+		}
+	}
+}
+
+// parseEbuildVariables parses `NAME => value` lines, the value can be empty but the `=>` can't be left out.
+func parseEbuildVariables(a []string) (map[string]string, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	result, err := parseMapType1(a)
+	if err != nil {
+		return nil, err
+	}
+	for name := range result {
+		if err := ValidateEbuildVariableName(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ValidateEbuildVariableName returns an error if the name isn't a shell variable name in upper case.
+func ValidateEbuildVariableName(name string) error {
+	if !ebuildVariableNameRegexp.MatchString(name) {
+		return fmt.Errorf("%q isn't an ebuild variable name, use upper case letters, digits and _", name)
+	}
+	return nil
+}
+
+// EbuildVariableNames returns the names of the entry's ebuild variables in order.
+func (ic *InputConfig) EbuildVariableNames() []string {
+	var names []string
+	for name := range ic.EbuildVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsDefaults is true for a block starting with a `Defaults` line, comments before it are allowed.
+func (cb *ConfigBlock) IsDefaults() bool {
+	for _, line := range cb.Lines {
+		if line.IsComment() {
+			continue
+		}
+		return line.Key() == "Defaults"
+	}
+	return false
+}
+
+// Defaults returns the inherited defaults with the fields of the `Defaults` block set over the top of them.
+func (cb *ConfigBlock) Defaults(inherited *InputConfigDefaults) (*InputConfigDefaults, error) {
+	fields := map[string][]string{}
+	for _, line := range cb.Lines {
+		switch line.Key() {
+		case "", "Defaults":
+			continue
+		}
+		fields[line.Key()] = append(fields[line.Key()], line.Value())
+	}
+	return inherited.Merge(fields)
+}
@@ -0,0 +1,343 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDefaultsData = `# Shared settings
+Defaults
+Category dev-util
+License MIT
+Dependencies sys-libs/glibc
+Workaround Semantic Version Without V
+EbuildVariable KEYWORDS => ~amd64 ~arm64
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/gohugoio/hugo
+Category www-apps
+License Apache License 2.0
+Workaround Semantic Version Without V => ignored
+Workaround Tag Prefix => hugo-
+EbuildVariable KEYWORDS => ~amd64
+EbuildVariable RESTRICT => mirror
+Binary amd64=>hugo_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+ProgramName extended
+Dependencies sys-libs/glibc sys-devel/gcc
+Binary amd64=>hugo_extended_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+ProgramName deploy
+Binary amd64=>hugo_deploy_${VERSION}_linux-amd64.tar.gz > hugo > hugo-deploy
+
+Defaults
+Category app-misc
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`
+
+func TestParseDefaults(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testDefaultsData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got []string
+	for _, ic := range ics {
+		got = append(got, ic.String())
+	}
+	want := []string{
+		`Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+EbuildName g2-bin.ebuild
+License MIT
+Workaround Semantic Version Without V
+EbuildVariable KEYWORDS => ~amd64 ~arm64
+Dependencies sys-libs/glibc
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`,
+		`Type Github Binary Release
+GithubProjectUrl https://github.com/gohugoio/hugo
+Category www-apps
+EbuildName hugo-bin.ebuild
+License Apache License 2.0
+Workaround Semantic Version Without V => ignored
+Workaround Tag Prefix => hugo-
+EbuildVariable KEYWORDS => ~amd64
+EbuildVariable RESTRICT => mirror
+Dependencies sys-libs/glibc
+Binary amd64=>hugo_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+ProgramName deploy
+Dependencies sys-libs/glibc
+Binary amd64=>hugo_deploy_${VERSION}_linux-amd64.tar.gz > hugo > hugo-deploy
+ProgramName extended
+Dependencies sys-libs/glibc sys-devel/gcc
+Binary amd64=>hugo_extended_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+`,
+		`Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Category app-misc
+EbuildName jan-appimage.ebuild
+License MIT
+Workaround Semantic Version Without V
+EbuildVariable KEYWORDS => ~amd64 ~arm64
+Dependencies sys-libs/glibc
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseInputConfigReader() mismatch (-want +got):\n%s", diff)
+	}
+	doc, err := ParseConfigDocument(strings.NewReader(testDefaultsData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	docIcs, err := doc.InputConfigs()
+	if err != nil {
+		t.Fatalf("InputConfigs() error = %v", err)
+	}
+	var docGot []string
+	for _, ic := range docIcs {
+		docGot = append(docGot, ic.String())
+	}
+	if diff := cmp.Diff(want, docGot); diff != "" {
+		t.Errorf("ConfigDocument.InputConfigs() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseDefaultsErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Defaults inside an entry",
+			input:   "Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nDefaults\n",
+			wantErr: "line 3: Defaults must be separated from the entry starting on line 1 by a blank line",
+		},
+		{
+			name:    "Entry field in Defaults",
+			input:   "Defaults\nCategory dev-util\nType Github Binary Release\n",
+			wantErr: "line 3: Type can't be used in Defaults",
+		},
+		{
+			name:    "Defaults with a value",
+			input:   "Defaults dev-util\n",
+			wantErr: "line 1: Defaults doesn't take a value",
+		},
+		{
+			name:    "Unknown workaround",
+			input:   "Defaults\nWorkaround Made Up\n\nType Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\n",
+			wantErr: "line 1: Defaults: unknown workaround: Made Up",
+		},
+		{
+			name:    "Bad ebuild variable",
+			input:   "Defaults\nEbuildVariable keywords => ~amd64\n",
+			wantErr: "line 1: Defaults: on EbuildVariable",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseInputConfigReader(strings.NewReader(test.input))
+			if err == nil {
+				t.Fatalf("ParseInputConfigReader() expected an error")
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ParseInputConfigReader() error = %s, want it to contain %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultsInheritedByIncludes(t *testing.T) {
+	entry := func(repo string) string {
+		return "Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/" + repo + "\nBinary amd64=>" + repo + ".tar.gz > " + repo + " > " + repo + "\n"
+	}
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config": "Defaults\nCategory dev-util\n\nInclude apps.config\n\n" + entry("root"),
+		"apps.config":  entry("inherited") + "\nDefaults\nCategory app-admin\n\n" + entry("overridden"),
+	})
+	ics, err := ReadConfigurationTree(filepath.Join(dir, "input.config"))
+	if err != nil {
+		t.Fatalf("ReadConfigurationTree() error = %v", err)
+	}
+	docs, err := LoadConfigDocumentTree(filepath.Join(dir, "input.config"))
+	if err != nil {
+		t.Fatalf("LoadConfigDocumentTree() error = %v", err)
+	}
+	docIcs, err := docs.InputConfigs()
+	if err != nil {
+		t.Fatalf("InputConfigs() error = %v", err)
+	}
+	for name, got := range map[string][]*InputConfig{"ReadConfigurationTree": ics, "LoadConfigDocumentTree": docIcs} {
+		categories := map[string]string{}
+		for _, ic := range got {
+			categories[ic.GithubRepo] = ic.Category
+		}
+		// The defaults of the included file don't apply to the rest of the including file.
+		want := map[string]string{
+			"inherited":  "dev-util",
+			"overridden": "app-admin",
+			"root":       "dev-util",
+		}
+		if diff := cmp.Diff(want, categories); diff != "" {
+			t.Errorf("%s categories mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestGenerateGithubWorkflowEbuildVariables(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testDefaultsData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, test := range []struct {
+		ic       *InputConfig
+		workflow string
+		want     []string
+	}{
+		{
+			ic:       ics[1],
+			workflow: "www-apps-hugo-bin-update.yaml",
+			want:     []string{"  keywords: ~amd64\n", `echo 'LICENSE="Apache-2.0"'`, `echo 'RESTRICT="mirror"'`},
+		},
+		{
+			ic:       ics[2],
+			workflow: "app-misc-jan-appimage-update.yaml",
+			want:     []string{"  keywords: ~amd64 ~arm64\n", `echo 'LICENSE="MIT"'`, `echo 'RESTRICT="strip"'`},
+		},
+	} {
+		if err := test.ic.GenerateGithubWorkflow("input.config", time.Now(), templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+		b, err := os.ReadFile(filepath.Join(outputDir, test.workflow))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", test.workflow, want)
+			}
+		}
+	}
+	ics[0].EbuildVariables["SRC_URI"] = "bad"
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Now(), templates, outputDir, "test"); err == nil {
+		t.Errorf("GenerateGithubWorkflow() with a generated ebuild variable should fail")
+	}
+}
+
+func TestLintDefaults(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(`Defaults
+Category dev-util
+Workaround Made Up
+EbuildVariable SRC_URI => bad
+Homepage https://example.com
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	doc.Filename = "test.config"
+	var got []string
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	want := []string{
+		"test.config:3:12: error: unknown workaround: Made Up",
+		"test.config:4:16: error: EbuildVariable SRC_URI is generated from the rest of the entry and can't be set",
+		"test.config:5:1: error: Homepage can't be used in Defaults",
+		"test.config:7:0: warning: entry has no Category, app-misc will be used",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+	doc.Blocks[0].RemoveLines(func(line *ConfigLine) bool {
+		return line.Number >= 3
+	})
+	if diagnostics := LintConfigDocument(doc); len(diagnostics) != 0 {
+		t.Errorf("LintConfigDocument() with valid defaults = %v, want none", diagnostics)
+	}
+}
+
+func TestDefaultsWorkaroundRemoval(t *testing.T) {
+	const config = `Defaults
+Category dev-util
+Workaround Semantic Version Without V
+Workaround Tag Prefix => app-
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Workaround -Semantic Version Without V
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+
+Defaults
+Workaround -Tag Prefix
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/other
+Binary amd64=>other_linux_amd64.tar.gz > other
+`
+	doc, err := ParseConfigDocument(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	docIcs, err := doc.InputConfigs()
+	if err != nil {
+		t.Fatalf("InputConfigs() error = %v", err)
+	}
+	ics, err := ParseInputConfigReader(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	want := []map[string]string{
+		{"Tag Prefix": "app-"},
+		{"Semantic Version Without V": ""},
+	}
+	for name, got := range map[string][]*InputConfig{"ParseInputConfigReader": ics, "ConfigDocument.InputConfigs": docIcs} {
+		var workarounds []map[string]string
+		for _, ic := range got {
+			workarounds = append(workarounds, ic.Workarounds)
+		}
+		if diff := cmp.Diff(want, workarounds); diff != "" {
+			t.Errorf("%s workarounds mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	doc.Filename = "test.config"
+	if diagnostics := LintConfigDocument(doc); len(diagnostics) != 0 {
+		t.Errorf("LintConfigDocument() = %v, want none", diagnostics)
+	}
+	doc, err = ParseConfigDocument(strings.NewReader("Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nWorkaround -Tag Prefix\nWorkaround -Made Up\nBinary amd64=>g2_linux_amd64.tar.gz > g2\n"))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	doc.Filename = "test.config"
+	var got []string
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	wantDiagnostics := []string{
+		"test.config:1:0: warning: entry has no Category, app-misc will be used",
+		"test.config:3:12: warning: workaround Tag Prefix isn't set by Defaults, there is nothing to remove",
+		"test.config:4:12: error: unknown workaround: Made Up",
+	}
+	if diff := cmp.Diff(wantDiagnostics, got); diff != "" {
+		t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+}
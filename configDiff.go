@@ -0,0 +1,306 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ConfigFieldChange is a field which differs between two versions of an entry. Old is empty if the field was added
+// and New is empty if it was removed.
+type ConfigFieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+func (cfc *ConfigFieldChange) String() string {
+	switch {
+	case cfc.Old == "":
+		return fmt.Sprintf("+ %s: %s", cfc.Field, cfc.New)
+	case cfc.New == "":
+		return fmt.Sprintf("- %s: %s", cfc.Field, cfc.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", cfc.Field, cfc.Old, cfc.New)
+	}
+}
+
+// ConfigEntryDiff is an entry which was added (Old is nil), removed (New is nil) or changed between two configuration
+// files.
+type ConfigEntryDiff struct {
+	Old     *InputConfig
+	New     *InputConfig
+	Changes []*ConfigFieldChange
+}
+
+func (ced *ConfigEntryDiff) String() string {
+	var sb strings.Builder
+	switch {
+	case ced.Old == nil:
+		sb.WriteString(fmt.Sprintf("+ %s\n", entryDiffName(ced.New)))
+	case ced.New == nil:
+		sb.WriteString(fmt.Sprintf("- %s\n", entryDiffName(ced.Old)))
+	default:
+		sb.WriteString(fmt.Sprintf("~ %s\n", entryDiffName(ced.New)))
+		for _, change := range ced.Changes {
+			sb.WriteString(fmt.Sprintf("    %s\n", change))
+		}
+	}
+	return sb.String()
+}
+
+func entryDiffName(ic *InputConfig) string {
+	name := fmt.Sprintf("%s/%s", ic.Category, strings.TrimSuffix(ic.EbuildName, ".ebuild"))
+	if ic.SourceFile == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s:%d)", name, ic.SourceFile, ic.SourceLine)
+}
+
+// ConfigDiff prints the entries which were added, removed or changed between the two configuration files and the
+// files they include, a missing file has no entries.
+func ConfigDiff(oldFile, newFile string) error {
+	oldIcs, err := ReadConfigurationFile(oldFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", oldFile, err)
+	}
+	newIcs, err := ReadConfigurationFile(newFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", newFile, err)
+	}
+	diffs := DiffInputConfigs(oldIcs, newIcs)
+	for _, diff := range diffs {
+		fmt.Print(diff.String())
+	}
+	if len(diffs) == 0 {
+		log.Printf("No differences between the entries in %s and %s", oldFile, newFile)
+	}
+	return nil
+}
+
+// DiffInputConfigs matches the old entries to the new ones and returns those which differ, removed and changed entries
+// come first in the old order followed by the added ones in the new order. Entries are matched by `Id`, then by ebuild
+// name and then by GitHub project if only one entry on each side is left for the project.
+func DiffInputConfigs(oldIcs, newIcs []*InputConfig) []*ConfigEntryDiff {
+	matched := map[*InputConfig]*InputConfig{}
+	used := map[*InputConfig]bool{}
+	match := func(key func(ic *InputConfig) string, unique bool) {
+		candidates := map[string][]*InputConfig{}
+		for _, ic := range newIcs {
+			if k := key(ic); !used[ic] && k != "" {
+				candidates[k] = append(candidates[k], ic)
+			}
+		}
+		counts := map[string]int{}
+		for _, ic := range oldIcs {
+			if _, ok := matched[ic]; !ok {
+				counts[key(ic)]++
+			}
+		}
+		for _, ic := range oldIcs {
+			k := key(ic)
+			if _, ok := matched[ic]; ok || k == "" || len(candidates[k]) == 0 {
+				continue
+			}
+			if unique && (counts[k] != 1 || len(candidates[k]) != 1) {
+				continue
+			}
+			matched[ic] = candidates[k][0]
+			used[candidates[k][0]] = true
+			candidates[k] = candidates[k][1:]
+		}
+	}
+	match(func(ic *InputConfig) string {
+		if ic.EntryNumber == 0 {
+			return ""
+		}
+		return fmt.Sprint(ic.EntryNumber)
+	}, false)
+	match(func(ic *InputConfig) string {
+		return ic.EbuildName
+	}, false)
+	match(func(ic *InputConfig) string {
+		switch {
+		case ic.IsGitlab():
+			return strings.ToLower(ic.GitlabBaseUrl + "/" + ic.GitlabProjectPath)
+		case ic.IsGitea():
+			return strings.ToLower(ic.GiteaBaseUrl + "/" + ic.GiteaOwner + "/" + ic.GiteaRepo)
+		}
+		return strings.ToLower(ic.GithubOwner + "/" + ic.GithubRepo)
+	}, true)
+
+	var result []*ConfigEntryDiff
+	for _, oldIc := range oldIcs {
+		newIc, ok := matched[oldIc]
+		if !ok {
+			result = append(result, &ConfigEntryDiff{Old: oldIc})
+			continue
+		}
+		if changes := DiffInputConfig(oldIc, newIc); len(changes) > 0 {
+			result = append(result, &ConfigEntryDiff{Old: oldIc, New: newIc, Changes: changes})
+		}
+	}
+	for _, newIc := range newIcs {
+		if !used[newIc] {
+			result = append(result, &ConfigEntryDiff{New: newIc})
+		}
+	}
+	return result
+}
+
+// DiffInputConfig returns the fields which differ between the two versions of the entry. Workarounds, ebuild variables,
+// programs and the keywords of Binary, Document, ManualPage and ShellCompletionScript lines are compared one by one so
+// the order they were written in doesn't matter.
+func DiffInputConfig(oldIc, newIc *InputConfig) []*ConfigFieldChange {
+	oldFields := inputConfigDiffFields(oldIc)
+	newFields := inputConfigDiffFields(newIc)
+	var result []*ConfigFieldChange
+	for _, field := range inputConfigDiffFieldOrder(oldIc, newIc) {
+		if oldFields[field] != newFields[field] {
+			result = append(result, &ConfigFieldChange{Field: field, Old: oldFields[field], New: newFields[field]})
+		}
+	}
+	return result
+}
+
+// inputConfigDiffFieldOrder returns the fields of both entries in the order they are written by InputConfig.String().
+func inputConfigDiffFieldOrder(ics ...*InputConfig) []string {
+	var result []string
+	seen := map[string]bool{}
+	add := func(field string) {
+		if !seen[field] {
+			seen[field] = true
+			result = append(result, field)
+		}
+	}
+	for _, field := range []string{"Type", "Id", "GithubProjectUrl", "GitlabProjectUrl", "GiteaProjectUrl", "Category", "EbuildName", "Description", "Homepage", "License", "BuildSystem", "SourceUrl", "ReleaseUrl", "Depend", "BDepend", "GoDependencies", "GoDependencyTarball", "GoPackages", "GoLdflags"} {
+		add(field)
+	}
+	var workarounds, ebuildVariables, programs []string
+	for _, ic := range ics {
+		workarounds = append(workarounds, ic.WorkaroundString()...)
+		ebuildVariables = append(ebuildVariables, ic.EbuildVariableNames()...)
+		programs = append(programs, ic.ProgramsString()...)
+	}
+	slices.Sort(workarounds)
+	slices.Sort(ebuildVariables)
+	slices.Sort(programs)
+	for _, name := range workarounds {
+		add("Workaround " + name)
+	}
+	for _, name := range ebuildVariables {
+		add("EbuildVariable " + name)
+	}
+	for _, programName := range programs {
+		fields := map[string]bool{}
+		for _, ic := range ics {
+			if p, ok := ic.Programs[programName]; ok {
+				for field := range programDiffFields(programName, p) {
+					fields[field] = true
+				}
+			}
+		}
+		for _, key := range programFieldKeys {
+			prefix := programDiffFieldName(programName, key)
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				if field == prefix || strings.HasPrefix(field, prefix+" ") {
+					add(field)
+				}
+			}
+		}
+	}
+	return result
+}
+
+func inputConfigDiffFields(ic *InputConfig) map[string]string {
+	result := map[string]string{
+		"Type":                ic.Type,
+		"GithubProjectUrl":    ic.GithubProjectUrl,
+		"GitlabProjectUrl":    ic.GitlabProjectUrl,
+		"GiteaProjectUrl":     ic.GiteaProjectUrl,
+		"Category":            ic.Category,
+		"EbuildName":          ic.EbuildName,
+		"Description":         ic.Description,
+		"Homepage":            ic.Homepage,
+		"License":             ic.License,
+		"BuildSystem":         ic.BuildSystem,
+		"SourceUrl":           ic.SourceUrl,
+		"ReleaseUrl":          ic.ReleaseUrl,
+		"Depend":              strings.Join(ic.Depend, " "),
+		"BDepend":             strings.Join(ic.BDepend, " "),
+		"GoDependencies":      ic.GoDependencies,
+		"GoDependencyTarball": ic.GoDependencyTarball,
+		"GoPackages":          strings.Join(ic.GoPackages, " "),
+		"GoLdflags":           ic.GoLdflags,
+	}
+	if ic.EntryNumber != 0 {
+		result["Id"] = fmt.Sprint(ic.EntryNumber)
+	}
+	for name, value := range ic.Workarounds {
+		// A workaround without a value is still set, so it needs a value to show up as added or removed.
+		if value == "" {
+			value = "(set)"
+		}
+		result["Workaround "+name] = value
+	}
+	for name, value := range ic.EbuildVariables {
+		if value == "" {
+			value = `""`
+		}
+		result["EbuildVariable "+name] = value
+	}
+	for programName, p := range ic.Programs {
+		maps.Copy(result, programDiffFields(programName, p))
+	}
+	return result
+}
+
+func programDiffFieldName(programName, field string) string {
+	if programName == "" {
+		return field
+	}
+	return fmt.Sprintf("ProgramName %s: %s", programName, field)
+}
+
+func programDiffFields(programName string, p *Program) map[string]string {
+	result := map[string]string{}
+	set := func(field, value string) {
+		if value != "" {
+			result[programDiffFieldName(programName, field)] = value
+		}
+	}
+	set("ProgramDescription", p.Description)
+	set("ProgramHomepage", p.Homepage)
+	set("ProgramLicense", p.License)
+	set("InstallPath", p.InstallPath)
+	set("DesktopFile", p.DesktopFile)
+	set("Icons", strings.Join(p.Icons, " "))
+	set("Dependencies", strings.Join(p.Dependencies, " "))
+	for kw, files := range p.Binary {
+		set("Binary "+kw, strings.Join(files, " > "))
+	}
+	for kw, documents := range p.Documents {
+		set("Document "+kw, joinFileLists(documents))
+	}
+	for kw, pages := range p.ManualPage {
+		set("ManualPage "+kw, joinFileLists(pages))
+	}
+	for kw, shells := range p.ShellCompletionScripts {
+		for shell, files := range shells {
+			set(fmt.Sprintf("ShellCompletionScript %s:%s", kw, shell), strings.Join(files, " > "))
+		}
+	}
+	return result
+}
+
+// joinFileLists joins the files of the lines for a keyword, the lines are sorted as their order doesn't matter.
+func joinFileLists(lists [][]string) string {
+	var lines []string
+	for _, files := range lists {
+		lines = append(lines, strings.Join(files, " > "))
+	}
+	slices.Sort(lines)
+	return strings.Join(lines, ", ")
+}
@@ -0,0 +1,121 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+const testDiffOldData = `Type Github Binary Release
+GithubProjectUrl https://github.com/gohugoio/hugo
+Category www-apps
+Workaround Tag Prefix => v
+Binary amd64=>hugo_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+Binary arm64=>hugo_${VERSION}_linux-arm64.tar.gz > hugo > hugo
+ProgramName deploy
+Binary amd64=>hugo_deploy_${VERSION}_linux-amd64.tar.gz > hugo > hugo-deploy
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+EbuildName chezmoi
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`
+
+// testDiffNewData has the same hugo entry written in a different order with some changes, chezmoi renamed and jan
+// replaced by g2.
+const testDiffNewData = `Type Github Binary Release
+Category www-apps
+GithubProjectUrl https://github.com/gohugoio/hugo
+ProgramName extended
+Binary amd64=>hugo_extended_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+ProgramName deploy
+Binary amd64=>hugo_deploy_${VERSION}_linux-amd64.tar.gz  >  hugo > hugo-deploy
+ProgramName
+Binary arm64=>hugo_${VERSION}_linux-arm64.tar.gz > hugo > hugo
+Binary amd64=>hugo_${VERSION}_Linux-64bit.tar.gz > hugo > hugo
+Workaround Tag Prefix => v
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+EbuildName chezmoi-cli
+Description Manage your dotfiles
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`
+
+func TestDiffInputConfigs(t *testing.T) {
+	oldIcs, err := ParseInputConfigReader(strings.NewReader(testDiffOldData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() old error = %v", err)
+	}
+	newIcs, err := ParseInputConfigReader(strings.NewReader(testDiffNewData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() new error = %v", err)
+	}
+	var got strings.Builder
+	for _, diff := range DiffInputConfigs(oldIcs, newIcs) {
+		got.WriteString(diff.String())
+	}
+	want := `~ www-apps/hugo-bin
+    ~ Binary amd64: hugo_${VERSION}_linux-amd64.tar.gz > hugo > hugo -> hugo_${VERSION}_Linux-64bit.tar.gz > hugo > hugo
+    + ProgramName extended: Binary amd64: hugo_extended_${VERSION}_linux-amd64.tar.gz > hugo > hugo
+~ app-admin/chezmoi-cli-bin
+    ~ EbuildName: chezmoi-bin.ebuild -> chezmoi-cli-bin.ebuild
+    + Description: Manage your dotfiles
+- app-misc/jan-appimage
++ dev-util/g2-bin
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("DiffInputConfigs() mismatch (-want +got):\n%s", diff)
+	}
+	if diffs := DiffInputConfigs(oldIcs, oldIcs); len(diffs) != 0 {
+		t.Errorf("DiffInputConfigs() of the same entries = %v, want none", diffs)
+	}
+}
+
+func TestDiffInputConfigsMatching(t *testing.T) {
+	oldIcs, err := ParseInputConfigReader(strings.NewReader(testDiffOldData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() old error = %v", err)
+	}
+	newIcs, err := ParseInputConfigReader(strings.NewReader(testDiffNewData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() new error = %v", err)
+	}
+	// chezmoi was renamed so is matched by its GitHub project.
+	diffs := DiffInputConfigs(oldIcs[1:2], newIcs[1:2])
+	if len(diffs) != 1 || diffs[0].Old == nil || diffs[0].New == nil {
+		t.Fatalf("DiffInputConfigs() = %v, want one changed entry", diffs)
+	}
+	got := map[string]*ConfigFieldChange{}
+	for _, change := range diffs[0].Changes {
+		got[change.Field] = change
+	}
+	if change := got["Description"]; change == nil || change.Old != "" || change.New != "Manage your dotfiles" {
+		t.Errorf("Description change = %v, want it added", change)
+	}
+	// Two entries for the same project can't be told apart by the project so are only matched by ebuild name.
+	oldIcs[2].GithubProjectUrl, oldIcs[2].GithubOwner, oldIcs[2].GithubRepo = oldIcs[1].GithubProjectUrl, oldIcs[1].GithubOwner, oldIcs[1].GithubRepo
+	oldIcs[1].EbuildName, oldIcs[2].EbuildName = "a-bin.ebuild", "b-bin.ebuild"
+	for _, diff := range DiffInputConfigs(oldIcs[1:], newIcs[1:2]) {
+		if diff.Old != nil && diff.New != nil {
+			t.Errorf("DiffInputConfigs() matched %s to %s, want them unmatched", diff.Old.EbuildName, diff.New.EbuildName)
+		}
+	}
+	// Ids match entries even when everything else changed.
+	oldIcs[0].EntryNumber, newIcs[2].EntryNumber = 5, 5
+	diffs = DiffInputConfigs(oldIcs[:1], newIcs[2:])
+	if len(diffs) != 1 || diffs[0].Old != oldIcs[0] || diffs[0].New != newIcs[2] {
+		t.Errorf("DiffInputConfigs() = %v, want the entries with the same Id matched", diffs)
+	}
+}
@@ -0,0 +1,650 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"errors"
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	// entryFieldOrder is the order entry level fields are written in by InputConfig.String(), it is used to work out
+	// where to insert a field which isn't already present in an entry.
+	entryFieldOrder = []string{
+		"Type",
+		"Id",
+		"GithubProjectUrl",
+		"GitlabProjectUrl",
+		"GiteaProjectUrl",
+		"Category",
+		"EbuildName",
+		"Description",
+		"Homepage",
+		"License",
+		"BuildSystem",
+		"SourceUrl",
+		"ReleaseUrl",
+		"Depend",
+		"BDepend",
+		"GoDependencies",
+		"GoDependencyTarball",
+		"GoPackages",
+		"GoLdflags",
+		"Workaround",
+		"EbuildVariable",
+	}
+	// programFieldKeys are the fields which belong to the current `ProgramName` section (or the unnamed program if
+	// there is no `ProgramName` before them.)
+	programFieldKeys = []string{
+		"ProgramName",
+		"ProgramDescription",
+		"ProgramHomepage",
+		"ProgramLicense",
+		"InstallPath",
+		"DesktopFile",
+		"Icons",
+		"Dependencies",
+		"Document",
+		"ManualPage",
+		"ShellCompletionScript",
+		"Binary",
+	}
+)
+
+// ConfigLine is a single line of a configuration file, kept verbatim so it can be written back unchanged.
+type ConfigLine struct {
+	// Number is the 1 based line number in the file it was read from, 0 for lines added since.
+	Number int
+	Text   string
+}
+
+func (cl *ConfigLine) IsBlank() bool {
+	return strings.TrimSpace(cl.Text) == ""
+}
+
+func (cl *ConfigLine) IsComment() bool {
+	return strings.HasPrefix(strings.TrimSpace(cl.Text), "#")
+}
+
+// Key returns the field name of the line, the same way ParseInputConfigReader splits it.
+func (cl *ConfigLine) Key() string {
+	if cl.IsBlank() || cl.IsComment() {
+		return ""
+	}
+	line := strings.TrimSpace(cl.Text)
+	if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func (cl *ConfigLine) Value() string {
+	line := strings.TrimSpace(cl.Text)
+	return strings.TrimSpace(strings.TrimPrefix(line, cl.Key()))
+}
+
+func (cl *ConfigLine) IsProgramField() bool {
+	return slices.Contains(programFieldKeys, cl.Key())
+}
+
+// ConfigBlock is a run of non-blank lines. A block with a `Type` line is a configuration entry, anything else (such
+// as a block of comments) is kept as is.
+type ConfigBlock struct {
+	// Separator is the blank lines between this block and the previous one.
+	Separator []*ConfigLine
+	Lines     []*ConfigLine
+}
+
+func (cb *ConfigBlock) IsEntry() bool {
+	return !cb.IsDefaults() && cb.Value("Type") != ""
+}
+
+// FirstLineNumber is the line number of the first line of the block as it was read, or 0 if the block is new.
+func (cb *ConfigBlock) FirstLineNumber() int {
+	for _, line := range cb.Lines {
+		if line.Number > 0 {
+			return line.Number
+		}
+	}
+	return 0
+}
+
+func (cb *ConfigBlock) Text() string {
+	var sb strings.Builder
+	for _, line := range cb.Lines {
+		sb.WriteString(line.Text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// clone copies the block and its lines so the copy can be changed without changing the document.
+func (cb *ConfigBlock) clone() *ConfigBlock {
+	result := &ConfigBlock{}
+	for _, line := range cb.Separator {
+		result.Separator = append(result.Separator, &ConfigLine{Number: line.Number, Text: line.Text})
+	}
+	for _, line := range cb.Lines {
+		result.Lines = append(result.Lines, &ConfigLine{Number: line.Number, Text: line.Text})
+	}
+	return result
+}
+
+// InputConfig parses the block with the same rules as ParseInputConfigReader.
+func (cb *ConfigBlock) InputConfig() (*InputConfig, error) {
+	return cb.InputConfigWithDefaults(nil)
+}
+
+// InputConfigWithDefaults parses the block as if it came after the defaults.
+func (cb *ConfigBlock) InputConfigWithDefaults(defaults *InputConfigDefaults) (*InputConfig, error) {
+	return cb.parseInputConfig(&InputConfigParser{Defaults: defaults})
+}
+
+func (cb *ConfigBlock) parseInputConfig(parser *InputConfigParser) (*InputConfig, error) {
+	ics, err := parser.Parse(strings.NewReader(cb.Text()))
+	if err != nil {
+		return nil, err
+	}
+	if len(ics) != 1 {
+		return nil, fmt.Errorf("expected 1 entry in block got %d", len(ics))
+	}
+	return ics[0], nil
+}
+
+// Values returns all values for the key in the order they appear.
+func (cb *ConfigBlock) Values(key string) []string {
+	var result []string
+	for _, line := range cb.Lines {
+		if line.Key() == key {
+			result = append(result, line.Value())
+		}
+	}
+	return result
+}
+
+// Value returns the first value for the key or an empty string.
+func (cb *ConfigBlock) Value(key string) string {
+	for _, line := range cb.Lines {
+		if line.Key() == key {
+			return line.Value()
+		}
+	}
+	return ""
+}
+
+// SetValue replaces the first entry level line with the key and removes any others, if there isn't one the line is
+// inserted with the other entry level fields.
+func (cb *ConfigBlock) SetValue(key, value string) {
+	text := strings.TrimSpace(key + " " + value)
+	found := false
+	cb.Lines = slices.DeleteFunc(cb.Lines, func(line *ConfigLine) bool {
+		if line.Key() != key {
+			return false
+		}
+		if found {
+			return true
+		}
+		found = true
+		line.Text = text
+		return false
+	})
+	if !found {
+		cb.InsertEntryLine(&ConfigLine{Text: text})
+	}
+}
+
+// RemoveKey removes every line with the key and returns how many were removed.
+func (cb *ConfigBlock) RemoveKey(key string) int {
+	return cb.RemoveLines(func(line *ConfigLine) bool {
+		return line.Key() == key
+	})
+}
+
+// RemoveLines removes every line matching the function and returns how many were removed.
+func (cb *ConfigBlock) RemoveLines(f func(line *ConfigLine) bool) int {
+	before := len(cb.Lines)
+	cb.Lines = slices.DeleteFunc(cb.Lines, f)
+	return before - len(cb.Lines)
+}
+
+// InsertEntryLine inserts an entry level line after the last entry level field which comes before it in
+// `entryFieldOrder`, it will not be placed after any program fields.
+func (cb *ConfigBlock) InsertEntryLine(newLine *ConfigLine) {
+	order := slices.Index(entryFieldOrder, newLine.Key())
+	if order < 0 {
+		order = len(entryFieldOrder)
+	}
+	position := 0
+	for i, line := range cb.Lines {
+		if line.IsProgramField() {
+			break
+		}
+		if line.IsComment() {
+			continue
+		}
+		lineOrder := slices.Index(entryFieldOrder, line.Key())
+		if lineOrder < 0 || lineOrder <= order {
+			position = i + 1
+		}
+	}
+	cb.InsertLines(position, newLine)
+}
+
+func (cb *ConfigBlock) InsertLines(position int, lines ...*ConfigLine) {
+	cb.Lines = slices.Insert(cb.Lines, position, lines...)
+}
+
+// ConfigDocument is a configuration file as a list of blocks which keeps comments, ordering, unknown lines and
+// spacing so that it can be edited and written back without losing anything.
+type ConfigDocument struct {
+	Filename string
+	Blocks   []*ConfigBlock
+	// Trailing is the blank lines after the last block.
+	Trailing []*ConfigLine
+	// NoFinalNewline is set when the file didn't end with a newline.
+	NoFinalNewline bool
+	// InheritedDefaults are the defaults of the file which included this one at the `Include` line.
+	InheritedDefaults *InputConfigDefaults
+}
+
+// LoadConfigDocument reads a configuration file into a ConfigDocument, a missing file is treated as empty.
+func LoadConfigDocument(filename string) (*ConfigDocument, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ConfigDocument{Filename: filename}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("opening configuration file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	doc, err := ParseConfigDocument(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration file: %s: %w", filename, err)
+	}
+	doc.Filename = filename
+	return doc, nil
+}
+
+func ParseConfigDocument(r io.Reader) (*ConfigDocument, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := &ConfigDocument{}
+	content := string(b)
+	if content == "" {
+		return doc, nil
+	}
+	if strings.HasSuffix(content, "\n") {
+		content = strings.TrimSuffix(content, "\n")
+	} else {
+		doc.NoFinalNewline = true
+	}
+	var separator []*ConfigLine
+	var current *ConfigBlock
+	for i, text := range strings.Split(content, "\n") {
+		line := &ConfigLine{Number: i + 1, Text: text}
+		if line.IsBlank() {
+			current = nil
+			separator = append(separator, line)
+			continue
+		}
+		if current == nil {
+			current = &ConfigBlock{Separator: separator}
+			separator = nil
+			doc.Blocks = append(doc.Blocks, current)
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	doc.Trailing = separator
+	return doc, nil
+}
+
+func (cd *ConfigDocument) String() string {
+	var sb strings.Builder
+	lines := 0
+	write := func(ls []*ConfigLine) {
+		for _, line := range ls {
+			if lines > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(line.Text)
+			lines++
+		}
+	}
+	for _, block := range cd.Blocks {
+		write(block.Separator)
+		write(block.Lines)
+	}
+	write(cd.Trailing)
+	if lines > 0 && !cd.NoFinalNewline {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Entries returns the blocks which are configuration entries.
+func (cd *ConfigDocument) Entries() []*ConfigBlock {
+	var result []*ConfigBlock
+	for _, block := range cd.Blocks {
+		if block.IsEntry() {
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+// InputConfigs parses every entry in the document.
+func (cd *ConfigDocument) InputConfigs() ([]*InputConfig, error) {
+	_, result, err := cd.parseEntries()
+	return result, err
+}
+
+// parseEntries returns the entry blocks and what they parse to with the defaults in effect for each.
+func (cd *ConfigDocument) parseEntries() ([]*ConfigBlock, []*InputConfig, error) {
+	var blocks []*ConfigBlock
+	ics := make([]*InputConfig, 0, len(cd.Blocks))
+	defaults := cd.InheritedDefaults
+	version, versionLine, err := cd.ConfigVersion()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%d: %w", cd.Filename, versionLine.Number, err)
+	}
+	for _, block := range cd.Blocks {
+		switch {
+		case block.IsDefaults():
+			defaults, err = block.Defaults(defaults)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s:%d: Defaults: %w", cd.Filename, block.FirstLineNumber(), err)
+			}
+			continue
+		case !block.IsEntry():
+			continue
+		}
+		ic, err := block.parseInputConfig(&InputConfigParser{Defaults: defaults, ConfigVersion: version})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %w", cd.Filename, block.FirstLineNumber(), err)
+		}
+		ic.SourceFile = cd.Filename
+		ic.SourceLine = block.FirstLineNumber()
+		blocks = append(blocks, block)
+		ics = append(ics, ic)
+	}
+	return blocks, ics, nil
+}
+
+// AppendEntry adds the configuration entry to the end of the document separated by a blank line.
+func (cd *ConfigDocument) AppendEntry(ic *InputConfig) *ConfigBlock {
+	block := NewConfigBlock(ic.String())
+	cd.AppendBlock(block)
+	return block
+}
+
+func (cd *ConfigDocument) AppendBlock(block *ConfigBlock) {
+	block.Separator = cd.Trailing
+	cd.Trailing = nil
+	if len(cd.Blocks) > 0 && len(block.Separator) == 0 {
+		block.Separator = []*ConfigLine{{}}
+	}
+	cd.Blocks = append(cd.Blocks, block)
+	cd.NoFinalNewline = false
+}
+
+// RemoveBlock removes the block, if it was the first block the following block takes over its leading blank lines.
+func (cd *ConfigDocument) RemoveBlock(block *ConfigBlock) bool {
+	i := slices.Index(cd.Blocks, block)
+	if i < 0 {
+		return false
+	}
+	if i == 0 && len(cd.Blocks) > 1 {
+		cd.Blocks[1].Separator = block.Separator
+	}
+	cd.Blocks = slices.Delete(cd.Blocks, i, i+1)
+	return true
+}
+
+// Save atomically replaces the file the document was loaded from.
+func (cd *ConfigDocument) Save() error {
+	return cd.SaveAs(cd.Filename)
+}
+
+func (cd *ConfigDocument) SaveAs(filename string) error {
+	if filename == "" {
+		return fmt.Errorf("no filename for configuration document")
+	}
+	if err := util.WriteFileAtomic(filename, []byte(cd.String()), 0644); err != nil {
+		return fmt.Errorf("writing configuration file: %w", err)
+	}
+	return nil
+}
+
+// NewConfigBlock creates a block from text such as the output of InputConfig.String(), blank lines are dropped.
+func NewConfigBlock(text string) *ConfigBlock {
+	block := &ConfigBlock{}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		block.Lines = append(block.Lines, &ConfigLine{Text: line})
+	}
+	return block
+}
+
+// ConfigProgramSection is the range of lines in an entry belonging to a program. The unnamed program runs from the
+// start of the entry to the first `ProgramName` line.
+type ConfigProgramSection struct {
+	ProgramName string
+	// Start is the index of the `ProgramName` line, or 0 for the unnamed program.
+	Start int
+	// End is the index after the last line of the section.
+	End int
+}
+
+// ProgramSections returns the sections of the entry, the first is always the unnamed program.
+func (cb *ConfigBlock) ProgramSections() []*ConfigProgramSection {
+	current := &ConfigProgramSection{}
+	result := []*ConfigProgramSection{current}
+	for i, line := range cb.Lines {
+		if line.Key() != "ProgramName" {
+			continue
+		}
+		current.End = i
+		current = &ConfigProgramSection{
+			ProgramName: line.Value(),
+			Start:       i,
+		}
+		result = append(result, current)
+	}
+	current.End = len(cb.Lines)
+	return result
+}
+
+// ProgramLines returns the program level lines in the section.
+func (cps *ConfigProgramSection) ProgramLines(cb *ConfigBlock) []*ConfigLine {
+	var result []*ConfigLine
+	for _, line := range cb.Lines[cps.Start:cps.End] {
+		if line.IsProgramField() {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// EntrySelector picks entries in a configuration document by their id, ebuild name and / or GitHub project URL, empty
+// fields match everything.
+type EntrySelector struct {
+	// Id is the entry's `Id`, 0 matches everything.
+	Id int
+	// EbuildName can be given with or without the `.ebuild` and type suffixes (`-bin`, `-appimage`.) A suffix given
+	// has to be the entry's own.
+	EbuildName string
+	// GithubUrl is compared by owner and repo so trailing slashes and case don't matter, GitLab and Gitea entries compare
+	// it with their GitlabProjectUrl or GiteaProjectUrl the same way.
+	GithubUrl string
+}
+
+func (es *EntrySelector) String() string {
+	var parts []string
+	if es.Id != 0 {
+		parts = append(parts, fmt.Sprintf("id %d", es.Id))
+	}
+	if es.EbuildName != "" {
+		parts = append(parts, fmt.Sprintf("ebuild name %s", es.EbuildName))
+	}
+	if es.GithubUrl != "" {
+		parts = append(parts, fmt.Sprintf("github url %s", es.GithubUrl))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// matches returns if the entry matches and if the match was exact, an exact match is one where the ebuild name wasn't
+// given or was given as is.
+func (es *EntrySelector) matches(ic *InputConfig) (bool, bool) {
+	if es.Id != 0 && ic.EntryNumber != es.Id {
+		return false, false
+	}
+	if es.GithubUrl != "" && ic.IsGitlab() {
+		baseUrl, projectPath, err := util.ExtractGitlabProject(es.GithubUrl)
+		if err != nil || !strings.EqualFold(baseUrl, ic.GitlabBaseUrl) || !strings.EqualFold(projectPath, ic.GitlabProjectPath) {
+			return false, false
+		}
+	} else if es.GithubUrl != "" && ic.IsGitea() {
+		baseUrl, owner, repo, err := util.ExtractGiteaOwnerRepo(es.GithubUrl)
+		if err != nil || !strings.EqualFold(baseUrl, ic.GiteaBaseUrl) || !strings.EqualFold(owner, ic.GiteaOwner) || !strings.EqualFold(repo, ic.GiteaRepo) {
+			return false, false
+		}
+	} else if es.GithubUrl != "" {
+		owner, repo, err := util.ExtractGithubOwnerRepo(es.GithubUrl)
+		if err != nil || !strings.EqualFold(owner, ic.GithubOwner) || !strings.EqualFold(repo, ic.GithubRepo) {
+			return false, false
+		}
+	}
+	switch {
+	case es.EbuildName == "", ic.EbuildName == es.EbuildName, ic.EbuildName == es.EbuildName+".ebuild":
+		return true, true
+	case trimEbuildNameSuffixes(ic.EbuildName) == strings.TrimSuffix(es.EbuildName, ".ebuild"):
+		// Only the entry's suffix is removed, the selector's might be part of the name as in go-appimage-appimage
+		return true, false
+	}
+	return false, false
+}
+
+// FindEntry returns the entry the selector picks, exact ebuild name matches are preferred over ones where the
+// suffixes differ. It is an error if there isn't exactly one match.
+func (cd *ConfigDocument) FindEntry(selector *EntrySelector) (*ConfigBlock, *InputConfig, error) {
+	_, block, ic, err := ConfigDocuments{cd}.FindEntry(selector)
+	return block, ic, err
+}
+
+// ConfigDocuments is a configuration file followed by the files it includes.
+type ConfigDocuments []*ConfigDocument
+
+// FindEntry returns the entry the selector picks from any of the documents and the document it is in. Exact ebuild
+// name matches are preferred over ones where the suffixes differ. It is an error if there isn't exactly one match.
+func (cds ConfigDocuments) FindEntry(selector *EntrySelector) (*ConfigDocument, *ConfigBlock, *InputConfig, error) {
+	if selector.Id == 0 && selector.EbuildName == "" && selector.GithubUrl == "" {
+		return nil, nil, nil, fmt.Errorf("no id, ebuild name or github url to select an entry with")
+	}
+	type match struct {
+		doc   *ConfigDocument
+		block *ConfigBlock
+		ic    *InputConfig
+	}
+	var exact, loose []*match
+	for _, doc := range cds {
+		blocks, ics, err := doc.parseEntries()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for i, block := range blocks {
+			ic := ics[i]
+			switch matched, isExact := selector.matches(ic); {
+			case matched && isExact:
+				exact = append(exact, &match{doc: doc, block: block, ic: ic})
+			case matched:
+				loose = append(loose, &match{doc: doc, block: block, ic: ic})
+			}
+		}
+	}
+	if len(exact) == 0 {
+		exact = loose
+	}
+	switch len(exact) {
+	case 0:
+		var filenames []string
+		for _, doc := range cds {
+			filenames = append(filenames, doc.Filename)
+		}
+		return nil, nil, nil, fmt.Errorf("no entry with %s in %s", selector, strings.Join(filenames, ", "))
+	case 1:
+		return exact[0].doc, exact[0].block, exact[0].ic, nil
+	default:
+		var locations []string
+		for _, m := range exact {
+			locations = append(locations, fmt.Sprintf("%s:%d", m.doc.Filename, m.block.FirstLineNumber()))
+		}
+		return nil, nil, nil, fmt.Errorf("%s matches the entries at %s, use the full ebuild name", selector, strings.Join(locations, ", "))
+	}
+}
+
+// InputConfigs parses every entry in every document.
+func (cds ConfigDocuments) InputConfigs() ([]*InputConfig, error) {
+	var result []*InputConfig
+	for _, doc := range cds {
+		ics, err := doc.InputConfigs()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ics...)
+	}
+	return result, nil
+}
+
+func trimEbuildNameSuffixes(ebuildName string) string {
+	return util.TrimSuffixes(strings.TrimSuffix(ebuildName, ".ebuild"), "-bin", "-appimage", "-AppImage")
+}
+
+// Workaround returns the value of the workaround and if the entry has it.
+func (cb *ConfigBlock) Workaround(name string) (string, bool) {
+	for _, value := range cb.Values("Workaround") {
+		workaround, workaroundValue, _ := strings.Cut(value, "=>")
+		if strings.TrimSpace(workaround) == name {
+			return strings.TrimSpace(workaroundValue), true
+		}
+	}
+	return "", false
+}
+
+// SetWorkaround replaces the line of the workaround or adds one after the other entry level fields.
+func (cb *ConfigBlock) SetWorkaround(name, value string) {
+	text := "Workaround " + name
+	if value != "" {
+		text += " => " + value
+	}
+	for _, line := range cb.Lines {
+		if line.Key() != "Workaround" {
+			continue
+		}
+		workaround, _, _ := strings.Cut(line.Value(), "=>")
+		if strings.TrimSpace(workaround) == name {
+			line.Text = text
+			return
+		}
+	}
+	cb.InsertEntryLine(&ConfigLine{Text: text})
+}
+
+// RemoveWorkaround removes the lines of the workaround and returns how many were removed.
+func (cb *ConfigBlock) RemoveWorkaround(name string) int {
+	return cb.RemoveLines(func(line *ConfigLine) bool {
+		if line.Key() != "Workaround" {
+			return false
+		}
+		workaround, _, _ := strings.Cut(line.Value(), "=>")
+		return strings.TrimSpace(workaround) == name
+	})
+}
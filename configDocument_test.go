@@ -0,0 +1,151 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfigDocumentData = `# My overlay's config
+# Second header line
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+# Keep this in dev-go
+Category dev-go
+EbuildName goreleaser-bin
+ProgramName goreleaser
+Binary  amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+Binary arm64=>goreleaser_Linux_arm64.tar.gz > goreleaser > goreleaser
+
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan/
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+
+# trailing comment
+`
+
+func TestConfigDocumentRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty", input: ""},
+		{name: "Only newline", input: "\n"},
+		{name: "No final newline", input: "Type Github Binary Release\nGithubProjectUrl https://github.com/a/b"},
+		{name: "Example", input: testConfigDocumentData},
+		{name: "Existing test config", input: testConfigData},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := ParseConfigDocument(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("ParseConfigDocument() error = %v", err)
+			}
+			if diff := cmp.Diff(test.input, doc.String()); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigDocumentEntries(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testConfigDocumentData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	if len(doc.Blocks) != 4 {
+		t.Fatalf("len(Blocks) = %d, want 4", len(doc.Blocks))
+	}
+	entries := doc.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("len(Entries()) = %d, want 2", len(entries))
+	}
+	if got := entries[0].FirstLineNumber(); got != 4 {
+		t.Errorf("FirstLineNumber() = %d, want 4", got)
+	}
+	ics, err := doc.InputConfigs()
+	if err != nil {
+		t.Fatalf("InputConfigs() error = %v", err)
+	}
+	if got := ics[0].Category; got != "dev-go" {
+		t.Errorf("Category = %q, want dev-go", got)
+	}
+	if got := len(ics[0].Programs["goreleaser"].Binary); got != 2 {
+		t.Errorf("len(Binary) = %d, want 2", got)
+	}
+}
+
+func TestConfigDocumentEdits(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testConfigDocumentData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	entries := doc.Entries()
+	entries[0].SetValue("Category", "dev-util")
+	entries[0].SetValue("Description", "Deliver Go binaries")
+	entries[1].SetValue("Category", "app-misc")
+	doc.RemoveBlock(entries[1])
+	doc.AppendEntry(&InputConfig{
+		Type:             "Github Binary Release",
+		GithubProjectUrl: "https://github.com/twpayne/chezmoi",
+		Category:         "app-admin",
+		Programs: map[string]*Program{
+			"": {Binary: map[string][]string{"amd64": {"chezmoi", "chezmoi"}}},
+		},
+	})
+	want := `# My overlay's config
+# Second header line
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+# Keep this in dev-go
+Category dev-util
+EbuildName goreleaser-bin
+Description Deliver Go binaries
+ProgramName goreleaser
+Binary  amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+Binary arm64=>goreleaser_Linux_arm64.tar.gz > goreleaser > goreleaser
+
+# trailing comment
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+Binary amd64=>chezmoi > chezmoi
+`
+	if diff := cmp.Diff(want, doc.String()); diff != "" {
+		t.Errorf("edited document mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAppendToConfigurationFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "input.config")
+	if err := os.WriteFile(fn, []byte(testConfigDocumentData), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ic := &InputConfig{
+		Type:             "Github Binary Release",
+		GithubProjectUrl: "https://github.com/twpayne/chezmoi",
+	}
+	if err := AppendToConfigurationFile(fn, ic); err != nil {
+		t.Fatalf("AppendToConfigurationFile() error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfigDocumentData + "\nType Github Binary Release\nGithubProjectUrl https://github.com/twpayne/chezmoi\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("appended file mismatch (-want +got):\n%s", diff)
+	}
+	fi, err := os.Stat(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %v, want 0600", fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,192 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"log"
+	"slices"
+	"strconv"
+)
+
+var (
+	// requiredEntryFields can be changed with `config set` but not unset.
+	requiredEntryFields = []string{
+		"Type",
+		"GithubProjectUrl",
+		"GitlabProjectUrl",
+		"GiteaProjectUrl",
+	}
+)
+
+// ConfigRemove removes the selected entry, along with any comments in it, from the configuration file.
+func ConfigRemove(configFile string, selector *EntrySelector, dryRun bool) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	doc, block, ic, err := docs.FindEntry(selector)
+	if err != nil {
+		return err
+	}
+	before := doc.String()
+	doc.RemoveBlock(block)
+	if err := saveConfigDocumentChanges(doc, before, nil, dryRun); err != nil {
+		return err
+	}
+	log.Printf("Removed %s from %s", ic.EbuildName, doc.Filename)
+	return nil
+}
+
+// ConfigRename changes the EbuildName and / or Category of the selected entry, empty values are left as they are.
+func ConfigRename(configFile string, selector *EntrySelector, ebuildName, category string, dryRun bool) error {
+	if ebuildName == "" && category == "" {
+		return fmt.Errorf("no new ebuild name or category given")
+	}
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	doc, block, ic, err := docs.FindEntry(selector)
+	if err != nil {
+		return err
+	}
+	before := doc.String()
+	if ebuildName != "" {
+		block.SetValue("EbuildName", ebuildName)
+		renamed, err := block.InputConfig()
+		if err != nil {
+			return fmt.Errorf("renamed entry doesn't parse: %w", err)
+		}
+		for _, otherDoc := range docs {
+			for _, other := range otherDoc.Entries() {
+				if other == block {
+					continue
+				}
+				otherIc, err := other.InputConfig()
+				if err == nil && otherIc.EbuildName == renamed.EbuildName {
+					return fmt.Errorf("ebuild name %s is already used by the entry at %s:%d", renamed.EbuildName, otherDoc.Filename, other.FirstLineNumber())
+				}
+			}
+		}
+	}
+	if category != "" {
+		block.SetValue("Category", category)
+	}
+	if err := saveConfigDocumentChanges(doc, before, block, dryRun); err != nil {
+		return err
+	}
+	log.Printf("Renamed %s in %s", ic.EbuildName, doc.Filename)
+	return nil
+}
+
+// ConfigSetField sets an entry level field of the selected entry, or removes it when unset is true.
+func ConfigSetField(configFile string, selector *EntrySelector, field, value string, unset, dryRun bool) error {
+	switch {
+	case slices.Contains(programFieldKeys, field):
+		return fmt.Errorf("%s is a program field, it can't be set on the whole entry", field)
+	case field == "Workaround":
+		return fmt.Errorf("use the workaround option to set workarounds")
+	case field == "EbuildVariable":
+		return fmt.Errorf("an entry can have more than one EbuildVariable, edit the configuration file to change them")
+	case !slices.Contains(entryFieldOrder, field):
+		return fmt.Errorf("unknown field %s", field)
+	case unset && slices.Contains(requiredEntryFields, field):
+		return fmt.Errorf("%s is required and can't be unset", field)
+	case !unset && value == "":
+		return fmt.Errorf("no value for %s, use unset to remove it", field)
+	}
+	if field == "Id" && !unset {
+		id, err := ParseEntryId(value)
+		if err != nil {
+			return err
+		}
+		if err := checkEntryIdUnused(configFile, selector, id); err != nil {
+			return err
+		}
+		value = strconv.Itoa(id)
+	}
+	return configEditEntry(configFile, selector, dryRun, func(block *ConfigBlock) {
+		if unset {
+			block.RemoveKey(field)
+		} else {
+			block.SetValue(field, value)
+		}
+	})
+}
+
+// ConfigSetWorkaround adds or changes a workaround of the selected entry, or removes it when unset is true.
+func ConfigSetWorkaround(configFile string, selector *EntrySelector, workaround, value string, unset, dryRun bool) error {
+	if err := ValidateWorkaround(workaround); err != nil {
+		return err
+	}
+	if !unset {
+		if err := ValidateWorkaroundValue(workaround, value); err != nil {
+			return err
+		}
+	}
+	return configEditEntry(configFile, selector, dryRun, func(block *ConfigBlock) {
+		if unset {
+			block.RemoveWorkaround(workaround)
+		} else {
+			block.SetWorkaround(workaround, value)
+		}
+	})
+}
+
+// checkEntryIdUnused returns an error if an entry other than the selected one has the id.
+func checkEntryIdUnused(configFile string, selector *EntrySelector, id int) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	_, _, selected, err := docs.FindEntry(selector)
+	if err != nil {
+		return err
+	}
+	ics, err := docs.InputConfigs()
+	if err != nil {
+		return err
+	}
+	if other := findEntryId(ics, id); other != nil && other.Location() != selected.Location() {
+		return fmt.Errorf("Id %d is already used by %s", id, other.Location())
+	}
+	return nil
+}
+
+func configEditEntry(configFile string, selector *EntrySelector, dryRun bool, edit func(block *ConfigBlock)) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	doc, block, ic, err := docs.FindEntry(selector)
+	if err != nil {
+		return err
+	}
+	before := doc.String()
+	edit(block)
+	if before == doc.String() {
+		log.Printf("No changes to %s", ic.EbuildName)
+		return nil
+	}
+	if err := saveConfigDocumentChanges(doc, before, block, dryRun); err != nil {
+		return err
+	}
+	log.Printf("Updated %s in %s", ic.EbuildName, doc.Filename)
+	return nil
+}
+
+// saveConfigDocumentChanges prints the changes made to the document as a diff, checks the changed block still parses
+// and then atomically writes the document unless dryRun is set.
+func saveConfigDocumentChanges(doc *ConfigDocument, before string, changed *ConfigBlock, dryRun bool) error {
+	fmt.Print(util.UnifiedDiff(doc.Filename, doc.Filename, before, doc.String()))
+	if changed != nil {
+		if _, err := changed.InputConfig(); err != nil {
+			return fmt.Errorf("changed entry doesn't parse: %w", err)
+		}
+	}
+	if dryRun {
+		log.Printf("Dry run, not writing %s", doc.Filename)
+		return nil
+	}
+	return doc.Save()
+}
@@ -0,0 +1,211 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfigEditData = `# Header
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/ente-io/ente
+EbuildName ente-auth-appimage
+Workaround Tag Prefix => auth-
+Binary amd64=>ente-${TAG}-x86_64.AppImage > ente_auth.AppImage
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/ente-io/ente
+EbuildName ente-cli
+# Keep with the other ente entry
+Category app-misc
+Workaround Tag Prefix => cli-
+Binary amd64=>ente-cli-${TAG}-linux-amd64.tar.gz > ente > ente-cli
+`
+
+func TestConfigDocumentFindEntry(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testConfigEditData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	for _, test := range []struct {
+		name     string
+		selector *EntrySelector
+		wantLine int
+		wantErr  bool
+	}{
+		{name: "Exact ebuild name", selector: &EntrySelector{EbuildName: "ente-cli-bin.ebuild"}, wantLine: 9},
+		{name: "Ebuild name without suffix", selector: &EntrySelector{EbuildName: "ente-auth"}, wantLine: 3},
+		{name: "Github url matches both", selector: &EntrySelector{GithubUrl: "https://github.com/ente-io/ente"}, wantErr: true},
+		{name: "Github url and ebuild name", selector: &EntrySelector{GithubUrl: "https://GitHub.com/ente-io/ente/", EbuildName: "ente-cli"}, wantLine: 9},
+		{name: "No match", selector: &EntrySelector{EbuildName: "chezmoi"}, wantErr: true},
+		{name: "Empty selector", selector: &EntrySelector{}, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			block, _, err := doc.FindEntry(test.selector)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("FindEntry() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err == nil && block.FirstLineNumber() != test.wantLine {
+				t.Errorf("FindEntry() line = %d, want %d", block.FirstLineNumber(), test.wantLine)
+			}
+		})
+	}
+}
+
+func TestConfigDocumentFindEntrySuffixInName(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(`Type Github AppImage Release
+GithubProjectUrl https://github.com/probonopd/go-appimage
+EbuildName go-appimage-appimage
+Binary amd64=>appimagetool-${VERSION}-x86_64.AppImage > appimagetool.AppImage
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/example/go
+EbuildName go-bin
+Binary amd64=>go_linux_amd64.tar.gz > go > go
+`))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	for _, test := range []struct {
+		ebuildName string
+		wantLine   int
+		wantErr    bool
+	}{
+		{ebuildName: "go-appimage", wantLine: 1},
+		{ebuildName: "go-appimage-appimage", wantLine: 1},
+		{ebuildName: "go", wantLine: 6},
+		{ebuildName: "go-appimage-bin", wantErr: true},
+	} {
+		t.Run(test.ebuildName, func(t *testing.T) {
+			block, _, err := doc.FindEntry(&EntrySelector{EbuildName: test.ebuildName})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("FindEntry() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err == nil && block.FirstLineNumber() != test.wantLine {
+				t.Errorf("FindEntry() line = %d, want %d", block.FirstLineNumber(), test.wantLine)
+			}
+		})
+	}
+}
+
+func TestConfigEditCommands(t *testing.T) {
+	cliSelector := &EntrySelector{EbuildName: "ente-cli"}
+	for _, test := range []struct {
+		name    string
+		edit    func(fn string) error
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Remove",
+			edit: func(fn string) error {
+				return ConfigRemove(fn, &EntrySelector{EbuildName: "ente-auth-appimage"}, false)
+			},
+			want: "# Header\n" + testConfigEditData[strings.Index(testConfigEditData, "\nType Github Binary"):],
+		},
+		{
+			name: "Rename",
+			edit: func(fn string) error {
+				return ConfigRename(fn, cliSelector, "ente-cli-tool", "app-crypt", false)
+			},
+			want: strings.NewReplacer("EbuildName ente-cli\n", "EbuildName ente-cli-tool\n", "Category app-misc\n", "Category app-crypt\n").Replace(testConfigEditData),
+		},
+		{
+			name: "Rename to a name used by a different type",
+			edit: func(fn string) error {
+				return ConfigRename(fn, &EntrySelector{EbuildName: "ente-auth"}, "ente-cli", "", false)
+			},
+			want: strings.Replace(testConfigEditData, "EbuildName ente-auth-appimage\n", "EbuildName ente-cli\n", 1),
+		},
+		{
+			name: "Set a new field",
+			edit: func(fn string) error {
+				return ConfigSetField(fn, cliSelector, "Description", "Ente's command line tool", false, false)
+			},
+			want: strings.Replace(testConfigEditData, "Category app-misc\n", "Category app-misc\nDescription Ente's command line tool\n", 1),
+		},
+		{
+			name: "Unset a field",
+			edit: func(fn string) error {
+				return ConfigSetField(fn, cliSelector, "Category", "", true, false)
+			},
+			want: strings.Replace(testConfigEditData, "Category app-misc\n", "", 1),
+		},
+		{
+			name: "Unset a required field",
+			edit: func(fn string) error {
+				return ConfigSetField(fn, cliSelector, "GithubProjectUrl", "", true, false)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Set a program field",
+			edit: func(fn string) error {
+				return ConfigSetField(fn, cliSelector, "Binary", "amd64=>a > b", false, false)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Change a workaround",
+			edit: func(fn string) error {
+				return ConfigSetWorkaround(fn, cliSelector, "Tag Prefix", "ente-cli-", false, false)
+			},
+			want: strings.Replace(testConfigEditData, "Workaround Tag Prefix => cli-", "Workaround Tag Prefix => ente-cli-", 1),
+		},
+		{
+			name: "Add a workaround",
+			edit: func(fn string) error {
+				return ConfigSetWorkaround(fn, cliSelector, "Semantic Version Without V", "", false, false)
+			},
+			want: strings.Replace(testConfigEditData, "Workaround Tag Prefix => cli-\n", "Workaround Tag Prefix => cli-\nWorkaround Semantic Version Without V\n", 1),
+		},
+		{
+			name: "Unset a workaround",
+			edit: func(fn string) error {
+				return ConfigSetWorkaround(fn, cliSelector, "Tag Prefix", "", true, false)
+			},
+			want: strings.Replace(testConfigEditData, "Workaround Tag Prefix => cli-\n", "", 1),
+		},
+		{
+			name: "Unknown workaround",
+			edit: func(fn string) error {
+				return ConfigSetWorkaround(fn, cliSelector, "Made Up", "", false, false)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Dry run",
+			edit: func(fn string) error {
+				return ConfigRemove(fn, cliSelector, true)
+			},
+			want: testConfigEditData,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), "input.config")
+			if err := os.WriteFile(fn, []byte(testConfigEditData), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			err := test.edit(fn)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("edit error = %v, wantErr %v", err, test.wantErr)
+			}
+			b, err := os.ReadFile(fn)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if test.wantErr {
+				if string(b) != testConfigEditData {
+					t.Errorf("file was modified by a failed edit")
+				}
+				return
+			}
+			if diff := cmp.Diff(test.want, string(b)); diff != "" {
+				t.Errorf("edit mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
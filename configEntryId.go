@@ -0,0 +1,36 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseEntryId parses the value of an `Id` line, ids are whole numbers from 1.
+func ParseEntryId(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%q isn't an id, use a whole number from 1", value)
+	}
+	return id, nil
+}
+
+// NextEntryId returns the id after the highest one used by the entries.
+func NextEntryId(ics []*InputConfig) int {
+	next := 1
+	for _, ic := range ics {
+		if ic.EntryNumber >= next {
+			next = ic.EntryNumber + 1
+		}
+	}
+	return next
+}
+
+// findEntryId returns the entry using the id, or nil if none of them do.
+func findEntryId(ics []*InputConfig, id int) *InputConfig {
+	for _, ic := range ics {
+		if ic.EntryNumber == id {
+			return ic
+		}
+	}
+	return nil
+}
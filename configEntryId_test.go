@@ -0,0 +1,164 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEntryIdData = `Type Github Binary Release
+Id 3
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+Category dev-go
+Binary amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+
+Type Github Binary Release
+Id 7
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+`
+
+func TestParseEntryId(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testEntryIdData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got []int
+	for _, ic := range ics {
+		got = append(got, ic.EntryNumber)
+	}
+	if diff := cmp.Diff([]int{3, 0, 7}, got); diff != "" {
+		t.Errorf("EntryNumber mismatch (-want +got):\n%s", diff)
+	}
+	if next := NextEntryId(ics); next != 8 {
+		t.Errorf("NextEntryId() = %d, want 8", next)
+	}
+	if next := NextEntryId(nil); next != 1 {
+		t.Errorf("NextEntryId(nil) = %d, want 1", next)
+	}
+	if s := ics[0].String(); !strings.HasPrefix(s, "Type Github Binary Release\nId 3\nGithubProjectUrl") {
+		t.Errorf("String() = %s, want the Id after the Type", s)
+	}
+	reparsed, err := ParseInputConfigReader(strings.NewReader(ics[2].String()))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() of String() error = %v", err)
+	}
+	if reparsed[0].EntryNumber != 7 {
+		t.Errorf("EntryNumber after String() = %d, want 7", reparsed[0].EntryNumber)
+	}
+	for _, input := range []string{"Id 0", "Id seven", "Id 1\nId 2"} {
+		entry := "Type Github Binary Release\n" + input + "\nGithubProjectUrl https://github.com/arran4/g2\n"
+		if _, err := ParseInputConfigReader(strings.NewReader(entry)); err == nil || !strings.Contains(err.Error(), "on Id") {
+			t.Errorf("ParseInputConfigReader(%q) error = %v, want an Id error", input, err)
+		}
+	}
+}
+
+func TestFindEntryById(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testEntryIdData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	_, ic, err := doc.FindEntry(&EntrySelector{Id: 7})
+	if err != nil {
+		t.Fatalf("FindEntry() error = %v", err)
+	}
+	if ic.EbuildName != "chezmoi-bin.ebuild" {
+		t.Errorf("FindEntry() = %s, want chezmoi-bin.ebuild", ic.EbuildName)
+	}
+	if _, _, err := doc.FindEntry(&EntrySelector{Id: 3, EbuildName: "chezmoi"}); err == nil {
+		t.Errorf("FindEntry() with an id and the ebuild name of another entry should fail")
+	}
+	if _, _, err := doc.FindEntry(&EntrySelector{Id: 4}); err == nil {
+		t.Errorf("FindEntry() with an unused id should fail")
+	}
+}
+
+func TestConfigSetEntryId(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "input.config")
+	if err := os.WriteFile(fn, []byte(testEntryIdData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigSetField(fn, &EntrySelector{EbuildName: "jan"}, "Id", "7", false, false); err == nil || !strings.Contains(err.Error(), "Id 7 is already used by chezmoi-bin.ebuild") {
+		t.Errorf("ConfigSetField() with a used id error = %v", err)
+	}
+	if err := ConfigSetField(fn, &EntrySelector{EbuildName: "jan"}, "Id", "08", false, false); err != nil {
+		t.Fatalf("ConfigSetField() error = %v", err)
+	}
+	if err := ConfigSetField(fn, &EntrySelector{Id: 7}, "Id", "7", false, false); err != nil {
+		t.Errorf("ConfigSetField() with the entry's own id error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(testEntryIdData, "Type Github AppImage Release\n", "Type Github AppImage Release\nId 8\n", 1)
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("ConfigSetField() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLintEntryIds(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(strings.Replace(testEntryIdData, "Id 7", "Id 3", 1) + `
+Type Github Binary Release
+Id -1
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	doc.Filename = "test.config"
+	var got []string
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	want := []string{
+		"test.config:7:0: warning: entry has no Category, app-misc will be used",
+		"test.config:12:0: error: duplicate Id 3, first used on line 2",
+		`test.config:18:4: error: Id "-1" isn't an id, use a whole number from 1`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateGithubWorkflowEntryId(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testEntryIdData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, test := range []struct {
+		ic       *InputConfig
+		workflow string
+		want     string
+	}{
+		{ic: ics[0], workflow: "dev-go-goreleaser-bin-update.yaml", want: "\n# Config entry Id: 3\n\nname:"},
+		{ic: ics[1], workflow: "app-misc-jan-appimage-update.yaml", want: " UTC\n\nname:"},
+	} {
+		if err := test.ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+		b, err := os.ReadFile(filepath.Join(outputDir, test.workflow))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if !strings.Contains(string(b), test.want) {
+			t.Errorf("%s doesn't contain %q:\n%s", test.workflow, test.want, strings.SplitN(string(b), "\n", 5)[:4])
+		}
+	}
+}
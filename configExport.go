@@ -0,0 +1,235 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	// InputConfigJsonSchema describes the document written by `config export` and read by `config import`.
+	//go:embed "schema/inputconfig.schema.json"
+	InputConfigJsonSchema []byte
+	// ConfigExportFormats are the formats `config export` and `config import` understand.
+	ConfigExportFormats = []string{
+		"json",
+		"yaml",
+	}
+)
+
+// ConfigExport is the document form of a configuration file for other tools.
+type ConfigExport struct {
+	Entries []*InputConfig `json:"Entries" yaml:"Entries"`
+}
+
+// ConfigExportFile writes every entry in the configuration file to the output in the format, an empty output or `-`
+// writes to stdout.
+func ConfigExportFile(configFile, format, output string) error {
+	ics, err := ReadConfigurationFile(configFile)
+	if err != nil {
+		return fmt.Errorf("reading configuration file: %s: %w", configFile, err)
+	}
+	b, err := MarshalConfigExport(&ConfigExport{Entries: ics}, format)
+	if err != nil {
+		return err
+	}
+	if output == "" || output == "-" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(output, b, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	log.Printf("Exported %d entries to %s", len(ics), output)
+	return nil
+}
+
+// ConfigImportFile appends the entries in the input file to the configuration file. The format is worked out from the
+// file extension if it's empty. Entries with an ebuild name which is already in the configuration file, or a file it
+// includes, are an error.
+func ConfigImportFile(configFile, format, input string) error {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(input)), ".")
+		if format == "yml" {
+			format = "yaml"
+		}
+	}
+	b, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", input, err)
+	}
+	export, err := UnmarshalConfigExport(b, format)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", input, err)
+	}
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	doc := docs[0]
+	existing, err := docs.InputConfigs()
+	if err != nil {
+		return err
+	}
+	if len(doc.Blocks) == 0 {
+		doc.SetConfigVersion(CurrentConfigVersion)
+	}
+	ebuildNames := map[string]bool{}
+	for _, ic := range existing {
+		ebuildNames[ic.EbuildName] = true
+	}
+	for i, ic := range export.Entries {
+		if ebuildNames[ic.EbuildName] {
+			return fmt.Errorf("entry %d: %s is already in %s", i+1, ic.EbuildName, configFile)
+		}
+		ebuildNames[ic.EbuildName] = true
+		if ic.EntryNumber != 0 {
+			if other := findEntryId(existing, ic.EntryNumber); other != nil {
+				return fmt.Errorf("entry %d: Id %d is already used by %s", i+1, ic.EntryNumber, other.Location())
+			}
+			existing = append(existing, ic)
+		}
+	}
+	for _, ic := range export.Entries {
+		if ic.EntryNumber == 0 {
+			ic.EntryNumber = NextEntryId(existing)
+			existing = append(existing, ic)
+		}
+		doc.AppendEntry(ic)
+	}
+	if err := doc.Save(); err != nil {
+		return err
+	}
+	log.Printf("Imported %d entries into %s", len(export.Entries), configFile)
+	return nil
+}
+
+func MarshalConfigExport(export *ConfigExport, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("json marshal: %w", err)
+		}
+		return append(b, '\n'), nil
+	case "yaml":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(export); err != nil {
+			return nil, fmt.Errorf("yaml marshal: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("yaml marshal: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, use one of: %s", format, strings.Join(ConfigExportFormats, ", "))
+	}
+}
+
+// UnmarshalConfigExport reads an exported document, every entry is put through the configuration file parser so they
+// come out the same as if they had been read from a configuration file.
+func UnmarshalConfigExport(b []byte, format string) (*ConfigExport, error) {
+	export := &ConfigExport{}
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(export); err != nil {
+			return nil, fmt.Errorf("json unmarshal: %w", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(b))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(export); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("yaml unmarshal: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q, use one of: %s", format, strings.Join(ConfigExportFormats, ", "))
+	}
+	for i, ic := range export.Entries {
+		sanitized, err := sanitizeImportedInputConfig(ic)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		export.Entries[i] = sanitized
+	}
+	return export, nil
+}
+
+func sanitizeImportedInputConfig(ic *InputConfig) (*InputConfig, error) {
+	if ic == nil {
+		return nil, fmt.Errorf("empty entry")
+	}
+	if !slices.Contains(InputConfigTypes, ic.Type) {
+		return nil, fmt.Errorf("unknown type %q, use one of: %s", ic.Type, strings.Join(InputConfigTypes, ", "))
+	}
+	for name, program := range ic.Programs {
+		if program == nil {
+			return nil, fmt.Errorf("program %q is empty", name)
+		}
+		if program.ProgramName == "" {
+			program.ProgramName = name
+		}
+		if program.ProgramName != name {
+			return nil, fmt.Errorf("program %q has the ProgramName %q", name, program.ProgramName)
+		}
+	}
+	if err := checkNoNewlines(reflect.ValueOf(ic)); err != nil {
+		return nil, err
+	}
+	ics, err := ParseInputConfigReader(strings.NewReader(ic.String()))
+	if err != nil {
+		return nil, err
+	}
+	if len(ics) != 1 {
+		return nil, fmt.Errorf("expected 1 entry got %d", len(ics))
+	}
+	return ics[0], nil
+}
+
+// checkNoNewlines stops values from adding extra lines to the configuration file when written with String().
+func checkNoNewlines(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return checkNoNewlines(v.Elem())
+		}
+	case reflect.String:
+		if strings.ContainsAny(v.String(), "\r\n") {
+			return fmt.Errorf("value %q contains a new line", v.String())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkNoNewlines(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if err := checkNoNewlines(key); err != nil {
+				return err
+			}
+			if err := checkNoNewlines(v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := checkNoNewlines(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigExportRoundTrip(t *testing.T) {
+	for _, input := range []string{testConfigData, testConfigDocumentData, testConfigEditData} {
+		ics, err := ParseInputConfigReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseInputConfigReader() error = %v", err)
+		}
+		for _, format := range ConfigExportFormats {
+			t.Run(format, func(t *testing.T) {
+				b, err := MarshalConfigExport(&ConfigExport{Entries: ics}, format)
+				if err != nil {
+					t.Fatalf("MarshalConfigExport() error = %v", err)
+				}
+				export, err := UnmarshalConfigExport(b, format)
+				if err != nil {
+					t.Fatalf("UnmarshalConfigExport() error = %v\n%s", err, b)
+				}
+				if len(export.Entries) != len(ics) {
+					t.Fatalf("len(Entries) = %d, want %d", len(export.Entries), len(ics))
+				}
+				for i := range ics {
+					if diff := cmp.Diff(ics[i].String(), export.Entries[i].String()); diff != "" {
+						t.Errorf("entry %d mismatch (-want +got):\n%s", i, diff)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestUnmarshalConfigExportErrors(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+	}{
+		{name: "Unknown field", input: `{"Entries": [{"Type": "Github Binary Release", "GithubProjectUrl": "https://github.com/a/b", "Frobnicate": true}]}`},
+		{name: "Unknown type", input: `{"Entries": [{"Type": "Github Source", "GithubProjectUrl": "https://github.com/a/b"}]}`},
+		{name: "New line", input: `{"Entries": [{"Type": "Github Binary Release", "GithubProjectUrl": "https://github.com/a/b", "Description": "a\nCategory b"}]}`},
+		{name: "Program name mismatch", input: `{"Entries": [{"Type": "Github Binary Release", "GithubProjectUrl": "https://github.com/a/b", "Programs": {"a": {"ProgramName": "b"}}}]}`},
+		{name: "Bad url", input: `{"Entries": [{"Type": "Github Binary Release", "GithubProjectUrl": "https://example.com/a/b"}]}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := UnmarshalConfigExport([]byte(test.input), "json"); err == nil {
+				t.Errorf("UnmarshalConfigExport() expected an error")
+			}
+		})
+	}
+}
+
+func TestConfigImportFile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "input.config")
+	if err := os.WriteFile(configFile, []byte(testConfigDocumentData), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	importFile := filepath.Join(dir, "import.yml")
+	if err := os.WriteFile(importFile, []byte(`Entries:
+  - Type: Github Binary Release
+    GithubProjectUrl: https://github.com/twpayne/chezmoi
+    Category: app-admin
+    Programs:
+      chezmoi:
+        Binary:
+          amd64: ["chezmoi_${VERSION}_linux_amd64.tar.gz", chezmoi, chezmoi]
+`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ConfigImportFile(configFile, "", importFile); err != nil {
+		t.Fatalf("ConfigImportFile() error = %v", err)
+	}
+	b, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := testConfigDocumentData + `
+Type Github Binary Release
+Id 1
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+EbuildName chezmoi-bin.ebuild
+License unknown
+ProgramName chezmoi
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("ConfigImportFile() mismatch (-want +got):\n%s", diff)
+	}
+	if err := ConfigImportFile(configFile, "yaml", importFile); err == nil {
+		t.Errorf("ConfigImportFile() of a duplicate ebuild name should fail")
+	}
+}
+
+// TestInputConfigJsonSchema checks every exported field is in the schema so the two don't drift apart.
+func TestInputConfigJsonSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+		Defs       map[string]struct {
+			Properties map[string]struct {
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(InputConfigJsonSchema, &schema); err != nil {
+		t.Fatalf("schema doesn't parse: %v", err)
+	}
+	for name, v := range map[string]any{"ConfigExport": ConfigExport{}, "InputConfig": InputConfig{}, "Program": Program{}} {
+		properties := map[string]bool{}
+		for property := range schema.Properties {
+			properties[property] = name == "ConfigExport"
+		}
+		for property := range schema.Defs[name].Properties {
+			properties[property] = name != "ConfigExport"
+		}
+		rt := reflect.TypeOf(v)
+		for i := 0; i < rt.NumField(); i++ {
+			tag, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+			if tag == "-" {
+				continue
+			}
+			if !properties[tag] {
+				t.Errorf("%s.%s is missing from the schema", name, tag)
+			}
+		}
+	}
+	if diff := cmp.Diff(InputConfigTypes, schema.Defs["InputConfig"].Properties["Type"].Enum); diff != "" {
+		t.Errorf("schema Type enum mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,242 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"log"
+	"slices"
+	"strings"
+)
+
+var (
+	// arrowFieldKeys are the fields in the `keyword=>file > file` form, their lines are sorted by keyword.
+	arrowFieldKeys = []string{
+		"Binary",
+		"Document",
+		"ManualPage",
+		"ShellCompletionScript",
+	}
+	// namedFieldKeys are the fields in the `name => value` form, their lines are sorted by name.
+	namedFieldKeys = []string{
+		"Workaround",
+		"EbuildVariable",
+	}
+	// listFieldKeys are the fields with a space separated list as the value.
+	listFieldKeys = []string{
+		"Icons",
+		"Dependencies",
+		"Depend",
+		"BDepend",
+		"GoPackages",
+	}
+)
+
+// ConfigFmt rewrites the configuration file and the files it includes in the canonical layout. With check set nothing
+// is written, the changes are shown and it is an error if any file isn't already formatted.
+func ConfigFmt(configFile string, check bool) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	var unformatted []string
+	for _, doc := range docs {
+		before := doc.String()
+		if err := FormatConfigDocument(doc); err != nil {
+			return fmt.Errorf("%s: %w", doc.Filename, err)
+		}
+		if before == doc.String() {
+			continue
+		}
+		unformatted = append(unformatted, doc.Filename)
+		if check {
+			fmt.Print(util.UnifiedDiff(doc.Filename, doc.Filename, before, doc.String()))
+			continue
+		}
+		if err := doc.Save(); err != nil {
+			return err
+		}
+		log.Printf("Formatted %s", doc.Filename)
+	}
+	if check && len(unformatted) > 0 {
+		return fmt.Errorf("%d file(s) need formatting, run `config fmt`: %s", len(unformatted), strings.Join(unformatted, ", "))
+	}
+	return nil
+}
+
+// FormatConfigDocument puts the document in the canonical layout: one blank line between blocks, the fields of each
+// entry in a fixed order with the programs sorted by name, `=>` and `>` spaced the same way as InputConfig.String()
+// and the lines of a field sorted by keyword. Comments are kept with the line after them. The document must parse and
+// not have entries `config migrate` would change, such as files without a `ConfigVersion` which only use fields that
+// haven't changed. The formatted document is checked to read the same as before.
+func FormatConfigDocument(doc *ConfigDocument) error {
+	version, _, err := doc.ConfigVersion()
+	if err != nil {
+		return err
+	}
+	if version < CurrentConfigVersion {
+		for _, block := range doc.Blocks {
+			if !block.IsEntry() {
+				continue
+			}
+			changed, err := MigrateConfigBlock(block.clone(), version)
+			if err != nil {
+				return fmt.Errorf("the entry at line %d can't be migrated from ConfigVersion %d: %w", block.FirstLineNumber(), version, err)
+			}
+			if changed {
+				return fmt.Errorf("the entry at line %d is in the ConfigVersion %d format, run `config migrate` first", block.FirstLineNumber(), version)
+			}
+		}
+	}
+	before, err := doc.InputConfigs()
+	if err != nil {
+		return fmt.Errorf("the file needs to parse before it can be formatted, `config lint` will show the problems: %w", err)
+	}
+	for i, block := range doc.Blocks {
+		block.Separator = nil
+		if i > 0 {
+			block.Separator = []*ConfigLine{{}}
+		}
+		switch {
+		case block.IsEntry():
+			formatEntryBlock(block)
+		case block.IsDefaults():
+			formatDefaultsBlock(block)
+		default:
+			for _, line := range block.Lines {
+				line.Text = formatConfigLine(line)
+			}
+		}
+	}
+	doc.Trailing = nil
+	doc.NoFinalNewline = false
+	after, err := doc.InputConfigs()
+	if err != nil {
+		return fmt.Errorf("formatted file doesn't parse: %w", err)
+	}
+	for i := range before {
+		if before[i].String() != after[i].String() {
+			return fmt.Errorf("formatting changed the entry at line %d", before[i].SourceLine)
+		}
+	}
+	return nil
+}
+
+// commentedLine is a line along with the comments before it, the comments move with the line when it is sorted.
+type commentedLine struct {
+	comments []*ConfigLine
+	line     *ConfigLine
+}
+
+// commentedLines groups the lines of the block, comments after the last field are returned separately.
+func commentedLines(lines []*ConfigLine) ([]*commentedLine, []*ConfigLine) {
+	var result []*commentedLine
+	var comments []*ConfigLine
+	for _, line := range lines {
+		if line.IsComment() {
+			line.Text = strings.TrimSpace(line.Text)
+			comments = append(comments, line)
+			continue
+		}
+		line.Text = formatConfigLine(line)
+		result = append(result, &commentedLine{comments: comments, line: line})
+		comments = nil
+	}
+	return result, comments
+}
+
+// sortFields orders the lines by the position of their key in order, lines with the same key are sorted by keyword
+// or name if it is one of the fields with one, otherwise they keep their order.
+func sortFields(lines []*commentedLine, order []string) {
+	slices.SortStableFunc(lines, func(a, b *commentedLine) int {
+		if d := slices.Index(order, a.line.Key()) - slices.Index(order, b.line.Key()); d != 0 {
+			return d
+		}
+		if key := a.line.Key(); slices.Contains(arrowFieldKeys, key) || slices.Contains(namedFieldKeys, key) {
+			return strings.Compare(fieldKeyword(a.line), fieldKeyword(b.line))
+		}
+		return 0
+	})
+}
+
+func fieldKeyword(line *ConfigLine) string {
+	keyword, _, _ := strings.Cut(line.Value(), "=>")
+	return strings.TrimSpace(keyword)
+}
+
+func appendCommentedLines(result []*ConfigLine, lines []*commentedLine) []*ConfigLine {
+	for _, cl := range lines {
+		result = append(result, cl.comments...)
+		result = append(result, cl.line)
+	}
+	return result
+}
+
+func formatEntryBlock(block *ConfigBlock) {
+	type program struct {
+		name  *commentedLine
+		lines []*commentedLine
+	}
+	var entryLines []*commentedLine
+	unnamed := &program{}
+	var programs []*program
+	current := unnamed
+	lines, trailing := commentedLines(block.Lines)
+	for _, cl := range lines {
+		switch key := cl.line.Key(); {
+		case key == "ProgramName":
+			current = &program{name: cl}
+			programs = append(programs, current)
+		case slices.Contains(programFieldKeys, key):
+			current.lines = append(current.lines, cl)
+		default:
+			entryLines = append(entryLines, cl)
+		}
+	}
+	sortFields(entryLines, entryFieldOrder)
+	result := appendCommentedLines(nil, entryLines)
+	sortFields(unnamed.lines, programFieldKeys)
+	result = appendCommentedLines(result, unnamed.lines)
+	slices.SortStableFunc(programs, func(a, b *program) int {
+		return strings.Compare(a.name.line.Value(), b.name.line.Value())
+	})
+	for _, p := range programs {
+		sortFields(p.lines, programFieldKeys)
+		result = appendCommentedLines(result, append([]*commentedLine{p.name}, p.lines...))
+	}
+	block.Lines = append(result, trailing...)
+}
+
+func formatDefaultsBlock(block *ConfigBlock) {
+	lines, trailing := commentedLines(block.Lines)
+	sortFields(lines, append([]string{"Defaults"}, defaultsFieldKeys...))
+	block.Lines = append(appendCommentedLines(nil, lines), trailing...)
+}
+
+// formatConfigLine returns the text of the line with the spacing of its key and value made canonical.
+func formatConfigLine(line *ConfigLine) string {
+	key := line.Key()
+	value := line.Value()
+	switch {
+	case line.IsComment():
+		return strings.TrimSpace(line.Text)
+	case value == "":
+		return key
+	case slices.Contains(arrowFieldKeys, key):
+		keyword, files, found := strings.Cut(value, "=>")
+		if !found {
+			break
+		}
+		parts := strings.Split(files, ">")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		value = fmt.Sprintf("%s=>%s", strings.TrimSpace(keyword), strings.Join(parts, " > "))
+	case slices.Contains(namedFieldKeys, key):
+		if name, v, found := strings.Cut(value, "=>"); found {
+			value = fmt.Sprintf("%s => %s", strings.TrimSpace(name), strings.TrimSpace(v))
+		}
+	case slices.Contains(listFieldKeys, key):
+		value = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.TrimSpace(key + " " + value)
+}
@@ -0,0 +1,169 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testUnformattedConfigData = `
+
+ConfigVersion   2
+
+
+# Shared settings
+Defaults
+EbuildVariable KEYWORDS=>~amd64
+Category dev-util
+
+Type Github Binary Release
+  Category   app-admin
+Workaround Tag Prefix=>v
+GithubProjectUrl https://github.com/twpayne/chezmoi
+# Comes first after fmt
+Binary arm64=>chezmoi_${VERSION}_linux_arm64.tar.gz>chezmoi >  chezmoi
+Binary   amd64 => chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+ProgramName zz
+Binary amd64=>zz.tar.gz > zz
+Dependencies   sys-libs/glibc    sys-libs/zlib
+# Named programs are sorted
+ProgramName aa
+DesktopFile aa.desktop
+Binary amd64=>aa.tar.gz > aa
+Id 4
+# trailing comment
+
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan`
+
+const testFormattedConfigData = `ConfigVersion 2
+
+# Shared settings
+Defaults
+Category dev-util
+EbuildVariable KEYWORDS => ~amd64
+
+Type Github Binary Release
+Id 4
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+Workaround Tag Prefix => v
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+# Comes first after fmt
+Binary arm64=>chezmoi_${VERSION}_linux_arm64.tar.gz > chezmoi > chezmoi
+# Named programs are sorted
+ProgramName aa
+DesktopFile aa.desktop
+Binary amd64=>aa.tar.gz > aa
+ProgramName zz
+Dependencies sys-libs/glibc sys-libs/zlib
+Binary amd64=>zz.tar.gz > zz
+# trailing comment
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`
+
+func TestFormatConfigDocument(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testUnformattedConfigData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	if err := FormatConfigDocument(doc); err != nil {
+		t.Fatalf("FormatConfigDocument() error = %v", err)
+	}
+	if diff := cmp.Diff(testFormattedConfigData, doc.String()); diff != "" {
+		t.Errorf("FormatConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+	formatted := doc.String()
+	if err := FormatConfigDocument(doc); err != nil {
+		t.Fatalf("FormatConfigDocument() second time error = %v", err)
+	}
+	if diff := cmp.Diff(formatted, doc.String()); diff != "" {
+		t.Errorf("FormatConfigDocument() isn't stable (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatConfigDocumentWithoutConfigVersion(t *testing.T) {
+	input := "Type   Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nBinary amd64=>g2_linux_amd64.tar.gz>g2\nCategory app-admin\n"
+	doc, err := ParseConfigDocument(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	if err := FormatConfigDocument(doc); err != nil {
+		t.Fatalf("FormatConfigDocument() error = %v", err)
+	}
+	want := "Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nCategory app-admin\nBinary amd64=>g2_linux_amd64.tar.gz > g2\n"
+	if diff := cmp.Diff(want, doc.String()); diff != "" {
+		t.Errorf("FormatConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatConfigDocumentErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Older version",
+			input:   "Type Github AppImage\nGithubProjectUrl https://github.com/arran4/g2\nInstalledFilename g2\nReleasesFilename amd64=>g2\n",
+			wantErr: "the entry at line 1 is in the ConfigVersion 1 format, run `config migrate` first",
+		},
+		{
+			name:    "Doesn't parse",
+			input:   "ConfigVersion 2\n\nType Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nMadeUp field\n",
+			wantErr: "the file needs to parse before it can be formatted",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := ParseConfigDocument(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("ParseConfigDocument() error = %v", err)
+			}
+			err = FormatConfigDocument(doc)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("FormatConfigDocument() error = %v, want it to contain %s", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.input, doc.String()); diff != "" {
+				t.Errorf("FormatConfigDocument() changed the document on error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigFmt(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config": testUnformattedConfigData + "\n\nInclude   other.config\n",
+		"other.config": "ConfigVersion 2\n",
+	})
+	fn := filepath.Join(dir, "input.config")
+	if err := ConfigFmt(fn, true); err == nil || !strings.Contains(err.Error(), "1 file(s) need formatting") {
+		t.Errorf("ConfigFmt() check error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testUnformattedConfigData+"\n\nInclude   other.config\n", string(b)); diff != "" {
+		t.Errorf("ConfigFmt() check changed the file (-want +got):\n%s", diff)
+	}
+	if err := ConfigFmt(fn, false); err != nil {
+		t.Fatalf("ConfigFmt() error = %v", err)
+	}
+	b, err = os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testFormattedConfigData+"\nInclude other.config\n", string(b)); diff != "" {
+		t.Errorf("ConfigFmt() mismatch (-want +got):\n%s", diff)
+	}
+	if err := ConfigFmt(fn, true); err != nil {
+		t.Errorf("ConfigFmt() check of a formatted file error = %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ResolveInclude returns the files an `Include` pattern in fromFile refers to, relative patterns are relative to the
+// directory of fromFile. A pattern without glob characters must match an existing file, a glob may match nothing.
+func ResolveInclude(fromFile, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(fromFile), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("bad pattern: %w", err)
+	}
+	if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+		return nil, fmt.Errorf("%s: %w", pattern, os.ErrNotExist)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// configIncludeStack tracks the files being read so an include loop is an error rather than a stack overflow.
+type configIncludeStack []string
+
+func (cis configIncludeStack) push(filename string) (configIncludeStack, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	if i := slices.Index(cis, abs); i >= 0 {
+		return nil, fmt.Errorf("include loop: %s", strings.Join(append(slices.Clone(cis[i:]), abs), " -> "))
+	}
+	return append(slices.Clone(cis), abs), nil
+}
+
+// ReadConfigurationTree reads the configuration file and the files it includes, in the order they are included.
+// Each entry records the file and line it came from.
+func ReadConfigurationTree(filename string) ([]*InputConfig, error) {
+	return readConfigurationTree(filename, nil, nil)
+}
+
+func readConfigurationTree(filename string, stack configIncludeStack, defaults *InputConfigDefaults) ([]*InputConfig, error) {
+	stack, err := stack.push(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("opening configuration file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	parser := &InputConfigParser{
+		Filename: filename,
+		Defaults: defaults,
+		Include: func(lineNumber int, pattern string, defaults *InputConfigDefaults) ([]*InputConfig, error) {
+			files, err := ResolveInclude(filename, pattern)
+			if err != nil {
+				return nil, err
+			}
+			var result []*InputConfig
+			for _, file := range files {
+				ics, err := readConfigurationTree(file, stack, defaults)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, ics...)
+			}
+			return result, nil
+		},
+	}
+	return parser.Parse(f)
+}
+
+// ReadConfigurationDir reads every `.config` file in the directory, and the files they include, in name order.
+func ReadConfigurationDir(dir string) ([]*InputConfig, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.config"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .config files in %s", dir)
+	}
+	sort.Strings(files)
+	var result []*InputConfig
+	for _, file := range files {
+		ics, err := ReadConfigurationTree(file)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ics...)
+	}
+	return result, nil
+}
+
+// LoadConfigDocumentTree loads the configuration file followed by every file it includes, depth first, so entries in
+// the whole tree can be edited. A missing root file is treated as empty.
+func LoadConfigDocumentTree(filename string) (ConfigDocuments, error) {
+	return loadConfigDocumentTree(filename, nil, nil)
+}
+
+func loadConfigDocumentTree(filename string, stack configIncludeStack, defaults *InputConfigDefaults) (ConfigDocuments, error) {
+	stack, err := stack.push(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(stack) > 1 {
+		if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("opening configuration file: %w", err)
+		}
+	}
+	doc, err := LoadConfigDocument(filename)
+	if err != nil {
+		return nil, err
+	}
+	doc.InheritedDefaults = defaults
+	result := ConfigDocuments{doc}
+	for _, block := range doc.Blocks {
+		switch {
+		case block.IsDefaults():
+			defaults, err = block.Defaults(defaults)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: Defaults: %w", filename, block.FirstLineNumber(), err)
+			}
+			continue
+		case block.IsEntry():
+			continue
+		}
+		for _, line := range block.Lines {
+			if line.Key() != "Include" {
+				continue
+			}
+			files, err := ResolveInclude(filename, line.Value())
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: Include %s: %w", filename, line.Number, line.Value(), err)
+			}
+			for _, file := range files {
+				docs, err := loadConfigDocumentTree(file, stack, defaults)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: Include %s: %w", filename, line.Number, line.Value(), err)
+				}
+				result = append(result, docs...)
+			}
+		}
+	}
+	return result, nil
+}
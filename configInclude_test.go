@@ -0,0 +1,219 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestConfigTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return dir
+}
+
+const testIncludeRoot = `# Root config
+Include apps/*.config
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+Category dev-go
+Binary amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+`
+
+const testIncludeApps = `Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Category app-misc
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`
+
+const testIncludeTools = `
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+`
+
+func TestReadConfigurationTree(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config":       testIncludeRoot,
+		"apps/jan.config":    testIncludeApps,
+		"apps/tools.config":  testIncludeTools,
+		"apps/ignored.other": "not a config",
+	})
+	ics, err := ReadConfigurationTree(filepath.Join(dir, "input.config"))
+	if err != nil {
+		t.Fatalf("ReadConfigurationTree() error = %v", err)
+	}
+	var got []string
+	for _, ic := range ics {
+		rel, _ := filepath.Rel(dir, ic.SourceFile)
+		got = append(got, fmt.Sprintf("%s %s:%d", ic.EbuildName, rel, ic.SourceLine))
+	}
+	want := []string{
+		"jan-appimage.ebuild apps/jan.config:1",
+		"chezmoi-bin.ebuild apps/tools.config:3",
+		"goreleaser-bin.ebuild input.config:4",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadConfigurationTree() mismatch (-want +got):\n%s", diff)
+	}
+	docs, err := LoadConfigDocumentTree(filepath.Join(dir, "input.config"))
+	if err != nil {
+		t.Fatalf("LoadConfigDocumentTree() error = %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("len(LoadConfigDocumentTree()) = %d, want 3", len(docs))
+	}
+	dirIcs, err := ReadConfigurationDir(filepath.Join(dir, "apps"))
+	if err != nil {
+		t.Fatalf("ReadConfigurationDir() error = %v", err)
+	}
+	if len(dirIcs) != 2 {
+		t.Errorf("len(ReadConfigurationDir()) = %d, want 2", len(dirIcs))
+	}
+}
+
+func TestReadConfigurationTreeErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "Error in an included file",
+			files: map[string]string{
+				"input.config":    "Include apps/jan.config\n",
+				"apps/jan.config": "Type Github AppImage Release\nGithubProjectUrl https://github.com/janhq/jan\nFrobnicate\n",
+			},
+			wantErr: "input.config:1: Include apps/jan.config: DIR/apps/jan.config:3: invalid line: Frobnicate",
+		},
+		{
+			name: "Missing file",
+			files: map[string]string{
+				"input.config": "# Header\nInclude missing.config\n",
+			},
+			wantErr: "input.config:2: Include missing.config: DIR/missing.config: file does not exist",
+		},
+		{
+			name: "Loop",
+			files: map[string]string{
+				"input.config": "Include other.config\n",
+				"other.config": "Include input.config\n",
+			},
+			wantErr: "include loop: DIR/input.config -> DIR/other.config -> DIR/input.config",
+		},
+		{
+			name: "Include inside an entry",
+			files: map[string]string{
+				"input.config": testIncludeApps + "Include other.config\n",
+			},
+			wantErr: "input.config:5: Include must be separated from the entry starting on line 1 by a blank line",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeTestConfigTree(t, test.files)
+			_, err := ReadConfigurationTree(filepath.Join(dir, "input.config"))
+			if err == nil {
+				t.Fatalf("ReadConfigurationTree() expected an error")
+			}
+			if got := strings.ReplaceAll(err.Error(), dir, "DIR"); !strings.Contains(got, test.wantErr) {
+				t.Errorf("ReadConfigurationTree() error = %s, want it to contain %s", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateGithubWorkflowsConfigFile(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config":      testIncludeRoot,
+		"apps/jan.config":   testIncludeApps,
+		"apps/tools.config": testIncludeTools,
+	})
+	outputDir := filepath.Join(dir, "output")
+	if err := GenerateGithubWorkflows(filepath.Join(dir, "input.config"), outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflows() error = %v", err)
+	}
+	for workflow, configFile := range map[string]string{
+		"app-misc-jan-appimage-update.yaml": filepath.Join(dir, "apps/jan.config"),
+		"app-admin-chezmoi-bin-update.yaml": filepath.Join(dir, "apps/tools.config"),
+		"dev-go-goreleaser-bin-update.yaml": filepath.Join(dir, "input.config"),
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, workflow))
+		if err != nil {
+			t.Errorf("ReadFile() error = %v", err)
+			continue
+		}
+		firstLine, _, _ := strings.Cut(string(b), "\n")
+		if !strings.Contains(firstLine, " "+configFile+" ") {
+			t.Errorf("%s header %q doesn't name %s", workflow, firstLine, configFile)
+		}
+	}
+}
+
+func TestConfigEditIncludedFile(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config":      testIncludeRoot,
+		"apps/jan.config":   testIncludeApps,
+		"apps/tools.config": testIncludeTools,
+	})
+	if err := ConfigSetField(filepath.Join(dir, "input.config"), &EntrySelector{EbuildName: "chezmoi"}, "Description", "Dotfiles", false, false); err != nil {
+		t.Fatalf("ConfigSetField() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "apps/tools.config"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := strings.Replace(testIncludeTools, "Category app-admin\n", "Category app-admin\nDescription Dotfiles\n", 1)
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("ConfigSetField() mismatch (-want +got):\n%s", diff)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "input.config")); string(b) != testIncludeRoot {
+		t.Errorf("root config was modified")
+	}
+}
+
+func TestLintConfigDocumentsIncludes(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config":    testIncludeRoot + "\nInclude missing.config\nInclude none/*.config\n",
+		"apps/jan.config": testIncludeApps + "\n" + testIncludeApps,
+	})
+	docs, err := LoadConfigDocumentTree(filepath.Join(dir, "input.config"))
+	if err == nil {
+		t.Fatalf("LoadConfigDocumentTree() expected an error for the missing include")
+	}
+	root, err := LoadConfigDocument(filepath.Join(dir, "input.config"))
+	if err != nil {
+		t.Fatalf("LoadConfigDocument() error = %v", err)
+	}
+	jan, err := LoadConfigDocument(filepath.Join(dir, "apps/jan.config"))
+	if err != nil {
+		t.Fatalf("LoadConfigDocument() error = %v", err)
+	}
+	docs = ConfigDocuments{root, jan}
+	var got []string
+	for _, diagnostic := range LintConfigDocuments(docs) {
+		got = append(got, strings.ReplaceAll(strings.SplitN(diagnostic.String(), "\n", 2)[0], dir, "DIR"))
+	}
+	want := []string{
+		"DIR/input.config:9:9: error: Include missing.config: DIR/missing.config: file does not exist",
+		"DIR/input.config:10:9: warning: Include none/*.config doesn't match any files",
+		"DIR/apps/jan.config:6:0: error: duplicate EbuildName jan-appimage.ebuild, first used on line 1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocuments() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,528 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"slices"
+	"strings"
+)
+
+type LintSeverity string
+
+// projectUrlExamples are shown in the hints for each of the ProjectUrlFields.
+var projectUrlExamples = map[string]string{
+	"GithubProjectUrl": "https://github.com/owner/repo",
+	"GitlabProjectUrl": "https://gitlab.com/group/project",
+	"GiteaProjectUrl":  "https://codeberg.org/owner/repo",
+}
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// LintDiagnostic is a single problem found in a configuration file. Line and Column are 1 based, Column is 0 when the
+// problem is with the whole line.
+type LintDiagnostic struct {
+	Filename string
+	Line     int
+	Column   int
+	Severity LintSeverity
+	Message  string
+	Hint     string
+}
+
+func (ld *LintDiagnostic) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s:%d:%d: %s: %s", ld.Filename, ld.Line, ld.Column, ld.Severity, ld.Message))
+	if ld.Hint != "" {
+		sb.WriteString(fmt.Sprintf("\n\thint: %s", ld.Hint))
+	}
+	return sb.String()
+}
+
+// ConfigLint prints every problem found in the configuration file and the files it includes, it returns an error if
+// any of them are errors.
+func ConfigLint(configFile string) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		// Lint what can be loaded, the broken include will be reported.
+		doc, rootErr := LoadConfigDocument(configFile)
+		if rootErr != nil {
+			return rootErr
+		}
+		docs = ConfigDocuments{doc}
+	}
+	diagnostics := LintConfigDocuments(docs)
+	errorCount := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == LintError {
+			errorCount++
+		}
+		fmt.Println(diagnostic.String())
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d error(s) and %d warning(s) found", errorCount, len(diagnostics)-errorCount)
+	}
+	return nil
+}
+
+type configLinter struct {
+	doc         *ConfigDocument
+	diagnostics []*LintDiagnostic
+	// started is set once an entry, Defaults or Include has been seen, a `ConfigVersion` line must come before them.
+	started bool
+}
+
+func (cl *configLinter) add(line *ConfigLine, column int, severity LintSeverity, message, hint string) {
+	cl.diagnostics = append(cl.diagnostics, &LintDiagnostic{
+		Filename: cl.doc.Filename,
+		Line:     line.Number,
+		Column:   column,
+		Severity: severity,
+		Message:  message,
+		Hint:     hint,
+	})
+}
+
+// LintConfigDocument checks every block of the document and returns the problems sorted by position.
+func LintConfigDocument(doc *ConfigDocument) []*LintDiagnostic {
+	return LintConfigDocuments(ConfigDocuments{doc})
+}
+
+// LintConfigDocuments checks every block of the documents, ebuild names must be unique across all of them. The
+// problems are returned in document order then sorted by position.
+func LintConfigDocuments(docs ConfigDocuments) []*LintDiagnostic {
+	var result []*LintDiagnostic
+	ebuildNames := map[string]*LintDiagnostic{}
+	ids := map[int]*LintDiagnostic{}
+	for _, doc := range docs {
+		result = append(result, lintConfigDocument(doc, ebuildNames, ids)...)
+	}
+	return result
+}
+
+// lintConfigDocument checks the document, ebuildNames and ids have where each ebuild name and id was first used in this
+// or previous documents.
+func lintConfigDocument(doc *ConfigDocument, ebuildNames map[string]*LintDiagnostic, ids map[int]*LintDiagnostic) []*LintDiagnostic {
+	cl := &configLinter{doc: doc}
+	defaults := doc.InheritedDefaults
+	version := cl.lintConfigVersion()
+	for _, block := range doc.Blocks {
+		if block.IsDefaults() {
+			cl.started = true
+			if cl.lintDefaults(block, defaults) {
+				// Errors are reported by lintDefaults so the rest of the file is checked with the defaults so far.
+				if merged, err := block.Defaults(defaults); err == nil {
+					defaults = merged
+				}
+			}
+			continue
+		}
+		if !block.IsEntry() {
+			cl.lintNonEntry(block)
+			continue
+		}
+		cl.started = true
+		if version < CurrentConfigVersion {
+			migrated := block.clone()
+			changed, err := MigrateConfigBlock(migrated, version)
+			if err != nil {
+				cl.add(block.Lines[0], 0, LintError, fmt.Sprintf("entry can't be migrated from ConfigVersion %d: %s", version, err), "fix the entry by hand and add `ConfigVersion "+fmt.Sprint(CurrentConfigVersion)+"` to the start of the file")
+				continue
+			}
+			if changed {
+				cl.add(findLine(block, "Type"), 0, LintWarning, fmt.Sprintf("entry is in the ConfigVersion %d format", version), "run `config migrate` to update the file")
+			}
+			block = migrated
+		}
+		before := len(cl.diagnostics)
+		cl.lintEntry(block, defaults)
+		ic, err := block.InputConfigWithDefaults(defaults)
+		if err != nil {
+			if !slices.ContainsFunc(cl.diagnostics[before:], func(d *LintDiagnostic) bool { return d.Severity == LintError }) {
+				cl.add(block.Lines[0], 0, LintError, fmt.Sprintf("entry can't be parsed: %s", err), "")
+			}
+			continue
+		}
+		if ic.WorkaroundTagsWithoutReleases() && ic.UsesReleaseUrl() && ic.ReleaseUrl == "" {
+			cl.add(findLine(block, "Type"), 0, LintError, fmt.Sprintf("workaround %s has no releases to download the release files from", tagsWithoutReleasesWorkaround.Name), "add `ReleaseUrl` with where the release files are, ${TAG} and ${VERSION} are replaced")
+		}
+		line := findLine(block, "EbuildName")
+		if line == nil {
+			line = findLine(block, "Type")
+		}
+		lintUnique(cl, line, "EbuildName", ic.EbuildName, ebuildNames, "give one of the entries a different `EbuildName`")
+		if ic.EntryNumber != 0 {
+			lintUnique(cl, findLine(block, "Id"), "Id", ic.EntryNumber, ids, "give one of the entries a different `Id`")
+		}
+	}
+	slices.SortStableFunc(cl.diagnostics, func(a, b *LintDiagnostic) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return cl.diagnostics
+}
+
+// lintUnique reports the line if the value was already used, first has where each value was first used.
+func lintUnique[T comparable](cl *configLinter, line *ConfigLine, key string, value T, first map[T]*LintDiagnostic, hint string) {
+	previous, ok := first[value]
+	switch {
+	case !ok:
+		first[value] = &LintDiagnostic{Filename: cl.doc.Filename, Line: line.Number}
+	case previous.Filename == cl.doc.Filename:
+		cl.add(line, 0, LintError, fmt.Sprintf("duplicate %s %v, first used on line %d", key, value, previous.Line), hint)
+	default:
+		cl.add(line, 0, LintError, fmt.Sprintf("duplicate %s %v, first used at %s:%d", key, value, previous.Filename, previous.Line), hint)
+	}
+}
+
+func (cl *configLinter) lintNonEntry(block *ConfigBlock) {
+	for _, line := range block.Lines {
+		switch line.Key() {
+		case "":
+		case "ConfigVersion":
+			if cl.started {
+				cl.add(line, keyColumn(line), LintError, "ConfigVersion must come before the first entry, Defaults or Include", "move it to the start of the file")
+			}
+		case "Include":
+			cl.started = true
+			cl.lintInclude(line)
+		case "Defaults":
+			cl.add(line, keyColumn(line), LintError, "Defaults must be the first line of its block", "put a blank line before `Defaults`")
+			return
+		default:
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s is outside of an entry", line.Key()), "start the entry with a `Type` line, or separate it from the entry above with a comment rather than a blank line")
+			return
+		}
+	}
+}
+
+// lintConfigVersion checks the `ConfigVersion` line and returns the version the entries are linted as.
+func (cl *configLinter) lintConfigVersion() int {
+	version, line, err := cl.doc.ConfigVersion()
+	if err != nil {
+		cl.add(line, valueColumn(line), LintError, err.Error(), fmt.Sprintf("this version supports up to ConfigVersion %d", CurrentConfigVersion))
+		return CurrentConfigVersion
+	}
+	return version
+}
+
+func (cl *configLinter) lintInclude(line *ConfigLine) {
+	if line.Value() == "" {
+		cl.add(line, keyColumn(line), LintError, "Include has no file or pattern", "use `Include path/to/file.config` or `Include dir/*.config`")
+		return
+	}
+	if cl.doc.Filename == "" {
+		return
+	}
+	files, err := ResolveInclude(cl.doc.Filename, line.Value())
+	switch {
+	case err != nil:
+		cl.add(line, valueColumn(line), LintError, fmt.Sprintf("Include %s: %s", line.Value(), err), "paths are relative to the directory of the file with the `Include`")
+	case len(files) == 0:
+		cl.add(line, valueColumn(line), LintWarning, fmt.Sprintf("Include %s doesn't match any files", line.Value()), "paths are relative to the directory of the file with the `Include`")
+	}
+}
+
+// lintDefaults checks a `Defaults` block, defaults are the ones before it, and returns true if it has no errors.
+func (cl *configLinter) lintDefaults(block *ConfigBlock, defaults *InputConfigDefaults) bool {
+	before := len(cl.diagnostics)
+	for _, line := range block.Lines {
+		key := line.Key()
+		switch {
+		case key == "":
+		case key == "Defaults":
+			if line.Value() != "" {
+				cl.add(line, valueColumn(line), LintError, "Defaults doesn't take a value", "put the default fields on the lines after `Defaults`")
+			}
+		case !slices.Contains(defaultsFieldKeys, key):
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s can't be used in Defaults", key), fmt.Sprintf("use one of: %s, and start entries after a blank line", strings.Join(defaultsFieldKeys, ", ")))
+		case key == "Category" || key == "License":
+			if line.Value() == "" {
+				cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s has no value", key), "")
+			}
+		case key == "Workaround":
+			cl.lintWorkaround(line, defaults)
+		case key == "EbuildVariable":
+			cl.lintEbuildVariable(line)
+		}
+	}
+	return len(cl.diagnostics) == before
+}
+
+// lintWorkaround checks a `Workaround` line, defaults are the ones the line's block gets so a `Workaround -Name` which
+// doesn't remove anything is reported.
+func (cl *configLinter) lintWorkaround(line *ConfigLine, defaults *InputConfigDefaults) {
+	name, value, _ := strings.Cut(line.Value(), "=>")
+	name = strings.TrimSpace(name)
+	removedName, removal := strings.CutPrefix(name, workaroundRemovalPrefix)
+	if removal {
+		name = strings.TrimSpace(removedName)
+	}
+	wd, err := LookupWorkaround(name)
+	if err != nil {
+		cl.add(line, valueColumn(line), LintError, err.Error(), fmt.Sprintf("remove the line or use one of: %s, `config workarounds` describes them", strings.Join(WorkaroundNames(), ", ")))
+		return
+	}
+	if removal {
+		if strings.TrimSpace(value) != "" {
+			cl.add(line, valueColumn(line), LintWarning, fmt.Sprintf("removing workaround %s doesn't take a value, it is ignored", name), fmt.Sprintf("use `Workaround %s%s`", workaroundRemovalPrefix, name))
+		}
+		if _, ok := defaults.workarounds()[name]; !ok {
+			cl.add(line, valueColumn(line), LintWarning, fmt.Sprintf("workaround %s isn't set by Defaults, there is nothing to remove", name), "remove the line")
+		}
+		return
+	}
+	if _, err := wd.ParseValue(value); err != nil {
+		cl.add(line, valueColumn(line), LintError, err.Error(), wd.Description)
+	} else if !wd.TakesValue() && strings.TrimSpace(value) != "" {
+		cl.add(line, valueColumn(line), LintWarning, fmt.Sprintf("workaround %s doesn't take a value, it is ignored", name), fmt.Sprintf("use `%s`", wd.Example()))
+	} else if wd.Warnings != nil {
+		for _, warning := range wd.Warnings(value) {
+			cl.add(line, valueColumn(line), LintWarning, fmt.Sprintf("workaround %s: %s", name, warning), fmt.Sprintf("use `%s`", wd.Example()))
+		}
+	}
+}
+
+func (cl *configLinter) lintEbuildVariable(line *ConfigLine) {
+	name, _, found := strings.Cut(line.Value(), "=>")
+	name = strings.TrimSpace(name)
+	switch {
+	case !found:
+		cl.add(line, valueColumn(line), LintError, "EbuildVariable is missing `=>` between the name and the value", "use `EbuildVariable NAME => value`")
+	case ValidateEbuildVariableName(name) != nil:
+		cl.add(line, valueColumn(line), LintError, ValidateEbuildVariableName(name).Error(), "use `EbuildVariable NAME => value`")
+	case slices.Contains(generatedEbuildVariables, name):
+		cl.add(line, valueColumn(line), LintError, fmt.Sprintf("EbuildVariable %s is generated from the rest of the entry and can't be set", name), fmt.Sprintf("these can't be set: %s", strings.Join(generatedEbuildVariables, ", ")))
+	}
+}
+
+func (cl *configLinter) lintEntry(block *ConfigBlock, defaults *InputConfigDefaults) {
+	knownKeys := slices.Concat(entryFieldOrder, programFieldKeys)
+	for _, line := range block.Lines {
+		key := line.Key()
+		switch {
+		case key == "":
+		case key == "Include":
+			cl.add(line, keyColumn(line), LintError, "Include is inside an entry", "put a blank line between the entry and the `Include`")
+		case key == "ConfigVersion":
+			cl.add(line, keyColumn(line), LintError, "ConfigVersion is inside an entry", "put a blank line between `ConfigVersion` and the entry")
+		case !slices.Contains(knownKeys, key):
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("unknown field %s", key), fmt.Sprintf("known fields are: %s", strings.Join(knownKeys, ", ")))
+		case key == "Type":
+			if !slices.Contains(InputConfigTypes, line.Value()) {
+				cl.add(line, valueColumn(line), LintError, fmt.Sprintf("unknown type %s", line.Value()), fmt.Sprintf("use one of: %s", strings.Join(InputConfigTypes, ", ")))
+			}
+		case key == "GithubProjectUrl":
+			if _, _, err := util.ExtractGithubOwnerRepo(line.Value()); err != nil {
+				cl.add(line, valueColumn(line), LintError, err.Error(), "use the form https://github.com/owner/repo")
+			}
+		case key == "GitlabProjectUrl":
+			if _, _, err := util.ExtractGitlabProject(line.Value()); err != nil {
+				cl.add(line, valueColumn(line), LintError, err.Error(), "use the form https://gitlab.com/group/project")
+			}
+		case key == "GiteaProjectUrl":
+			if _, _, _, err := util.ExtractGiteaOwnerRepo(line.Value()); err != nil {
+				cl.add(line, valueColumn(line), LintError, err.Error(), "use the form https://codeberg.org/owner/repo")
+			}
+		case key == "Id":
+			if _, err := ParseEntryId(line.Value()); err != nil {
+				cl.add(line, valueColumn(line), LintError, fmt.Sprintf("Id %s", err), "")
+			}
+		case key == "Category":
+			if line.Value() == "" {
+				cl.add(line, keyColumn(line), LintError, "Category has no value", "set it to a Gentoo category such as `app-misc`")
+			}
+		case key == "Workaround":
+			cl.lintWorkaround(line, defaults)
+		case key == "EbuildVariable":
+			cl.lintEbuildVariable(line)
+		case key == "BuildSystem":
+			if !slices.Contains(SourceBuildSystems, line.Value()) {
+				cl.add(line, valueColumn(line), LintError, fmt.Sprintf("unknown build system %s", line.Value()), fmt.Sprintf("use one of: %s, `config add github-release-source` detects it", strings.Join(SourceBuildSystems, ", ")))
+			}
+		case key == "GoDependencies":
+			if !slices.Contains(GoDependencyModes, line.Value()) {
+				cl.add(line, valueColumn(line), LintError, fmt.Sprintf("unknown Go dependency mode %s", line.Value()), fmt.Sprintf("use one of: %s", strings.Join(GoDependencyModes, ", ")))
+			}
+		case key == "InstallPath":
+			if err := ValidateInstallPath(line.Value()); err != nil {
+				cl.add(line, valueColumn(line), LintError, err.Error(), fmt.Sprintf("leave it out to use %s", DefaultInstallPath))
+			}
+		case key == "Binary", key == "Document", key == "ManualPage":
+			cl.lintArrowLine(line, false)
+		case key == "ShellCompletionScript":
+			cl.lintArrowLine(line, true)
+		}
+	}
+	entryType := ""
+	if typeLine := findLine(block, "Type"); typeLine != nil {
+		entryType = typeLine.Value()
+	}
+	urlField := ProjectUrlField(entryType)
+	if findLine(block, urlField) == nil {
+		cl.add(block.Lines[0], 0, LintError, fmt.Sprintf("entry has no %s", urlField), fmt.Sprintf("add `%s %s`", urlField, projectUrlExamples[urlField]))
+	}
+	for _, field := range ProjectUrlFields {
+		if line := findLine(block, field); line != nil && field != urlField {
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s uses %s", entryType, urlField), fmt.Sprintf("replace `%s` with `%s`", field, urlField))
+		}
+	}
+	if findLine(block, "Category") == nil && (defaults == nil || defaults.Category == "") {
+		cl.add(findLine(block, "Type"), 0, LintWarning, fmt.Sprintf("entry has no Category, %s will be used", DefaultCategory), "add a `Category` line with the Gentoo category for the package")
+	}
+	if !strings.HasSuffix(entryType, " Go Source Release") {
+		for _, key := range goSourceFields {
+			if line := findLine(block, key); line != nil {
+				cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s is only used by the Go source type", key), "remove it or change the `Type`")
+			}
+		}
+	}
+	if line := findLine(block, "ReleaseUrl"); line != nil && !(&InputConfig{Type: entryType}).UsesReleaseUrl() {
+		cl.add(line, keyColumn(line), LintError, "ReleaseUrl is only used by the binary and AppImage types", "remove it or change the `Type`")
+	}
+	if strings.HasSuffix(entryType, " Source Release") {
+		cl.lintSourceEntry(block, entryType)
+		return
+	}
+	for _, key := range []string{"BuildSystem", "SourceUrl", "Depend", "BDepend"} {
+		if line := findLine(block, key); line != nil {
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("%s is only used by the source types", key), "remove it or change the `Type`")
+		}
+	}
+	if strings.HasSuffix(entryType, " Binary Release") {
+		for _, line := range block.Lines {
+			if line.Key() != "Icons" {
+				continue
+			}
+			for _, icon := range strings.Fields(line.Value()) {
+				if !slices.Contains(PackageIconLocations, icon) {
+					cl.add(line, valueColumn(line), LintError, fmt.Sprintf("unknown icon location %s for a binary", icon), fmt.Sprintf("use one of: %s, where the icons are in the .deb or .rpm", strings.Join(PackageIconLocations, ", ")))
+				}
+			}
+		}
+	}
+	if strings.HasSuffix(entryType, " Llamafile Release") {
+		for _, line := range block.Lines {
+			if line.Key() != "Binary" {
+				continue
+			}
+			if _, files, found := strings.Cut(line.Value(), "=>"); found && len(strings.Split(files, ">")) != 2 {
+				cl.add(line, valueColumn(line), LintError, "a llamafile is installed straight from the release file", "use `Binary amd64=>release-filename > installed-name`")
+			}
+		}
+	}
+	binaries := 0
+	for _, section := range block.ProgramSections() {
+		lines := section.ProgramLines(block)
+		if slices.ContainsFunc(lines, func(line *ConfigLine) bool { return line.Key() == "Binary" }) {
+			binaries++
+			continue
+		}
+		if section.ProgramName != "" {
+			cl.add(block.Lines[section.Start], 0, LintWarning, fmt.Sprintf("program %s has no Binary lines", section.ProgramName), "add `Binary keyword=>release-filename > installed-name` lines or remove the program")
+		}
+	}
+	if binaries == 0 {
+		cl.add(findLine(block, "Type"), 0, LintError, "entry has no Binary lines", "add `Binary keyword=>release-filename > installed-name` lines, `config update` can detect them")
+	}
+}
+
+// lintSourceEntry checks the parts of an entry which are particular to the source types, the build system installs the
+// programs so they have no release files.
+func (cl *configLinter) lintSourceEntry(block *ConfigBlock, entryType string) {
+	switch {
+	case languageSourceEclasses[entryType] != "":
+		if line := findLine(block, "BuildSystem"); line != nil {
+			cl.add(line, keyColumn(line), LintError, fmt.Sprintf("BuildSystem is not used by %s", entryType), fmt.Sprintf("remove it, it is built with the %s eclass", languageSourceEclasses[entryType]))
+		}
+		if strings.HasSuffix(entryType, " Go Source Release") {
+			cl.lintGoDependencies(block)
+		}
+	case findLine(block, "BuildSystem") == nil:
+		cl.add(findLine(block, "Type"), 0, LintError, "entry has no BuildSystem", fmt.Sprintf("add `BuildSystem` with one of: %s", strings.Join(SourceBuildSystems, ", ")))
+	}
+	if line := findLine(block, "SourceUrl"); line != nil && (&InputConfig{SourceUrl: line.Value()}).SourceArchiveExtension() == "" {
+		cl.add(line, valueColumn(line), LintError, fmt.Sprintf("SourceUrl %s isn't a tar archive", line.Value()), fmt.Sprintf("use a URL ending with one of: %s", strings.Join(SourceArchiveExtensions, ", ")))
+	}
+	for _, key := range arrowFieldKeys {
+		if line := findLine(block, key); line != nil {
+			cl.add(line, keyColumn(line), LintWarning, fmt.Sprintf("%s is ignored by the source types", key), "the build system installs the programs, remove the line")
+		}
+	}
+}
+
+// lintGoDependencies checks GoDependencyTarball is set for, and only for, GoDependencies tarball.
+func (cl *configLinter) lintGoDependencies(block *ConfigBlock) {
+	mode := findLine(block, "GoDependencies")
+	tarball := findLine(block, "GoDependencyTarball")
+	switch {
+	case mode != nil && mode.Value() == "tarball" && tarball == nil:
+		cl.add(mode, valueColumn(mode), LintError, "GoDependencies tarball has no GoDependencyTarball", "add `GoDependencyTarball` with the URL of the dependency tarball, ${VERSION} and ${TAG} are replaced")
+	case tarball != nil && (mode == nil || mode.Value() != "tarball"):
+		cl.add(tarball, keyColumn(tarball), LintError, "GoDependencyTarball is only used by GoDependencies tarball", "remove it or add `GoDependencies tarball`")
+	}
+}
+
+// lintArrowLine checks the `keyword=>file > file` form used by Binary, Document, ManualPage and
+// ShellCompletionScript (where the keyword is `keyword:shell`)
+func (cl *configLinter) lintArrowLine(line *ConfigLine, shellKeyword bool) {
+	key := line.Key()
+	value := line.Value()
+	column := valueColumn(line)
+	example := fmt.Sprintf("%s amd64=>release-filename > installed-name", key)
+	if shellKeyword {
+		example = fmt.Sprintf("%s amd64:bash=>release-filename > path/in/archive > installed-name", key)
+	}
+	keyword, files, found := strings.Cut(value, "=>")
+	if !found {
+		cl.add(line, column, LintError, fmt.Sprintf("%s is missing `=>` between the keyword and the filenames", key), fmt.Sprintf("use `%s`", example))
+		return
+	}
+	keyword = strings.TrimSpace(keyword)
+	if shellKeyword {
+		kw, shell, ok := strings.Cut(keyword, ":")
+		if !ok || strings.TrimSpace(kw) == "" || strings.TrimSpace(shell) == "" {
+			cl.add(line, column, LintError, fmt.Sprintf("%s keyword %q must be in the form keyword:shell", key, keyword), fmt.Sprintf("use `%s`", example))
+			return
+		}
+	} else if keyword == "" || strings.ContainsAny(keyword, " \t:") {
+		cl.add(line, column, LintError, fmt.Sprintf("%s keyword %q is not a single Gentoo keyword", key, keyword), fmt.Sprintf("use `%s`", example))
+		return
+	}
+	column += strings.Index(value, "=>") + 2
+	parts := strings.Split(files, ">")
+	offset := 0
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			cl.add(line, column+offset, LintError, fmt.Sprintf("%s filename %d is empty", key, i+1), "remove the extra `>` or fill in the filename")
+			return
+		}
+		offset += len(part) + 1
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		cl.add(line, column, LintError, fmt.Sprintf("%s has %d filenames, expected 2 (release > installed) or 3 (archive > path in archive > installed)", key, len(parts)), fmt.Sprintf("use `%s`", example))
+	}
+}
+
+func findLine(block *ConfigBlock, key string) *ConfigLine {
+	for _, line := range block.Lines {
+		if line.Key() == key {
+			return line
+		}
+	}
+	return nil
+}
+
+func keyColumn(line *ConfigLine) int {
+	return len(line.Text) - len(strings.TrimLeft(line.Text, " \t")) + 1
+}
+
+func valueColumn(line *ConfigLine) int {
+	start := keyColumn(line) - 1 + len(line.Key())
+	rest := line.Text[start:]
+	return start + len(rest) - len(strings.TrimLeft(rest, " \t")) + 1
+}
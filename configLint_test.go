@@ -0,0 +1,214 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestLintConfigDocument(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "Existing test config has no errors",
+			input: testConfigDocumentData,
+			want: []string{
+				"test.config:14:0: warning: entry has no Category, app-misc will be used",
+			},
+		},
+		{
+			name: "Everything wrong",
+			input: `Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Workaround Tag Prefix => g2-
+Workaround Made Up
+Binary amd64 g2_linux_amd64.tar.gz > g2
+Binary arm64=>g2_linux_arm64.tar.gz >  > g2
+ManualPage amd64=>g2.tar.gz
+ShellCompletionScript amd64=>g2.tar.gz > g2.bash > g2
+ProgramName extra
+Dependencies sys-libs/glibc
+Frobnicate yes
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+EbuildName g2
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+EbuildName g2-bin
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+
+Category app-misc
+`,
+			want: []string{
+				"test.config:5:12: error: unknown workaround: Made Up",
+				"test.config:6:8: error: Binary is missing `=>` between the keyword and the filenames",
+				"test.config:7:38: error: Binary filename 2 is empty",
+				"test.config:8:19: error: ManualPage has 1 filenames, expected 2 (release > installed) or 3 (archive > path in archive > installed)",
+				"test.config:9:23: error: ShellCompletionScript keyword \"amd64\" must be in the form keyword:shell",
+				"test.config:10:0: warning: program extra has no Binary lines",
+				"test.config:12:1: error: unknown field Frobnicate",
+				"test.config:14:0: warning: entry has no Category, app-misc will be used",
+				"test.config:22:0: error: duplicate EbuildName g2-bin.ebuild, first used on line 16",
+				"test.config:25:1: error: Category is outside of an entry",
+			},
+		},
+		{
+			name: "Missing binaries and url",
+			input: `Type Github Binary Release
+Category dev-util
+`,
+			want: []string{
+				"test.config:1:0: error: entry has no GithubProjectUrl",
+				"test.config:1:0: error: entry has no Binary lines",
+			},
+		},
+		{
+			name: "GitLab urls",
+			input: `Type GitLab Binary Release
+GithubProjectUrl https://github.com/cli/cli
+Category dev-util
+Binary amd64=>glab_linux_amd64.tar.gz > glab
+
+Type GitLab Binary Release
+GitlabProjectUrl gitlab.com/gitlab-org/cli
+Category dev-util
+Binary amd64=>glab_linux_amd64.tar.gz > glab
+`,
+			want: []string{
+				"test.config:1:0: error: entry has no GitlabProjectUrl",
+				"test.config:2:1: error: GitLab Binary Release uses GitlabProjectUrl",
+				"test.config:7:18: error: not a valid GitLab URL: gitlab.com/gitlab-org/cli",
+			},
+		},
+		{
+			name: "Gitea urls",
+			input: `Type Gitea Binary Release
+GitlabProjectUrl https://gitlab.com/forgejo/runner
+GiteaProjectUrl codeberg.org/forgejo/runner
+Category dev-util
+Binary amd64=>runner_linux_amd64.tar.gz > runner
+`,
+			want: []string{
+				"test.config:2:1: error: Gitea Binary Release uses GiteaProjectUrl",
+				"test.config:3:17: error: not a valid Gitea URL: codeberg.org/forgejo/runner",
+			},
+		},
+		{
+			name: "Llamafile releases",
+			input: `Type Github Llamafile Release
+GithubProjectUrl https://github.com/Mozilla-Ocho/llamafile
+Category dev-ml
+Binary amd64=>llamafile-${VERSION} > llamafile
+Binary arm64=>llamafile-${VERSION}.zip > bin/llamafile > llamafile
+`,
+			want: []string{
+				"test.config:5:8: error: a llamafile is installed straight from the release file",
+			},
+		},
+		{
+			name: "Tags without releases",
+			input: `Type Github Binary Release
+GithubProjectUrl https://github.com/example/tagged
+Category dev-util
+Workaround Tags Without Releases
+Binary amd64=>tagged.tar.gz > tagged
+
+Type Github Source Release
+GithubProjectUrl https://github.com/example/tagged
+Category dev-util
+EbuildName tagged-source
+BuildSystem make
+ReleaseUrl https://downloads.example.org/${TAG}
+`,
+			want: []string{
+				"test.config:1:0: error: workaround Tags Without Releases has no releases to download the release files from",
+				"test.config:12:1: error: ReleaseUrl is only used by the binary and AppImage types",
+			},
+		},
+		{
+			name: "Source releases",
+			input: `Type Github Source Release
+GithubProjectUrl https://github.com/lojban/jbofihe
+Category app-text
+BuildSystem scons
+Binary amd64=>jbofihe > jbofihe
+
+Type Github Source Release
+GithubProjectUrl https://github.com/lojban/jbofihe
+Category app-text
+EbuildName jbofihe-doc
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Depend virtual/libc
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`,
+			want: []string{
+				"test.config:4:13: error: unknown build system scons",
+				"test.config:5:1: warning: Binary is ignored by the source types",
+				"test.config:7:0: error: entry has no BuildSystem",
+				"test.config:15:1: error: Depend is only used by the source types",
+			},
+		},
+		{
+			name: "Go and Rust source releases",
+			input: `Type Github Go Source Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+BuildSystem make
+GoDependencies tarball
+
+Type Github Go Source Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+GoDependencies modcache
+GoDependencyTarball https://example.org/g2-deps.tar.xz
+
+Type Github Source Release
+GithubProjectUrl https://github.com/lojban/jbofihe
+Category app-text
+BuildSystem autotools
+GoPackages ./cmd/jbofihe
+
+Type Github Rust Source Release
+GithubProjectUrl https://github.com/example/tool
+Category app-misc
+BuildSystem cmake
+GoLdflags -X main.version=${VERSION}
+`,
+			want: []string{
+				"test.config:4:1: error: BuildSystem is not used by Github Go Source Release",
+				"test.config:5:16: error: GoDependencies tarball has no GoDependencyTarball",
+				"test.config:10:16: error: unknown Go dependency mode modcache",
+				"test.config:11:1: error: GoDependencyTarball is only used by GoDependencies tarball",
+				"test.config:17:1: error: GoPackages is only used by the Go source type",
+				"test.config:22:1: error: BuildSystem is not used by Github Rust Source Release",
+				"test.config:23:1: error: GoLdflags is only used by the Go source type",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := ParseConfigDocument(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("ParseConfigDocument() error = %v", err)
+			}
+			doc.Filename = "test.config"
+			var got []string
+			for _, diagnostic := range LintConfigDocument(doc) {
+				got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// CurrentConfigVersion is the `ConfigVersion` of the format this version reads and writes, it is the Version of the
+// last migration. Files without a `ConfigVersion` line are version 1.
+const CurrentConfigVersion = 2
+
+// ConfigMigration updates an entry written for the previous version to the format of Version.
+type ConfigMigration struct {
+	Version     int
+	Description string
+	// Migrate changes the entry in place and returns if it changed anything. Lines which are kept should be changed
+	// rather than replaced so they keep their line numbers for error messages.
+	Migrate func(block *ConfigBlock) (bool, error)
+}
+
+// ConfigMigrations are applied in order to the entries of files with an older `ConfigVersion` when they are read, and
+// by `config migrate` to rewrite them. A change to the format which would break existing files adds a migration here
+// and increases CurrentConfigVersion.
+var ConfigMigrations = []*ConfigMigration{
+	{
+		Version:     2,
+		Description: "`Type Github AppImage` and `Type Github Binary` gain `Release`, and `InstalledFilename` with `ReleasesFilename keyword=>release` becomes `Binary keyword=>release > installed`",
+		Migrate:     migrateReleasesFilename,
+	},
+}
+
+// ConfigVersion returns the version in the `ConfigVersion` line of the document and the line, or 1 and nil if there
+// isn't one. The line is also returned with an error.
+func (cd *ConfigDocument) ConfigVersion() (int, *ConfigLine, error) {
+	for _, block := range cd.Blocks {
+		for _, line := range block.Lines {
+			if line.Key() != "ConfigVersion" {
+				continue
+			}
+			version, err := ParseConfigVersion(line.Value())
+			if err != nil {
+				return 0, line, err
+			}
+			return version, line, nil
+		}
+	}
+	return 1, nil, nil
+}
+
+// ParseConfigVersion parses the value of a `ConfigVersion` line, versions newer than this version supports are an
+// error.
+func ParseConfigVersion(value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("ConfigVersion %q isn't a version number", value)
+	}
+	if version > CurrentConfigVersion {
+		return 0, fmt.Errorf("ConfigVersion %d is newer than this version supports (%d), upgrade arrans_overlay_workflow_builder", version, CurrentConfigVersion)
+	}
+	return version, nil
+}
+
+// MigrateConfigBlock applies the migrations after fromVersion to the entry and returns if it changed.
+func MigrateConfigBlock(block *ConfigBlock, fromVersion int) (bool, error) {
+	changed := false
+	for _, migration := range ConfigMigrations {
+		if migration.Version <= fromVersion {
+			continue
+		}
+		migrated, err := migration.Migrate(block)
+		if err != nil {
+			return false, fmt.Errorf("migrating to ConfigVersion %d: %w", migration.Version, err)
+		}
+		changed = changed || migrated
+	}
+	return changed, nil
+}
+
+// MigrateConfigDocument updates every entry to the current format and returns how many changed, it doesn't change the
+// `ConfigVersion` line.
+func MigrateConfigDocument(doc *ConfigDocument) (int, error) {
+	version, line, err := doc.ConfigVersion()
+	if err != nil {
+		return 0, fmt.Errorf("line %d: %w", line.Number, err)
+	}
+	if version >= CurrentConfigVersion {
+		return 0, nil
+	}
+	changed := 0
+	for _, block := range doc.Blocks {
+		if !block.IsEntry() {
+			continue
+		}
+		migrated, err := MigrateConfigBlock(block, version)
+		if err != nil {
+			return 0, fmt.Errorf("entry starting on line %d: %w", block.FirstLineNumber(), err)
+		}
+		if migrated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// SetConfigVersion changes the `ConfigVersion` line, or adds one in a block of its own at the start of the document.
+func (cd *ConfigDocument) SetConfigVersion(version int) {
+	text := fmt.Sprintf("ConfigVersion %d", version)
+	if _, line, _ := cd.ConfigVersion(); line != nil {
+		line.Text = text
+		return
+	}
+	block := NewConfigBlock(text)
+	if len(cd.Blocks) > 0 {
+		block.Separator = cd.Blocks[0].Separator
+		cd.Blocks[0].Separator = []*ConfigLine{{}}
+	}
+	cd.Blocks = append([]*ConfigBlock{block}, cd.Blocks...)
+	cd.NoFinalNewline = cd.NoFinalNewline && len(cd.Blocks) > 1
+}
+
+// ConfigMigrate rewrites the configuration file and the files it includes in the current format and sets their
+// `ConfigVersion`.
+func ConfigMigrate(configFile string, dryRun bool) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if len(doc.Blocks) == 0 {
+			continue
+		}
+		before := doc.String()
+		changed, err := MigrateConfigDocument(doc)
+		if err != nil {
+			return fmt.Errorf("%s: %w", doc.Filename, err)
+		}
+		doc.SetConfigVersion(CurrentConfigVersion)
+		if before == doc.String() {
+			log.Printf("%s is already ConfigVersion %d", doc.Filename, CurrentConfigVersion)
+			continue
+		}
+		if err := saveConfigDocumentChanges(doc, before, nil, dryRun); err != nil {
+			return err
+		}
+		if _, err := doc.InputConfigs(); err != nil {
+			return fmt.Errorf("migrated file doesn't parse: %w", err)
+		}
+		log.Printf("Migrated %d entries in %s to ConfigVersion %d", changed, doc.Filename, CurrentConfigVersion)
+	}
+	return nil
+}
+
+// legacyTypes are the `Type` values used before `Release` was added to them.
+var legacyTypes = map[string]string{
+	"Github AppImage": "Github AppImage Release",
+	"Github Binary":   "Github Binary Release",
+}
+
+// migrateReleasesFilename rewrites the ConfigVersion 1 form where each program had one `InstalledFilename` and a
+// `ReleasesFilename keyword=>release` per keyword.
+func migrateReleasesFilename(block *ConfigBlock) (bool, error) {
+	changed := false
+	for _, line := range block.Lines {
+		if line.Key() != "Type" {
+			continue
+		}
+		if current, ok := legacyTypes[line.Value()]; ok {
+			line.Text = strings.Replace(line.Text, line.Value(), current, 1)
+			changed = true
+		}
+	}
+	for _, section := range block.ProgramSections() {
+		var installed *ConfigLine
+		var releases []*ConfigLine
+		for _, line := range block.Lines[section.Start:section.End] {
+			switch line.Key() {
+			case "InstalledFilename":
+				installed = line
+			case "ReleasesFilename":
+				releases = append(releases, line)
+			}
+		}
+		switch {
+		case installed == nil && len(releases) == 0:
+			continue
+		case installed == nil:
+			return false, fmt.Errorf("ReleasesFilename on line %d has no InstalledFilename", releases[0].Number)
+		case len(releases) == 0:
+			return false, fmt.Errorf("InstalledFilename on line %d has no ReleasesFilename", installed.Number)
+		}
+		for _, line := range releases {
+			keyword, release, found := strings.Cut(line.Value(), "=>")
+			if !found {
+				return false, fmt.Errorf("ReleasesFilename on line %d is missing `=>` between the keyword and the filename", line.Number)
+			}
+			line.Text = fmt.Sprintf("Binary %s=>%s > %s", strings.TrimSpace(keyword), strings.TrimSpace(release), installed.Value())
+		}
+		changed = true
+	}
+	if block.RemoveKey("InstalledFilename") > 0 {
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,226 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLegacyConfigData = `# Tools from go-appimage
+Type Github AppImage
+GithubProjectUrl https://github.com/probonopd/go-appimage
+Category app-misc
+ProgramName appimaged
+DesktopFile appimaged.desktop
+InstalledFilename appimaged.AppImage
+ReleasesFilename amd64=>appimaged-x86_64.AppImage
+ReleasesFilename arm64 => appimaged-aarch64.AppImage
+ProgramName appimagetool
+InstalledFilename appimagetool.AppImage
+ReleasesFilename amd64=>appimagetool-x86_64.AppImage
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`
+
+const testMigratedConfigData = `ConfigVersion 2
+
+# Tools from go-appimage
+Type Github AppImage Release
+GithubProjectUrl https://github.com/probonopd/go-appimage
+Category app-misc
+ProgramName appimaged
+DesktopFile appimaged.desktop
+Binary amd64=>appimaged-x86_64.AppImage > appimaged.AppImage
+Binary arm64=>appimaged-aarch64.AppImage > appimaged.AppImage
+ProgramName appimagetool
+Binary amd64=>appimagetool-x86_64.AppImage > appimagetool.AppImage
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`
+
+func TestCurrentConfigVersion(t *testing.T) {
+	if last := ConfigMigrations[len(ConfigMigrations)-1].Version; last != CurrentConfigVersion {
+		t.Errorf("CurrentConfigVersion = %d, want the version of the last migration %d", CurrentConfigVersion, last)
+	}
+}
+
+func TestParseLegacyConfig(t *testing.T) {
+	legacy, err := ParseInputConfigReader(strings.NewReader(testLegacyConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() legacy error = %v", err)
+	}
+	migrated, err := ParseInputConfigReader(strings.NewReader(testMigratedConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() migrated error = %v", err)
+	}
+	if diff := cmp.Diff(migrated, legacy); diff != "" {
+		t.Errorf("ParseInputConfigReader() legacy mismatch (-want +got):\n%s", diff)
+	}
+	doc, err := ParseConfigDocument(strings.NewReader(testLegacyConfigData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	docIcs, err := doc.InputConfigs()
+	if err != nil {
+		t.Fatalf("InputConfigs() error = %v", err)
+	}
+	for _, ic := range docIcs {
+		ic.SourceLine = 0
+	}
+	if diff := cmp.Diff(migrated, docIcs); diff != "" {
+		t.Errorf("ConfigDocument.InputConfigs() legacy mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseConfigVersionErrors(t *testing.T) {
+	entry := "Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nBinary amd64=>g2_linux_amd64.tar.gz > g2\n"
+	for _, test := range []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Newer version",
+			input:   "ConfigVersion 99\n\n" + entry,
+			wantErr: "line 1: ConfigVersion 99 is newer than this version supports",
+		},
+		{
+			name:    "Not a number",
+			input:   "ConfigVersion two\n\n" + entry,
+			wantErr: `line 1: ConfigVersion "two" isn't a version number`,
+		},
+		{
+			name:    "After an entry",
+			input:   entry + "\nConfigVersion 2\n",
+			wantErr: "line 5: ConfigVersion must come before the first entry, Defaults or Include",
+		},
+		{
+			name:    "No blank line after it",
+			input:   "ConfigVersion 2\n" + entry,
+			wantErr: "line 2: ConfigVersion must be separated from the rest of the file by a blank line",
+		},
+		{
+			name:    "Legacy fields in the current version",
+			input:   "ConfigVersion 2\n\nType Github AppImage Release\nGithubProjectUrl https://github.com/arran4/g2\nInstalledFilename g2\nReleasesFilename amd64=>g2\n",
+			wantErr: "line 5: invalid line: InstalledFilename g2",
+		},
+		{
+			name:    "ReleasesFilename without InstalledFilename",
+			input:   "Type Github AppImage\nGithubProjectUrl https://github.com/arran4/g2\nReleasesFilename amd64=>g2\n",
+			wantErr: "line 1: migrating to ConfigVersion 2: ReleasesFilename on line 3 has no InstalledFilename",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseInputConfigReader(strings.NewReader(test.input))
+			if err == nil {
+				t.Fatalf("ParseInputConfigReader() expected an error")
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ParseInputConfigReader() error = %s, want it to contain %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	dir := writeTestConfigTree(t, map[string]string{
+		"input.config": testLegacyConfigData + "\nInclude current.config\n",
+		"current.config": "ConfigVersion 2\n\nType Github Binary Release\nGithubProjectUrl https://github.com/arran4/dotfiles\n" +
+			"Binary amd64=>dotfiles_linux_amd64.tar.gz > dotfiles\n",
+	})
+	fn := filepath.Join(dir, "input.config")
+	if err := ConfigMigrate(fn, true); err != nil {
+		t.Fatalf("ConfigMigrate() dry run error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testLegacyConfigData+"\nInclude current.config\n", string(b)); diff != "" {
+		t.Errorf("ConfigMigrate() dry run changed the file (-want +got):\n%s", diff)
+	}
+	if err := ConfigMigrate(fn, false); err != nil {
+		t.Fatalf("ConfigMigrate() error = %v", err)
+	}
+	b, err = os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testMigratedConfigData+"\nInclude current.config\n", string(b)); diff != "" {
+		t.Errorf("ConfigMigrate() mismatch (-want +got):\n%s", diff)
+	}
+	if err := ConfigMigrate(fn, false); err != nil {
+		t.Fatalf("ConfigMigrate() second run error = %v", err)
+	}
+	again, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(b), string(again)); diff != "" {
+		t.Errorf("ConfigMigrate() second run changed the file (-want +got):\n%s", diff)
+	}
+}
+
+func TestAppendToNewConfigurationFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "input.config")
+	ic := &InputConfig{
+		Type:             "Github Binary Release",
+		GithubProjectUrl: "https://github.com/twpayne/chezmoi",
+	}
+	if err := AppendToConfigurationFile(fn, ic); err != nil {
+		t.Fatalf("AppendToConfigurationFile() error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ConfigVersion 2\n\nType Github Binary Release\nGithubProjectUrl https://github.com/twpayne/chezmoi\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("new file mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLintLegacyConfig(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(testLegacyConfigData))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	doc.Filename = "test.config"
+	var got []string
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	want := []string{
+		"test.config:2:0: warning: entry is in the ConfigVersion 1 format",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+	doc.SetConfigVersion(CurrentConfigVersion)
+	got = nil
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	want = []string{
+		"test.config:2:0: error: entry has no Binary lines",
+		"test.config:2:6: error: unknown type Github AppImage",
+		"test.config:5:0: warning: program appimaged has no Binary lines",
+		"test.config:7:1: error: unknown field InstalledFilename",
+		"test.config:8:1: error: unknown field ReleasesFilename",
+		"test.config:9:1: error: unknown field ReleasesFilename",
+		"test.config:10:0: warning: program appimagetool has no Binary lines",
+		"test.config:11:1: error: unknown field InstalledFilename",
+		"test.config:12:1: error: unknown field ReleasesFilename",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocument() with ConfigVersion %d mismatch (-want +got):\n%s", CurrentConfigVersion, diff)
+	}
+}
@@ -0,0 +1,168 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// ConfigQueryFormats are the formats `config list` can write the matching entries in.
+var ConfigQueryFormats = []string{
+	"table",
+	"json",
+	"names",
+}
+
+// ConfigQuery selects entries of a configuration file, empty fields match every entry.
+type ConfigQuery struct {
+	Type     string
+	Category string
+	// Name is a glob such as `*-appimage` matched against the ebuild name without `.ebuild`.
+	Name       string
+	Workaround string
+	// Dependency is a package such as `x11-libs/gtk+` which one of the entry's programs depends on, with or without a
+	// version.
+	Dependency string
+}
+
+// Matches is true if the entry matches every set field of the query.
+func (cq *ConfigQuery) Matches(ic *InputConfig) (bool, error) {
+	if cq.Type != "" && ic.Type != cq.Type {
+		return false, nil
+	}
+	if cq.Category != "" && ic.Category != cq.Category {
+		return false, nil
+	}
+	if cq.Name != "" {
+		matched, err := path.Match(cq.Name, strings.TrimSuffix(ic.EbuildName, ".ebuild"))
+		if err != nil {
+			return false, fmt.Errorf("name pattern %s: %w", cq.Name, err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if cq.Workaround != "" {
+		if _, ok := ic.Workarounds[cq.Workaround]; !ok {
+			return false, nil
+		}
+	}
+	if cq.Dependency != "" && len(cq.DependentPrograms(ic)) == 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// DependentPrograms returns the names of the entry's programs which depend on the query's Dependency.
+func (cq *ConfigQuery) DependentPrograms(ic *InputConfig) []string {
+	var result []string
+	for _, programName := range ic.ProgramsString() {
+		if slices.ContainsFunc(ic.Programs[programName].Dependencies, func(dependency string) bool {
+			return dependencyIsPackage(dependency, cq.Dependency)
+		}) {
+			result = append(result, programName)
+		}
+	}
+	return result
+}
+
+// dependencyIsPackage is true if the dependency atom, such as `>=x11-libs/gtk+-3.24:3`, is for the package.
+func dependencyIsPackage(dependency, pkg string) bool {
+	atom := strings.TrimLeft(dependency, "!<>=~")
+	if !strings.HasPrefix(atom, pkg) {
+		return false
+	}
+	rest := strings.TrimPrefix(atom, pkg)
+	return rest == "" || strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "[") ||
+		(strings.HasPrefix(rest, "-") && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9')
+}
+
+// QueryInputConfigs returns the entries which match the query.
+func QueryInputConfigs(ics []*InputConfig, query *ConfigQuery) ([]*InputConfig, error) {
+	var result []*InputConfig
+	for _, ic := range ics {
+		matched, err := query.Matches(ic)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			result = append(result, ic)
+		}
+	}
+	return result, nil
+}
+
+// ConfigList writes the entries of the configuration file, and the files it includes, which match the query to stdout
+// in the format.
+func ConfigList(configFile string, query *ConfigQuery, format string) error {
+	ics, err := ReadConfigurationFile(configFile)
+	if err != nil {
+		return fmt.Errorf("reading configuration file: %s: %w", configFile, err)
+	}
+	matched, err := QueryInputConfigs(ics, query)
+	if err != nil {
+		return err
+	}
+	return WriteConfigList(os.Stdout, matched, query, format)
+}
+
+// WriteConfigList writes the entries in the format. The table has a line per entry, json is the same document as
+// `config export` and names is the ebuild name of each entry on a line of its own.
+func WriteConfigList(w io.Writer, ics []*InputConfig, query *ConfigQuery, format string) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tEBUILD\tTYPE\tPROGRAMS\tLOCATION")
+		for _, ic := range ics {
+			id := "-"
+			if ic.EntryNumber != 0 {
+				id = fmt.Sprint(ic.EntryNumber)
+			}
+			programs := ic.ProgramsString()
+			if query != nil && query.Dependency != "" {
+				programs = query.DependentPrograms(ic)
+			}
+			location := "-"
+			if ic.SourceFile != "" {
+				location = fmt.Sprintf("%s:%d", ic.SourceFile, ic.SourceLine)
+			}
+			fmt.Fprintf(tw, "%s\t%s/%s\t%s\t%s\t%s\n", id, ic.Category, strings.TrimSuffix(ic.EbuildName, ".ebuild"), ic.Type, listProgramNames(programs), location)
+		}
+		return tw.Flush()
+	case "json":
+		b, err := MarshalConfigExport(&ConfigExport{Entries: ics}, "json")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "names":
+		for _, ic := range ics {
+			if _, err := fmt.Fprintln(w, strings.TrimSuffix(ic.EbuildName, ".ebuild")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, use one of: %s", format, strings.Join(ConfigQueryFormats, ", "))
+	}
+}
+
+// listProgramNames joins the program names for the table, the unnamed program is shown as `(unnamed)`.
+func listProgramNames(programs []string) string {
+	names := make([]string, 0, len(programs))
+	for _, programName := range programs {
+		if programName == "" {
+			programName = "(unnamed)"
+		}
+		names = append(names, programName)
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,115 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+const testConfigQueryData = `Type Github Binary Release
+Id 2
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Workaround Tag Prefix => v
+Dependencies >=x11-libs/gtk+-3.24:3
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+ProgramName extra
+Dependencies x11-libs/gtk+extra
+Binary arm64=>g2_linux_arm64.tar.gz > g2
+
+Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Category app-admin
+Binary amd64=>chezmoi_${VERSION}_linux_amd64.tar.gz > chezmoi > chezmoi
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan
+Category dev-util
+Dependencies x11-libs/gtk+
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`
+
+func TestQueryInputConfigs(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testConfigQueryData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	for _, test := range []struct {
+		name  string
+		query *ConfigQuery
+		want  []string
+	}{
+		{name: "Everything", query: &ConfigQuery{}, want: []string{"g2-bin.ebuild", "chezmoi-bin.ebuild", "jan-appimage.ebuild"}},
+		{name: "Type and category", query: &ConfigQuery{Type: "Github Binary Release", Category: "dev-util"}, want: []string{"g2-bin.ebuild"}},
+		{name: "Workaround", query: &ConfigQuery{Workaround: "Tag Prefix"}, want: []string{"g2-bin.ebuild"}},
+		{name: "Dependency", query: &ConfigQuery{Dependency: "x11-libs/gtk+"}, want: []string{"g2-bin.ebuild", "jan-appimage.ebuild"}},
+		{name: "Dependency isn't a prefix match", query: &ConfigQuery{Dependency: "x11-libs/gtk"}, want: nil},
+		{name: "Name", query: &ConfigQuery{Name: "*-appimage"}, want: []string{"jan-appimage.ebuild"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			matched, err := QueryInputConfigs(ics, test.query)
+			if err != nil {
+				t.Fatalf("QueryInputConfigs() error = %v", err)
+			}
+			var got []string
+			for _, ic := range matched {
+				got = append(got, ic.EbuildName)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("QueryInputConfigs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := QueryInputConfigs(ics, &ConfigQuery{Name: "["}); err == nil {
+		t.Errorf("QueryInputConfigs() with a bad pattern should fail")
+	}
+}
+
+func TestWriteConfigList(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testConfigQueryData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	ics[0].SourceFile, ics[0].SourceLine = "input.config", 1
+	query := &ConfigQuery{Dependency: "x11-libs/gtk+"}
+	matched, err := QueryInputConfigs(ics, query)
+	if err != nil {
+		t.Fatalf("QueryInputConfigs() error = %v", err)
+	}
+	for _, test := range []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "table",
+			want: `ID  EBUILD                 TYPE                     PROGRAMS   LOCATION
+2   dev-util/g2-bin        Github Binary Release    (unnamed)  input.config:1
+-   dev-util/jan-appimage  Github AppImage Release  (unnamed)  -
+`,
+		},
+		{format: "names", want: "g2-bin\njan-appimage\n"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteConfigList(&buf, matched, query, test.format); err != nil {
+			t.Fatalf("WriteConfigList(%s) error = %v", test.format, err)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("WriteConfigList(%s) mismatch (-want +got):\n%s", test.format, diff)
+		}
+	}
+	var buf bytes.Buffer
+	if err := WriteConfigList(&buf, matched, query, "json"); err != nil {
+		t.Fatalf("WriteConfigList(json) error = %v", err)
+	}
+	export, err := UnmarshalConfigExport(buf.Bytes(), "json")
+	if err != nil {
+		t.Fatalf("UnmarshalConfigExport() error = %v", err)
+	}
+	if len(export.Entries) != 2 || export.Entries[1].EbuildName != "jan-appimage.ebuild" {
+		t.Errorf("WriteConfigList(json) entries = %v", export.Entries)
+	}
+	if err := WriteConfigList(&buf, matched, query, "xml"); err == nil {
+		t.Errorf("WriteConfigList() with an unknown format should fail")
+	}
+}
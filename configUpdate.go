@@ -0,0 +1,192 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+var (
+	// detectedFieldKeys are the program fields `config update` replaces with what was detected in the latest release,
+	// everything else in the entry is left for a human to maintain.
+	detectedFieldKeys = []string{
+		"Document",
+		"ManualPage",
+		"ShellCompletionScript",
+		"Binary",
+	}
+	// ConfigEntryGenerators detect a configuration entry from a release for each `Type`.
+	ConfigEntryGenerators = map[string]func(gitRepo, tagOverride, tagPrefix string) (*InputConfig, error){
+		"Github AppImage Release":    GenerateAppImageGithubReleaseConfigEntry,
+		"Github Binary Release":      GenerateBinaryGithubReleaseConfigEntry,
+		"GitLab AppImage Release":    GenerateAppImageGitlabReleaseConfigEntry,
+		"GitLab Binary Release":      GenerateBinaryGitlabReleaseConfigEntry,
+		"Gitea AppImage Release":     GenerateAppImageGiteaReleaseConfigEntry,
+		"Gitea Binary Release":       GenerateBinaryGiteaReleaseConfigEntry,
+		"Github Source Release":      GenerateSourceGithubReleaseConfigEntry,
+		"Github Go Source Release":   GenerateGoSourceGithubReleaseConfigEntry,
+		"Github Rust Source Release": GenerateRustSourceGithubReleaseConfigEntry,
+		"Github Llamafile Release":   GenerateLlamafileGithubReleaseConfigEntry,
+	}
+)
+
+// ConfigUpdate re-detects the release files of the selected entry and merges them into the entry, the changes are
+// shown as a diff and only written if dryRun is false.
+func ConfigUpdate(configFile string, selector *EntrySelector, dryRun bool) error {
+	docs, err := LoadConfigDocumentTree(configFile)
+	if err != nil {
+		return err
+	}
+	doc, block, ic, err := docs.FindEntry(selector)
+	if err != nil {
+		return err
+	}
+	generator, ok := ConfigEntryGenerators[ic.Type]
+	if !ok {
+		return fmt.Errorf("can't update entry of type %s", ic.Type)
+	}
+	detected, err := generator(ic.ProjectUrl(), "", ic.WorkaroundTagPrefix())
+	if err != nil {
+		return fmt.Errorf("detecting %s: %w", ic.ProjectUrl(), err)
+	}
+	before := doc.String()
+	MergeDetectedConfigEntry(block, detected)
+	if before == doc.String() {
+		log.Printf("%s is up to date", ic.EbuildName)
+		return nil
+	}
+	if err := saveConfigDocumentChanges(doc, before, block, dryRun); err != nil {
+		return err
+	}
+	log.Printf("Updated %s in %s", ic.EbuildName, doc.Filename)
+	return nil
+}
+
+// MergeDetectedConfigEntry replaces the Binary, Document, ManualPage and ShellCompletionScript lines of each program
+// in the entry with the detected ones. Programs which weren't in the entry are added to the end and programs which
+// weren't detected are left alone. If both have a single program they are treated as the same program even when the
+// names differ. Detected workarounds the entry doesn't have are added, existing ones are kept as is.
+func MergeDetectedConfigEntry(block *ConfigBlock, detected *InputConfig) {
+	sections := block.ProgramSections()
+	var existing []*ConfigProgramSection
+	for _, section := range sections {
+		if section.ProgramName != "" || len(section.ProgramLines(block)) > 0 {
+			existing = append(existing, section)
+		}
+	}
+	matched := map[*ConfigProgramSection]*Program{}
+	var added []*Program
+	for _, programName := range detected.ProgramsString() {
+		program := detected.Programs[programName]
+		i := slices.IndexFunc(existing, func(section *ConfigProgramSection) bool {
+			return section.ProgramName == program.ProgramName
+		})
+		switch {
+		case i >= 0:
+			matched[existing[i]] = program
+		case len(existing) == 1 && len(detected.Programs) == 1:
+			matched[existing[0]] = program
+		case program.ProgramName == "":
+			// The unnamed program can't be appended as its lines would belong to the last program.
+			matched[sections[0]] = program
+		default:
+			added = append(added, program)
+		}
+	}
+	for _, section := range existing {
+		if _, ok := matched[section]; !ok {
+			log.Printf("Program %q wasn't detected in the release, leaving it as is", section.ProgramName)
+		}
+	}
+	// Work backwards so the indexes of the earlier sections stay valid.
+	for i := len(sections) - 1; i >= 0; i-- {
+		section := sections[i]
+		program, ok := matched[section]
+		if !ok {
+			continue
+		}
+		position := -1
+		var kept []*ConfigLine
+		for _, line := range block.Lines[section.Start:section.End] {
+			if slices.Contains(detectedFieldKeys, line.Key()) {
+				if position < 0 {
+					position = section.Start + len(kept)
+				}
+				continue
+			}
+			kept = append(kept, line)
+		}
+		if position < 0 {
+			position = section.Start + len(kept)
+		}
+		block.Lines = slices.Replace(block.Lines, section.Start, section.End, kept...)
+		block.InsertLines(position, detectedProgramLines(program)...)
+	}
+	for _, program := range added {
+		block.Lines = append(block.Lines, NewConfigBlock(program.String()).Lines...)
+	}
+	for _, workaround := range detected.WorkaroundString() {
+		if _, ok := block.Workaround(workaround); !ok {
+			block.SetWorkaround(workaround, detected.Workarounds[workaround])
+		}
+	}
+}
+
+func detectedProgramLines(program *Program) []*ConfigLine {
+	var result []*ConfigLine
+	for _, line := range NewConfigBlock(program.String()).Lines {
+		if slices.Contains(detectedFieldKeys, line.Key()) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// forgeEntryGenerator returns the generator of the type, the types of a forge all start with its name.
+func forgeEntryGenerator(forge, sourceType string) (func(gitRepo, tagOverride, tagPrefix string) (*InputConfig, error), error) {
+	generator, ok := ConfigEntryGenerators[sourceType]
+	if !ok || !strings.HasPrefix(sourceType, forge+" ") {
+		return nil, fmt.Errorf("unknown %s type %s", forge, sourceType)
+	}
+	return generator, nil
+}
+
+func configAddForgeReleases(forge, toConfig, sourceType, projectUrl, tagOverride, tagPrefix string) error {
+	generator, err := forgeEntryGenerator(forge, sourceType)
+	if err != nil {
+		return err
+	}
+	ic, err := generator(projectUrl, tagOverride, tagPrefix)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reading config")
+	config, err := ReadConfigurationFile(toConfig)
+	if err != nil {
+		return fmt.Errorf("reading configuration file: %s: %w", toConfig, err)
+	}
+
+	ic.EntryNumber = NextEntryId(config)
+
+	log.Printf("Appending to config as entry id: %d", ic.EntryNumber)
+	if err := AppendToConfigurationFile(toConfig, ic); err != nil {
+		return fmt.Errorf("appending to configuration file: %s: %w", toConfig, err)
+	}
+	return nil
+}
+
+func configViewForgeReleases(forge, sourceType, projectUrl, tagOverride, tagPrefix string) error {
+	generator, err := forgeEntryGenerator(forge, sourceType)
+	if err != nil {
+		return err
+	}
+	ic, err := generator(projectUrl, tagOverride, tagPrefix)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", ic.String())
+	return nil
+}
@@ -0,0 +1,192 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeDetectedConfigEntry(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		detected *InputConfig
+		want     string
+	}{
+		{
+			name: "Curated fields and comments are kept",
+			input: `Type Github Binary Release
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+# Keep this in dev-go
+Category dev-go
+EbuildName goreleaser-bin
+Description Curated description
+ProgramName goreleaser
+Dependencies sys-libs/glibc
+# amd64 only for now
+Binary amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+ManualPage amd64=>goreleaser_Linux_x86_64.tar.gz > manpages/goreleaser.1.gz > goreleaser.1
+`,
+			detected: &InputConfig{
+				Type:        "Github Binary Release",
+				Description: "Detected description",
+				Workarounds: map[string]string{"Semantic Version Prerelease Hack 1": ""},
+				Programs: map[string]*Program{
+					"goreleaser": {
+						ProgramName:  "goreleaser",
+						Dependencies: []string{"sys-libs/musl"},
+						Binary: map[string][]string{
+							"amd64": {"goreleaser_Linux_x86_64.tar.gz", "goreleaser", "goreleaser"},
+							"arm64": {"goreleaser_Linux_arm64.tar.gz", "goreleaser", "goreleaser"},
+						},
+						ShellCompletionScripts: map[string]map[string][]string{
+							"amd64": {"bash": {"goreleaser_Linux_x86_64.tar.gz", "completions/goreleaser.bash", "goreleaser"}},
+						},
+					},
+				},
+			},
+			want: `Type Github Binary Release
+GithubProjectUrl https://github.com/goreleaser/goreleaser
+# Keep this in dev-go
+Category dev-go
+EbuildName goreleaser-bin
+Description Curated description
+Workaround Semantic Version Prerelease Hack 1
+ProgramName goreleaser
+Dependencies sys-libs/glibc
+# amd64 only for now
+ShellCompletionScript amd64:bash=>goreleaser_Linux_x86_64.tar.gz > completions/goreleaser.bash > goreleaser
+Binary amd64=>goreleaser_Linux_x86_64.tar.gz > goreleaser > goreleaser
+Binary arm64=>goreleaser_Linux_arm64.tar.gz > goreleaser > goreleaser
+`,
+		},
+		{
+			name: "Single programs are matched even if renamed",
+			input: `Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan/
+DesktopFile jan.desktop
+Binary amd64=>jan-linux-x86_64-0.4.0.AppImage > jan
+`,
+			detected: &InputConfig{
+				Type: "Github AppImage Release",
+				Programs: map[string]*Program{
+					"jan": {
+						ProgramName: "jan",
+						DesktopFile: "Jan.desktop",
+						Binary: map[string][]string{
+							"amd64": {"jan-linux-x86_64-${VERSION}.AppImage", "jan"},
+						},
+					},
+				},
+			},
+			want: `Type Github AppImage Release
+GithubProjectUrl https://github.com/janhq/jan/
+DesktopFile jan.desktop
+Binary amd64=>jan-linux-x86_64-${VERSION}.AppImage > jan
+`,
+		},
+		{
+			name: "New programs are appended and missing ones left alone",
+			input: `Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Workaround Programs as Alternatives => amd64:glibc
+ProgramName chezmoi
+Binary amd64=>chezmoi_${VERSION}_linux-musl_amd64.tar.gz > chezmoi > chezmoi
+ProgramName glibc
+Binary amd64=>chezmoi_${VERSION}_linux-glibc_amd64.tar.gz > chezmoi > chezmoi
+`,
+			detected: &InputConfig{
+				Type:        "Github Binary Release",
+				Workarounds: map[string]string{"Programs as Alternatives": "amd64:glibc amd64:loong64"},
+				Programs: map[string]*Program{
+					"chezmoi": {
+						ProgramName: "chezmoi",
+						Binary: map[string][]string{
+							"amd64": {"chezmoi_${VERSION}_linux-musl_amd64.tar.gz", "chezmoi", "chezmoi"},
+							"arm64": {"chezmoi_${VERSION}_linux_arm64.tar.gz", "chezmoi", "chezmoi"},
+						},
+					},
+					"loong64": {
+						ProgramName:  "loong64",
+						Dependencies: []string{"sys-libs/glibc"},
+						Binary: map[string][]string{
+							"amd64": {"chezmoi_${VERSION}_linux_loong64.tar.gz", "chezmoi", "chezmoi"},
+						},
+					},
+				},
+			},
+			want: `Type Github Binary Release
+GithubProjectUrl https://github.com/twpayne/chezmoi
+Workaround Programs as Alternatives => amd64:glibc
+ProgramName chezmoi
+Binary amd64=>chezmoi_${VERSION}_linux-musl_amd64.tar.gz > chezmoi > chezmoi
+Binary arm64=>chezmoi_${VERSION}_linux_arm64.tar.gz > chezmoi > chezmoi
+ProgramName glibc
+Binary amd64=>chezmoi_${VERSION}_linux-glibc_amd64.tar.gz > chezmoi > chezmoi
+ProgramName loong64
+Dependencies sys-libs/glibc
+Binary amd64=>chezmoi_${VERSION}_linux_loong64.tar.gz > chezmoi > chezmoi
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			block := NewConfigBlock(test.input)
+			MergeDetectedConfigEntry(block, test.detected)
+			if diff := cmp.Diff(test.want, block.Text()); diff != "" {
+				t.Errorf("MergeDetectedConfigEntry() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigUpdate(t *testing.T) {
+	generators := ConfigEntryGenerators
+	t.Cleanup(func() {
+		ConfigEntryGenerators = generators
+	})
+	ConfigEntryGenerators = map[string]func(gitRepo, tagOverride, tagPrefix string) (*InputConfig, error){
+		"Github Binary Release": func(gitRepo, tagOverride, tagPrefix string) (*InputConfig, error) {
+			if gitRepo != "https://github.com/goreleaser/goreleaser" {
+				t.Errorf("gitRepo = %s", gitRepo)
+			}
+			return &InputConfig{
+				Type: "Github Binary Release",
+				Programs: map[string]*Program{
+					"goreleaser": {
+						ProgramName: "goreleaser",
+						Binary: map[string][]string{
+							"amd64": {"goreleaser_Linux_x86_64.tar.gz", "goreleaser", "goreleaser"},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+	fn := filepath.Join(t.TempDir(), "input.config")
+	if err := os.WriteFile(fn, []byte(testConfigDocumentData), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ConfigUpdate(fn, &EntrySelector{EbuildName: "goreleaser"}, true); err != nil {
+		t.Fatalf("ConfigUpdate() dry run error = %v", err)
+	}
+	if b, _ := os.ReadFile(fn); string(b) != testConfigDocumentData {
+		t.Errorf("dry run modified the file")
+	}
+	if err := ConfigUpdate(fn, &EntrySelector{EbuildName: "goreleaser-bin.ebuild"}, false); err != nil {
+		t.Fatalf("ConfigUpdate() error = %v", err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := strings.Replace(testConfigDocumentData, "Binary arm64=>goreleaser_Linux_arm64.tar.gz > goreleaser > goreleaser\n", "", 1)
+	want = strings.Replace(want, "Binary  amd64", "Binary amd64", 1)
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("ConfigUpdate() mismatch (-want +got):\n%s", diff)
+	}
+	if err := ConfigUpdate(fn, &EntrySelector{EbuildName: "missing"}, true); err == nil {
+		t.Errorf("ConfigUpdate() of an unknown ebuild name should fail")
+	}
+}
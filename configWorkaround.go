@@ -0,0 +1,300 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WorkaroundDefinition describes a `Workaround` the generator understands. Adding a definition to WorkaroundRegistry is
+// enough for it to be accepted by the parser, `config lint`, `config set` and the help text, the templates read the
+// parsed value through the hooks listed in TemplateHooks.
+type WorkaroundDefinition struct {
+	// Name is the name used in `Workaround Name => value`.
+	Name string
+	// Usage is the form of the value such as `prefix`, empty if the workaround takes no value.
+	Usage string
+	// Description is a one line summary shown by `config workarounds` and in lint hints.
+	Description string
+	// Detected is true if `config view` and `config add` set the workaround on their own when they need it.
+	Detected bool
+	// TemplateHooks are the template data methods which expose the workaround to the templates.
+	TemplateHooks []string
+	// Parse converts the value into the type the template hooks use, nil for workarounds without a value.
+	Parse func(value string) (any, error)
+	// Warnings returns the problems with a value Parse accepts, which `config lint` reports as warnings, it can be nil.
+	Warnings func(value string) []string
+}
+
+// TakesValue is true if the workaround is written as `Workaround Name => value`.
+func (wd *WorkaroundDefinition) TakesValue() bool {
+	return wd.Parse != nil
+}
+
+// Example returns the workaround as it is written in a config file.
+func (wd *WorkaroundDefinition) Example() string {
+	if !wd.TakesValue() {
+		return "Workaround " + wd.Name
+	}
+	return fmt.Sprintf("Workaround %s => %s", wd.Name, wd.Usage)
+}
+
+// ParseValue checks the value written for the workaround and returns it in the type the template hooks use, for
+// workarounds without a value this is true and any value written is ignored.
+func (wd *WorkaroundDefinition) ParseValue(value string) (any, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case !wd.TakesValue():
+		return true, nil
+	case value == "":
+		return nil, fmt.Errorf("workaround %s needs a value, use `%s`", wd.Name, wd.Example())
+	}
+	v, err := wd.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("workaround %s: %w", wd.Name, err)
+	}
+	return v, nil
+}
+
+const (
+	// workaroundRemovalPrefix starts the name of a `Workaround -Name` line, which removes a workaround the entry would
+	// otherwise get from `Defaults`.
+	workaroundRemovalPrefix = "-"
+)
+
+var (
+	semanticVersionWithoutVWorkaround = &WorkaroundDefinition{
+		Name:          "Semantic Version Without V",
+		Description:   "the release tags are versions without a leading `v`, such as `1.2.3`",
+		Detected:      true,
+		TemplateHooks: []string{"WorkaroundSemanticVersionWithoutV"},
+	}
+	semanticVersionPrereleaseHack1Workaround = &WorkaroundDefinition{
+		Name:          "Semantic Version Prerelease Hack 1",
+		Description:   "the versions have prereleases such as `-beta.1` which are rewritten into Gentoo versions",
+		Detected:      true,
+		TemplateHooks: []string{"WorkaroundSemanticVersionPrereleaseHack1"},
+	}
+	tagPrefixWorkaround = &WorkaroundDefinition{
+		Name:          "Tag Prefix",
+		Usage:         "prefix",
+		Description:   "only tags starting with the prefix are releases of this package, the prefix is removed from the version",
+		TemplateHooks: []string{"WorkaroundTagPrefix"},
+		Parse: func(value string) (any, error) {
+			if strings.ContainsAny(value, " \t") {
+				return nil, fmt.Errorf("tag prefix %q can't contain spaces", value)
+			}
+			return value, nil
+		},
+	}
+	programsAsAlternativesWorkaround = &WorkaroundDefinition{
+		Name:          "Programs as Alternatives",
+		Usage:         "keyword:program ...",
+		Description:   "the programs are alternative builds for the keyword and are chosen between with USE flags",
+		TemplateHooks: []string{"ProgramsAsAlternatives", "ReverseProgramsAsAlternatives", "ProgramsAsAlternativesForArch"},
+		Parse: func(value string) (any, error) {
+			return parseProgramsAsAlternatives(value), nil
+		},
+		Warnings: programsAsAlternativesWarnings,
+	}
+	tagsWithoutReleasesWorkaround = &WorkaroundDefinition{
+		Name:          "Tags Without Releases",
+		Description:   "the project only pushes tags, the versions are taken from the tags rather than the releases, the binary and AppImage types download the release files from ReleaseUrl",
+		Detected:      true,
+		TemplateHooks: []string{"WorkaroundTagsWithoutReleases"},
+	}
+	liveEbuildWorkaround = &WorkaroundDefinition{
+		Name:          "Live Ebuild",
+		Usage:         "build-system",
+		Description:   "also generate a -9999 ebuild which builds the default branch with git-r3, the build system is one of autotools, cmake, meson, make, go or cargo",
+		TemplateHooks: []string{"LiveEbuildBuildSystem"},
+		Parse: func(value string) (any, error) {
+			if !slices.Contains(LiveEbuildBuildSystems, value) {
+				return nil, fmt.Errorf("unknown build system %s, use one of: %s", value, strings.Join(LiveEbuildBuildSystems, ", "))
+			}
+			return value, nil
+		},
+	}
+	rollingTagWorkaround = &WorkaroundDefinition{
+		Name:          "Rolling Tag",
+		Usage:         "tag build-number|published-date [offset]",
+		Description:   "the only release is a tag such as `continuous` which is moved to each new build, the version is the ${BUILD} number in the release filenames or the date it was published, plus the offset",
+		TemplateHooks: []string{"RollingTag", "RollingTagBuildNumberPattern"},
+		Parse: func(value string) (any, error) {
+			return parseRollingTag(value)
+		},
+	}
+)
+
+const (
+	// RollingTagBuildNumber takes the version from the ${BUILD} number in the release filenames
+	RollingTagBuildNumber = "build-number"
+	// RollingTagPublishedDate takes the version from the date the release was published as YYYYMMDD
+	RollingTagPublishedDate = "published-date"
+)
+
+// RollingTag is a release tag which is moved to each new build, such as `continuous` or `nightly`, so there is only
+// ever one release to make an ebuild of.
+type RollingTag struct {
+	Tag string
+	// Source is where the version comes from, RollingTagBuildNumber or RollingTagPublishedDate.
+	Source string
+	// Offset is added to the number to make the version, so it can carry on from an older version scheme.
+	Offset int
+}
+
+// BuildNumber is true if the version comes from the ${BUILD} number in the release filenames.
+func (rt *RollingTag) BuildNumber() bool {
+	return rt.Source == RollingTagBuildNumber
+}
+
+// parseRollingTag parses `tag source [offset]`.
+func parseRollingTag(value string) (*RollingTag, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("%q isn't `tag build-number|published-date [offset]`", value)
+	}
+	result := &RollingTag{Tag: fields[0], Source: fields[1]}
+	switch result.Source {
+	case RollingTagBuildNumber, RollingTagPublishedDate:
+	default:
+		return nil, fmt.Errorf("unknown version source %s, use %s or %s", result.Source, RollingTagBuildNumber, RollingTagPublishedDate)
+	}
+	if len(fields) == 3 {
+		offset, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("offset %s isn't a number", fields[2])
+		}
+		result.Offset = offset
+	}
+	return result, nil
+}
+
+// WorkaroundRegistry is every workaround the generator understands, in the order they are documented.
+var WorkaroundRegistry = []*WorkaroundDefinition{
+	semanticVersionWithoutVWorkaround,
+	semanticVersionPrereleaseHack1Workaround,
+	tagPrefixWorkaround,
+	programsAsAlternativesWorkaround,
+	tagsWithoutReleasesWorkaround,
+	liveEbuildWorkaround,
+	rollingTagWorkaround,
+}
+
+// WorkaroundNames returns the names of the registered workarounds.
+func WorkaroundNames() []string {
+	var result []string
+	for _, wd := range WorkaroundRegistry {
+		result = append(result, wd.Name)
+	}
+	return result
+}
+
+// LookupWorkaround returns the definition of the workaround with the name.
+func LookupWorkaround(name string) (*WorkaroundDefinition, error) {
+	i := slices.IndexFunc(WorkaroundRegistry, func(wd *WorkaroundDefinition) bool {
+		return wd.Name == name
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("unknown workaround: %s", name)
+	}
+	return WorkaroundRegistry[i], nil
+}
+
+// ValidateWorkaround returns an error if the workaround isn't one the templates understand.
+func ValidateWorkaround(workaround string) error {
+	_, err := LookupWorkaround(workaround)
+	return err
+}
+
+// ValidateWorkaroundValue returns an error if the workaround isn't known or the value isn't valid for it.
+func ValidateWorkaroundValue(workaround, value string) error {
+	wd, err := LookupWorkaround(workaround)
+	if err != nil {
+		return err
+	}
+	_, err = wd.ParseValue(value)
+	return err
+}
+
+// cutWorkaroundRemovals takes the `Workaround -Name` values out of the parsed workarounds and returns the names they
+// remove, in name order.
+func cutWorkaroundRemovals(workarounds map[string]string) []string {
+	var removed []string
+	for _, name := range slices.Sorted(maps.Keys(workarounds)) {
+		if removedName, ok := strings.CutPrefix(name, workaroundRemovalPrefix); ok {
+			delete(workarounds, name)
+			removed = append(removed, strings.TrimSpace(removedName))
+		}
+	}
+	return removed
+}
+
+// validateWorkarounds checks each workaround and its value, in name order so the first error is always the same.
+func validateWorkarounds(workarounds map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(workarounds)) {
+		if err := ValidateWorkaroundValue(name, workarounds[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workaroundValue returns the parsed value of the workaround if it is set and valid.
+func workaroundValue(workarounds map[string]string, wd *WorkaroundDefinition) (any, bool) {
+	value, ok := workarounds[wd.Name]
+	if !ok {
+		return nil, false
+	}
+	v, err := wd.ParseValue(value)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// parseProgramsAsAlternatives parses `keyword:program` pairs into the programs for each keyword. Pairs in any other
+// form have always been ignored rather than rejected, so they still are and programsAsAlternativesWarnings lists them.
+func parseProgramsAsAlternatives(value string) map[string][]string {
+	result := map[string][]string{}
+	for _, each := range strings.Fields(value) {
+		if kw, program, ok := cutProgramAsAlternative(each); ok {
+			result[kw] = append(result[kw], program)
+		}
+	}
+	return result
+}
+
+// programsAsAlternativesWarnings returns a warning for each pair parseProgramsAsAlternatives ignores.
+func programsAsAlternativesWarnings(value string) []string {
+	var warnings []string
+	for _, each := range strings.Fields(value) {
+		if _, _, ok := cutProgramAsAlternative(each); !ok {
+			warnings = append(warnings, fmt.Sprintf("%q isn't `keyword:program`, it is ignored", each))
+		}
+	}
+	return warnings
+}
+
+// cutProgramAsAlternative splits a `keyword:program` pair, it is false if either is missing or there is another `:`.
+func cutProgramAsAlternative(pair string) (string, string, bool) {
+	kw, program, found := strings.Cut(pair, ":")
+	if !found || kw == "" || program == "" || strings.Contains(program, ":") {
+		return "", "", false
+	}
+	return kw, program, true
+}
+
+// WorkaroundHelp describes each registered workaround for `config workarounds`.
+func WorkaroundHelp() string {
+	var sb strings.Builder
+	for _, wd := range WorkaroundRegistry {
+		sb.WriteString(fmt.Sprintf("%s\n    %s\n", wd.Example(), wd.Description))
+		if wd.Detected {
+			sb.WriteString("    Added by `config add` and `config view` when the releases need it.\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkaroundRegistry(t *testing.T) {
+	seen := map[string]bool{}
+	for _, wd := range WorkaroundRegistry {
+		if seen[wd.Name] {
+			t.Errorf("workaround %s is registered twice", wd.Name)
+		}
+		seen[wd.Name] = true
+		if wd.Description == "" || len(wd.TemplateHooks) == 0 {
+			t.Errorf("workaround %s needs a description and template hooks", wd.Name)
+		}
+		if wd.TakesValue() != (wd.Usage != "") {
+			t.Errorf("workaround %s has a value parser but no usage or the other way around", wd.Name)
+		}
+		if err := ValidateWorkaround(wd.Name); err != nil {
+			t.Errorf("ValidateWorkaround(%s) error = %v", wd.Name, err)
+		}
+	}
+	if err := ValidateWorkaround("Made Up"); err == nil || err.Error() != "unknown workaround: Made Up" {
+		t.Errorf("ValidateWorkaround() of an unknown workaround error = %v", err)
+	}
+}
+
+func TestWorkaroundParseValue(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		workaround string
+		value      string
+		want       any
+		wantErr    string
+	}{
+		{name: "Without a value", workaround: "Semantic Version Without V", want: true},
+		{name: "Value is ignored", workaround: "Semantic Version Without V", value: "ignored", want: true},
+		{name: "Tag prefix", workaround: "Tag Prefix", value: " auth- ", want: "auth-"},
+		{name: "Tag prefix without a value", workaround: "Tag Prefix", wantErr: "workaround Tag Prefix needs a value, use `Workaround Tag Prefix => prefix`"},
+		{name: "Tag prefix with a space", workaround: "Tag Prefix", value: "a b", wantErr: `workaround Tag Prefix: tag prefix "a b" can't contain spaces`},
+		{
+			name:       "Programs as alternatives",
+			workaround: "Programs as Alternatives",
+			value:      "amd64:glibc amd64:loong64  arm64:android",
+			want:       map[string][]string{"amd64": {"glibc", "loong64"}, "arm64": {"android"}},
+		},
+		{name: "Live ebuild", workaround: "Live Ebuild", value: "cargo", want: "cargo"},
+		{name: "Live ebuild with an unknown build system", workaround: "Live Ebuild", value: "scons", wantErr: "unknown build system scons"},
+		{name: "Rolling tag", workaround: "Rolling Tag", value: "continuous build-number 646", want: &RollingTag{Tag: "continuous", Source: RollingTagBuildNumber, Offset: 646}},
+		{name: "Rolling tag without an offset", workaround: "Rolling Tag", value: "nightly published-date", want: &RollingTag{Tag: "nightly", Source: RollingTagPublishedDate}},
+		{name: "Rolling tag with an unknown source", workaround: "Rolling Tag", value: "nightly commit", wantErr: "unknown version source commit"},
+		{name: "Rolling tag offset isn't a number", workaround: "Rolling Tag", value: "nightly build-number x", wantErr: "offset x isn't a number"},
+		{
+			name:       "Programs as alternatives ignores pairs in other forms",
+			workaround: "Programs as Alternatives",
+			value:      "amd64:glibc arm64 arm64:a:b :musl",
+			want:       map[string][]string{"amd64": {"glibc"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			wd, err := LookupWorkaround(test.workaround)
+			if err != nil {
+				t.Fatalf("LookupWorkaround() error = %v", err)
+			}
+			got, err := wd.ParseValue(test.value)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("ParseValue() error = %v, want %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseValue() error = %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseValue() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintWorkaroundValues(t *testing.T) {
+	doc, err := ParseConfigDocument(strings.NewReader(`Type Github Binary Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+Workaround Tag Prefix
+Workaround Semantic Version Without V => yes
+Workaround Programs as Alternatives => amd64
+Binary amd64=>g2_linux_amd64.tar.gz > g2
+`))
+	if err != nil {
+		t.Fatalf("ParseConfigDocument() error = %v", err)
+	}
+	doc.Filename = "test.config"
+	var got []string
+	for _, diagnostic := range LintConfigDocument(doc) {
+		got = append(got, strings.SplitN(diagnostic.String(), "\n", 2)[0])
+	}
+	want := []string{
+		"test.config:4:12: error: workaround Tag Prefix needs a value, use `Workaround Tag Prefix => prefix`",
+		"test.config:5:12: warning: workaround Semantic Version Without V doesn't take a value, it is ignored",
+		"test.config:6:12: warning: workaround Programs as Alternatives: \"amd64\" isn't `keyword:program`, it is ignored",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LintConfigDocument() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigSetWorkaroundValue(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "input.config")
+	if err := os.WriteFile(fn, []byte(testEntryIdData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigSetWorkaround(fn, &EntrySelector{Id: 3}, "Tag Prefix", "", false, false); err == nil || !strings.Contains(err.Error(), "needs a value") {
+		t.Errorf("ConfigSetWorkaround() without a value error = %v", err)
+	}
+	if err := ConfigSetWorkaround(fn, &EntrySelector{Id: 3}, "Tag Prefix", "", true, false); err != nil {
+		t.Errorf("ConfigSetWorkaround() unset error = %v", err)
+	}
+}
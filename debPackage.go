@@ -0,0 +1,129 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// arMagic starts every ar archive, a .deb is an ar archive of debian-binary, control.tar and data.tar
+	arMagic = "!<arch>\n"
+	// arHeaderSize is the size of the header of each ar member
+	arHeaderSize = 60
+)
+
+// debDataReader is the uncompressed data.tar of a .deb.
+type debDataReader struct {
+	io.Reader
+	closer func()
+}
+
+func (ddr *debDataReader) Close() error {
+	if ddr.closer != nil {
+		ddr.closer()
+	}
+	return nil
+}
+
+// OpenDebData returns the uncompressed data.tar of a .deb, the files which the package installs. The data.tar can be
+// uncompressed or compressed with gzip, xz, zstd or bzip2.
+func OpenDebData(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != arMagic {
+		return nil, fmt.Errorf("not an ar archive")
+	}
+	header := make([]byte, arHeaderSize)
+	for {
+		if _, err := io.ReadFull(br, header); errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no data.tar found")
+		} else if err != nil {
+			return nil, fmt.Errorf("reading ar header: %w", err)
+		}
+		if !bytes.Equal(header[58:60], []byte("`\n")) {
+			return nil, fmt.Errorf("bad ar header")
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ar member %s size: %w", name, err)
+		}
+		if !strings.HasPrefix(name, "data.tar") {
+			// Members are padded to an even size
+			if _, err := br.Discard(int(size + size%2)); err != nil {
+				return nil, fmt.Errorf("skipping ar member %s: %w", name, err)
+			}
+			continue
+		}
+		data := io.LimitReader(br, size)
+		switch path.Ext(name) {
+		case ".tar":
+			return &debDataReader{Reader: data}, nil
+		case ".gz":
+			gr, err := gzip.NewReader(data)
+			if err != nil {
+				return nil, fmt.Errorf("opening gzip %s: %w", name, err)
+			}
+			return &debDataReader{Reader: gr}, nil
+		case ".xz":
+			xr, err := xz.NewReader(data)
+			if err != nil {
+				return nil, fmt.Errorf("opening xz %s: %w", name, err)
+			}
+			return &debDataReader{Reader: xr}, nil
+		case ".zst":
+			zr, err := zstd.NewReader(data)
+			if err != nil {
+				return nil, fmt.Errorf("opening zstd %s: %w", name, err)
+			}
+			return &debDataReader{Reader: zr, closer: zr.Close}, nil
+		case ".bz2":
+			return &debDataReader{Reader: bzip2.NewReader(data)}, nil
+		default:
+			return nil, fmt.Errorf("unknown compression of %s", name)
+		}
+	}
+}
+
+// readDeb adds the files of the data.tar of a .deb.
+func (pf *packageFiles) readDeb(r io.Reader) error {
+	data, err := OpenDebData(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := data.Close(); err != nil {
+			log.Printf("Error closing deb data: %s", err)
+		}
+	}()
+	tr := tar.NewReader(data)
+	for {
+		zfh, err := tr.Next()
+		if zfh == nil || errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading next data.tar file: %w", err)
+		}
+		switch zfh.Typeflag {
+		case tar.TypeSymlink:
+			pf.addSymlink(zfh.Name, zfh.Linkname)
+		case tar.TypeReg:
+			if err := pf.addFile(zfh.Name, (zfh.Mode&0o0500) == 0o0500, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPackageFile is a file of the test packages, a directory if the name ends in /.
+type testPackageFile struct {
+	name     string
+	mode     int64
+	content  []byte
+	linkname string
+}
+
+// newTestElf is the smallest ELF file the binary checks accept, an x86-64 executable header without any sections.
+func newTestElf() []byte {
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Ehsize:    uint16(binary.Size(elf.Header64{})),
+		Phentsize: uint16(binary.Size(elf.Prog64{})),
+		Shentsize: uint16(binary.Size(elf.Section64{})),
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, header)
+	return buf.Bytes()
+}
+
+// newTestPackageFiles are the files of the test packages, a small ELF executable installed in /opt/viewer and linked
+// into /usr/bin, with a desktop file, an icon, a manual page and a shell script which isn't a binary.
+func newTestPackageFiles() []testPackageFile {
+	return []testPackageFile{
+		{name: "./usr/bin/", mode: 0755},
+		{name: "./opt/viewer/viewer", mode: 0755, content: newTestElf()},
+		{name: "./usr/bin/viewer", linkname: "/opt/viewer/viewer"},
+		{name: "./usr/bin/viewer-update", mode: 0755, content: []byte("#!/bin/sh\n")},
+		{name: "./usr/share/applications/viewer.desktop", mode: 0644, content: []byte("[Desktop Entry]\nExec=/opt/viewer/viewer %U\n")},
+		{name: "./usr/share/icons/hicolor/256x256/apps/viewer.png", mode: 0644, content: []byte("png")},
+		{name: "./usr/share/man/man1/viewer.1.gz", mode: 0644, content: []byte("man")},
+		{name: "./usr/share/doc/viewer/copyright", mode: 0644, content: []byte("MIT")},
+	}
+}
+
+// newTestCompressWriter compresses with gz, xz or zst.
+func newTestCompressWriter(t *testing.T, w io.Writer, compression string) io.WriteCloser {
+	t.Helper()
+	switch compression {
+	case "gz":
+		gw, _ := gzip.NewWriterLevel(w, gzip.BestSpeed)
+		return gw
+	case "xz":
+		xw, err := xz.NewWriter(w)
+		if err != nil {
+			t.Fatalf("xz.NewWriter() error = %v", err)
+		}
+		return xw
+	case "zst":
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			t.Fatalf("zstd.NewWriter() error = %v", err)
+		}
+		return zw
+	}
+	t.Fatalf("unknown compression %s", compression)
+	return nil
+}
+
+// newTestDeb is a .deb of the test package files with the data.tar compressed with the given compression.
+func newTestDeb(t *testing.T, compression string) []byte {
+	t.Helper()
+	var data bytes.Buffer
+	cw := newTestCompressWriter(t, &data, compression)
+	tw := tar.NewWriter(cw)
+	for _, file := range newTestPackageFiles() {
+		header := &tar.Header{Name: file.name, Mode: file.mode, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		switch {
+		case file.linkname != "":
+			header.Typeflag, header.Linkname, header.Mode = tar.TypeSymlink, file.linkname, 0777
+		case strings.HasSuffix(file.name, "/"):
+			header.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("tar WriteHeader() error = %v", err)
+		}
+		if _, err := tw.Write(file.content); err != nil {
+			t.Fatalf("tar Write() error = %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("%s Close() error = %v", compression, err)
+	}
+	var deb bytes.Buffer
+	deb.WriteString(arMagic)
+	for _, member := range []struct {
+		name    string
+		content []byte
+	}{
+		{name: "debian-binary", content: []byte("2.0\n")},
+		{name: "control.tar.gz", content: []byte("not read")},
+		{name: "data.tar." + compression, content: data.Bytes()},
+	} {
+		fmt.Fprintf(&deb, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", member.name, 0, 0, 0, "100644", len(member.content))
+		deb.Write(member.content)
+		if len(member.content)%2 == 1 {
+			deb.WriteByte('\n')
+		}
+	}
+	return deb.Bytes()
+}
+
+func TestOpenDebData(t *testing.T) {
+	for _, compression := range []string{"gz", "xz", "zst"} {
+		t.Run(compression, func(t *testing.T) {
+			data, err := OpenDebData(bytes.NewReader(newTestDeb(t, compression)))
+			if err != nil {
+				t.Fatalf("OpenDebData() error = %v", err)
+			}
+			defer data.Close()
+			var got []string
+			tr := tar.NewReader(data)
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("tar Next() error = %v", err)
+				}
+				got = append(got, header.Name)
+			}
+			want := []string{"./usr/bin/", "./opt/viewer/viewer", "./usr/bin/viewer", "./usr/bin/viewer-update", "./usr/share/applications/viewer.desktop", "./usr/share/icons/hicolor/256x256/apps/viewer.png", "./usr/share/man/man1/viewer.1.gz", "./usr/share/doc/viewer/copyright"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("data.tar files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := OpenDebData(strings.NewReader("not a deb")); err == nil {
+		t.Errorf("OpenDebData() of something else should fail")
+	}
+}
+
+func TestDetectDebBinaryReleaseConfigEntry(t *testing.T) {
+	debs := map[string][]byte{
+		"/viewer_1.2.0_amd64.deb": newTestDeb(t, "xz"),
+		"/viewer_1.2.0_arm64.deb": newTestDeb(t, "zst"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if deb, ok := debs[r.URL.Path]; ok {
+			_, _ = w.Write(deb)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	var files []*BinaryReleaseFileInfo
+	for _, name := range []string{"viewer_1.2.0_amd64.deb", "viewer_1.2.0_arm64.deb", "viewer-1.2.0.tar.gz"} {
+		files = append(files, &BinaryReleaseFileInfo{Filename: name, DownloadUrl: server.URL + "/" + name})
+	}
+	ic := &InputConfig{Type: "Github Binary Release", GithubRepo: "viewer", Workarounds: map[string]string{}}
+	ic, err := detectBinaryReleaseConfigEntry("viewer", ic, []string{"1.2.0"}, []string{"v1.2.0"}, files)
+	if err != nil {
+		t.Fatalf("detectBinaryReleaseConfigEntry() error = %v", err)
+	}
+	if len(ic.Programs) != 1 {
+		t.Fatalf("Programs = %#v", ic.Programs)
+	}
+	for _, p := range ic.Programs {
+		want := &Program{
+			ProgramName: "viewer",
+			Binary: map[string][]string{
+				"amd64": {"viewer_${VERSION}_amd64.deb", "opt/viewer/viewer", "viewer"},
+				"arm64": {"viewer_${VERSION}_arm64.deb", "opt/viewer/viewer", "viewer"},
+			},
+			DesktopFile: "viewer.desktop",
+			Icons:       []string{"hicolor-apps"},
+			ManualPage: map[string][][]string{
+				"amd64": {{"viewer_${VERSION}_amd64.deb", "usr/share/man/man1/viewer.1.gz", "viewer.1"}},
+				"arm64": {{"viewer_${VERSION}_arm64.deb", "usr/share/man/man1/viewer.1.gz", "viewer.1"}},
+			},
+		}
+		got := &Program{ProgramName: p.ProgramName, Binary: p.Binary, DesktopFile: p.DesktopFile, Icons: p.Icons, ManualPage: p.ManualPage}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("program mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+const testDebConfigData = `Type Github Binary Release
+GithubProjectUrl https://github.com/example/viewer
+Category media-gfx
+Binary amd64=>viewer_${VERSION}_amd64.deb > opt/viewer/viewer > viewer
+DesktopFile viewer
+Icons hicolor-apps pixmaps
+`
+
+func TestGenerateDebBinaryWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testDebConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if !strings.Contains(ics[0].String(), "DesktopFile viewer.desktop\nIcons hicolor-apps pixmaps\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "media-gfx-viewer-bin-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		"echo 'EAPI=8'\n                echo ''\n                echo 'inherit unpacker xdg-utils'\n",
+		`echo "    unpacker \"\${DISTDIR}/\${P}-viewer_\${PV}_amd64.deb\" || die \"Can't unpack deb file\""`,
+		`echo "  sed -i 's:^Exec=[^ ]*:Exec=/opt/bin/${{ env.binary_installed_name }}:' 'usr/share/applications/${{ env.desktop_file }}'"`,
+		`echo '    newexe "${{ env.binary_archived_name_amd64 }}" "${{ env.binary_installed_name }}" || die "Failed to install Binary"'`,
+		`echo '  doins "usr/share/applications/${{ env.desktop_file }}" || die "Failed to install desktop file"'`,
+		`echo '  doins -r usr/share/icons/hicolor || die "Failed to install icons"'`,
+		`echo '  doins usr/share/pixmaps/* || die "Failed to install icons"'`,
+		`echo '  xdg_icon_cache_update'`,
+		`echo 'pkg_postrm() {'`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("media-gfx-viewer-bin-update.yaml doesn't contain %q", want)
+		}
+	}
+}
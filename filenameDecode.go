@@ -256,7 +256,7 @@ func GenerateWordMeanings(gitRepo string, versions []string, tags []string) map[
 		"LICENSE":     {Document: true},
 		"AppImage":    {AppImage: true, OS: "linux", SuffixOnly: true},
 		"deb":         {Container: "deb", OS: "linux", SuffixOnly: true},
-		"rpm":         {Container: "deb", OS: "linux", SuffixOnly: true},
+		"rpm":         {Container: "rpm", OS: "linux", SuffixOnly: true},
 		"exe":         {OS: "windows", SuffixOnly: true},
 		"dmg":         {OS: "macosx", SuffixOnly: true},
 		"pkg":         {OS: "macosx", SuffixOnly: true},
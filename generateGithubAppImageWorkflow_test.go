@@ -3,8 +3,11 @@ package arrans_overlay_workflow_builder
 import (
 	"bytes"
 	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -143,3 +146,51 @@ func TestExternalResourcesToArchivedResourceNameConsistency(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateRollingTagAppImageWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(`Type Github AppImage Release
+GithubProjectUrl https://github.com/probonopd/go-appimage
+EbuildName go-appimage
+Workaround Rolling Tag => continuous build-number 646
+ProgramName appimagetool
+Binary amd64=>appimagetool-${BUILD}-x86_64.AppImage > appimagetool.AppImage
+`))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if got, want := ics[0].RollingTagBuildNumberPattern(), `appimagetool-\([0-9]\+\)-x86_64\.AppImage`; got != want {
+		t.Errorf("RollingTagBuildNumberPattern() = %s, want %s", got, want)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "app-misc-go-appimage-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		`https://api.github.com/repos/${{ env.github_owner }}/${{ env.github_repo }}/releases/tags/continuous)`,
+		`BUILD="$(echo "${assets}" | sed -n 's/^appimagetool-\([0-9]\+\)-x86_64\.AppImage$/\1/p' | sort -n | tail -n 1)"`,
+		`version="$(( BUILD + 646 ))"`,
+		`echo "BUILD=\"${BUILD}\""`,
+		`find "${ebuild_dir}" -name "${{ env.epn }}-*.ebuild" ! -path "${ebuild_file}" -delete`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("app-misc-go-appimage-update.yaml doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(string(b), "egrep") {
+		t.Errorf("app-misc-go-appimage-update.yaml checks the rolling version is semantic")
+	}
+
+	ics[0].Workarounds["Rolling Tag"] = "continuous build-number"
+	ics[0].Programs["appimagetool"].Binary["amd64"][0] = "appimagetool-x86_64.AppImage"
+	if err := ics[0].Validate(); err == nil {
+		t.Errorf("Validate() should fail without a ${BUILD} in a release filename")
+	}
+}
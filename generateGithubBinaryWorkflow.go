@@ -106,6 +106,34 @@ func (ggbtd *GenerateGithubBinaryTemplateData) HasDesktopFile() bool {
 	return false
 }
 
+// Icons are the unique icon locations of all the programs.
+func (ggbtd *GenerateGithubBinaryTemplateData) Icons() []string {
+	var result []string
+	for _, p := range ggbtd.Programs {
+		for _, icon := range p.Icons {
+			if !slices.Contains(result, icon) {
+				result = append(result, icon)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (ggbtd *GenerateGithubBinaryTemplateData) HasIcons() bool {
+	return len(ggbtd.Icons()) > 0
+}
+
+// HasDebs is true if any of the release files are Debian packages, which are unpacked with unpacker.eclass.
+func (ggbtd *GenerateGithubBinaryTemplateData) HasDebs() bool {
+	return slices.ContainsFunc(ggbtd.ExternalResources(), (*ExternalResourceKeywordExtended).Deb)
+}
+
+// HasRpms is true if any of the release files are RPM packages, which are unpacked with rpm.eclass.
+func (ggbtd *GenerateGithubBinaryTemplateData) HasRpms() bool {
+	return slices.ContainsFunc(ggbtd.ExternalResources(), (*ExternalResourceKeywordExtended).Rpm)
+}
+
 func (ggbtd *GenerateGithubBinaryTemplateData) HasManualPages() bool {
 	for _, p := range ggbtd.Programs {
 		if p.HasManualPage() {
@@ -384,7 +412,7 @@ func (ggbtd *GenerateGithubBinaryTemplateData) inferUseFlags() {
 			if v, ok := ggbtd.MustntHaveUseFlags[programName][kw]; !ok || v == nil {
 				ggbtd.MustntHaveUseFlags[programName][kw] = []string{}
 			}
-			if programName == "" || programName == ggbtd.GithubRepo {
+			if programName == "" || programName == ggbtd.RepoName() {
 				alts, ok := archAlts[kw]
 				if !ok || len(alts) <= 0 {
 					continue
@@ -431,6 +459,16 @@ func (erke *ExternalResourceKeywordExtended) Archived() bool {
 	return erke.ExternalResource.Archived
 }
 
+// Deb is true if the release file is a Debian package.
+func (erke *ExternalResourceKeywordExtended) Deb() bool {
+	return strings.HasSuffix(strings.ToLower(erke.ExternalResource.ReleaseFilename), ".deb")
+}
+
+// Rpm is true if the release file is an RPM package.
+func (erke *ExternalResourceKeywordExtended) Rpm() bool {
+	return strings.HasSuffix(strings.ToLower(erke.ExternalResource.ReleaseFilename), ".rpm")
+}
+
 func (ggbtd *GenerateGithubBinaryTemplateData) ExternalResources() []*ExternalResourceKeywordExtended {
 	ggbtd.inferUseFlags()
 	m := make(map[string]*ExternalResourceKeywordExtended)
@@ -494,20 +532,24 @@ func (ggbtd *GenerateGithubBinaryTemplateData) ProgramsAsAlternatives() map[stri
 	if ggbtd._programsAsAlternatives != nil {
 		return ggbtd._programsAsAlternatives
 	}
-	if ggbtd.Workarounds == nil {
+	v, ok := workaroundValue(ggbtd.Workarounds, programsAsAlternativesWorkaround)
+	if !ok {
 		return map[string][]string{}
 	}
-	ggbtd._programsAsAlternatives = map[string][]string{}
-	s := ggbtd.Workarounds["Programs as Alternatives"]
-	ss := strings.Split(s, " ")
-	for _, each := range ss {
-		e := strings.Split(each, ":")
-		if len(e) != 2 {
-			continue
+	ggbtd._programsAsAlternatives = v.(map[string][]string)
+	return ggbtd._programsAsAlternatives
+}
+
+// UseFlagDescriptions returns the description of each program which is chosen with a USE flag by `Programs as
+// Alternatives`, for the USE flag descriptions in metadata.xml.
+func (ggbtd *GenerateGithubBinaryTemplateData) UseFlagDescriptions() map[string]string {
+	result := map[string]string{}
+	for use := range ggbtd.ReverseProgramsAsAlternatives() {
+		if p, ok := ggbtd.Programs[use]; ok && p.Description != "" {
+			result[use] = p.Description
 		}
-		ggbtd._programsAsAlternatives[strings.TrimSpace(e[0])] = append(ggbtd._programsAsAlternatives[strings.TrimSpace(e[0])], strings.TrimSpace(e[1]))
 	}
-	return ggbtd._programsAsAlternatives
+	return result
 }
 
 func (ggbtd *GenerateGithubBinaryTemplateData) ReverseProgramsAsAlternatives() map[string][]string {
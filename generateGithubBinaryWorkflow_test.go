@@ -3,7 +3,10 @@ package arrans_overlay_workflow_builder
 import (
 	"bytes"
 	"log"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -306,6 +309,117 @@ Binary amd64=>chezmoi_${VERSION}_linux_loong64.tar.gz > chezmoi > chezmoi
 	}
 }
 
+func TestGenerateGithubWorkflowProgramDetails(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testProgramDetailsData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "app-misc-go-appimage-bin-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		"echo '# appimaged: Optional AppImage daemon for desktop integration'",
+		"echo '# appimagetool: Tool to generate AppImages from AppDirs, Homepage: https://github.com/probonopd/go-appimage/tree/master/src/appimagetool, License: Apache License 2.0'",
+		"echo '  exeinto /usr/bin'\n                echo '  if use amd64 && use appimaged && ! use appimagetool ; then'",
+		"echo '    <flag name=\"appimaged\">Optional AppImage daemon for desktop integration</flag>'",
+		"} > \"${ebuild_dir}/metadata.xml\"",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("workflow doesn't contain %q", want)
+		}
+	}
+}
+
+func TestGenerateTagsWithoutReleasesWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(`Type Github Binary Release
+GithubProjectUrl https://github.com/example/tagged
+ReleaseUrl https://downloads.example.org/tagged/${VERSION}/
+Workaround Tags Without Releases
+Binary amd64=>tagged-${VERSION}-linux-amd64.tar.gz > tagged
+
+Type Github AppImage Release
+GithubProjectUrl https://github.com/example/tagged
+ReleaseUrl https://downloads.example.org/${TAG}
+Workaround Tags Without Releases
+Binary amd64=>Tagged-${VERSION}-x86_64.AppImage > tagged
+`))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if want := "ReleaseUrl https://downloads.example.org/tagged/${VERSION}/\n"; !strings.Contains(ics[0].String(), want) {
+		t.Errorf("String() = %s, doesn't contain %q", ics[0].String(), want)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, test := range []struct {
+		ic       *InputConfig
+		workflow string
+		want     []string
+	}{
+		{
+			ic:       ics[0],
+			workflow: "app-misc-tagged-bin-update.yaml",
+			want: []string{
+				`"https://api.github.com/repos/${{ env.github_owner }}/${{ env.github_repo }}/tags?per_page=100&page=${page}" | jq -r '.[].name')" && [ -n "${page_tags}" ]; do`,
+				`https://downloads.example.org/tagged/${originalVersion}/tagged-\${PV}-linux-amd64.tar.gz -> \${P}-tagged-\${PV}-linux-amd64.tar.gz`,
+				`g2 manifest upsert-from-url "https://downloads.example.org/tagged/${originalVersion}/tagged-${version}-linux-amd64.tar.gz"`,
+			},
+		},
+		{
+			ic:       ics[1],
+			workflow: "app-misc-tagged-appimage-update.yaml",
+			want: []string{
+				`"https://api.github.com/repos/${{ env.github_owner }}/${{ env.github_repo }}/tags?per_page=100&page=${page}" | jq -r '.[].name')" && [ -n "${page_tags}" ]; do`,
+				`https://downloads.example.org/${tag}/Tagged-\${PV}-x86_64.AppImage -> \${P}-Tagged-\${PV}-x86_64.AppImage`,
+			},
+		},
+	} {
+		if err := test.ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+		b, err := os.ReadFile(filepath.Join(outputDir, test.workflow))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", test.workflow, want)
+			}
+		}
+		if strings.Contains(string(b), "/releases |") || strings.Contains(string(b), "/releases/download/") {
+			t.Errorf("%s uses the releases", test.workflow)
+		}
+	}
+
+	for _, config := range []string{
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/example/tagged\nWorkaround Tags Without Releases\nBinary amd64=>tagged.tar.gz > tagged\n",
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/example/tagged\nReleaseUrl https://downloads.example.org\nWorkaround Tags Without Releases\nWorkaround Rolling Tag => nightly published-date\nBinary amd64=>tagged.tar.gz > tagged\n",
+		"Type GitLab Binary Release\nGitlabProjectUrl https://gitlab.com/example/tagged\nReleaseUrl https://downloads.example.org\nBinary amd64=>tagged.tar.gz > tagged\n",
+		"Type Github Llamafile Release\nGithubProjectUrl https://github.com/example/tagged\nWorkaround Tags Without Releases\nBinary amd64=>tagged.llamafile > tagged.llamafile\n",
+		"Type Github Source Release\nGithubProjectUrl https://github.com/example/tagged\nBuildSystem make\nReleaseUrl https://downloads.example.org\n",
+	} {
+		ics, err := ParseInputConfigReader(strings.NewReader(config))
+		if err == nil {
+			err = ics[0].Validate()
+		}
+		if err == nil {
+			t.Errorf("%q should fail", config)
+		}
+	}
+}
+
 func NewGenerateGithubBinaryTemplateDataFromString(s string) *GenerateGithubBinaryTemplateData {
 	ics, err := ParseInputConfigReader(bytes.NewReader([]byte(s)))
 	if err != nil {
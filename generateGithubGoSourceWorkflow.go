@@ -0,0 +1,31 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"strings"
+)
+
+type GenerateGithubGoSourceTemplateData struct {
+	*GenerateGithubSourceTemplateData
+}
+
+func (gggstd *GenerateGithubGoSourceTemplateData) TemplateFileName() string {
+	return "github-go-source.tmpl"
+}
+
+// GoPackageList are the packages `go build` is run on.
+func (gggstd *GenerateGithubGoSourceTemplateData) GoPackageList() []string {
+	if len(gggstd.GoPackages) == 0 {
+		return []string{"."}
+	}
+	return gggstd.GoPackages
+}
+
+// EbuildGoLdflags is GoLdflags with ${VERSION} replaced with the ebuild's ${PV}.
+func (gggstd *GenerateGithubGoSourceTemplateData) EbuildGoLdflags() string {
+	return strings.ReplaceAll(gggstd.GoLdflags, "${VERSION}", "${PV}")
+}
+
+// GoDependencyTarballUrl is GoDependencyTarball with ${VERSION} and ${TAG} replaced with the workflow's shell variables.
+func (gggstd *GenerateGithubGoSourceTemplateData) GoDependencyTarballUrl() string {
+	return strings.NewReplacer("${VERSION}", "${version}", "${TAG}", "${tag}").Replace(gggstd.GoDependencyTarball)
+}
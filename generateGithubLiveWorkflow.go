@@ -0,0 +1,66 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GenerateGithubLiveTemplateData is the workflow which creates the -9999 ebuild of an entry with the Live Ebuild
+// workaround. It is the source package, so the binary and AppImage types lose their suffix.
+type GenerateGithubLiveTemplateData struct {
+	*GenerateGithubWorkflowBase
+}
+
+func (ggltd *GenerateGithubLiveTemplateData) TemplateFileName() string {
+	return "github-live.tmpl"
+}
+
+func (ggltd *GenerateGithubLiveTemplateData) WorkflowName() string {
+	return fmt.Sprintf("%s/%s live update", ggltd.Category, ggltd.PackageName())
+}
+
+func (ggltd *GenerateGithubLiveTemplateData) WorkflowFileName() string {
+	return fmt.Sprintf("%s-%s-live-update.yaml", ggltd.Category, ggltd.PackageName())
+}
+
+func (ggltd *GenerateGithubLiveTemplateData) PackageName() string {
+	return util.TrimSuffixes(strings.TrimSuffix(ggltd.EbuildName, ".ebuild"), "-bin", "-appimage")
+}
+
+// Inherit is git-r3 after the eclass of the build system, the default phases of `make` don't need one.
+func (ggltd *GenerateGithubLiveTemplateData) Inherit() string {
+	switch ggltd.LiveEbuildBuildSystem() {
+	case "make":
+		return "git-r3"
+	case "go":
+		return "go-module git-r3"
+	default:
+		return ggltd.LiveEbuildBuildSystem() + " git-r3"
+	}
+}
+
+// RuntimeDependencies are the Dependencies of every program, they are added to DEPEND for RDEPEND.
+func (ggltd *GenerateGithubLiveTemplateData) RuntimeDependencies() []string {
+	deps := make([]string, 0)
+	for programName := range ggltd.Programs {
+		deps = append(deps, ggltd.Programs[programName].Dependencies...)
+	}
+	sort.Strings(deps)
+	return slices.CompactFunc(deps, strings.EqualFold)
+}
+
+// GoPackageList are the packages `go build` is run on.
+func (ggltd *GenerateGithubLiveTemplateData) GoPackageList() []string {
+	if len(ggltd.GoPackages) == 0 {
+		return []string{"."}
+	}
+	return ggltd.GoPackages
+}
+
+// EbuildGoLdflags is GoLdflags with ${VERSION} replaced with the ebuild's ${PV}.
+func (ggltd *GenerateGithubLiveTemplateData) EbuildGoLdflags() string {
+	return strings.ReplaceAll(ggltd.GoLdflags, "${VERSION}", "${PV}")
+}
@@ -0,0 +1,99 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateGithubLiveWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(`Type Github Binary Release
+GithubProjectUrl https://github.com/example/viewer
+Category media-gfx
+Description Views things
+License Apache-2.0
+Workaround Live Ebuild => cmake
+ProgramName viewer
+Dependencies sys-libs/glibc
+Binary amd64=>viewer_${VERSION}_linux_amd64 > viewer
+
+Type Github Go Source Release
+GithubProjectUrl https://github.com/example/gotool
+GoPackages ./cmd/gotool
+GoLdflags -X main.version=${VERSION}
+Workaround Live Ebuild => go
+
+Type Github Rust Source Release
+GithubProjectUrl https://github.com/example/rusttool
+License GPL-2
+EbuildVariable LICENSE => || ( MIT Apache-2.0 )
+Workaround Live Ebuild => autotools
+`))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, ic := range ics {
+		if err := ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+	}
+	for filename, test := range map[string]struct {
+		wants    []string
+		notWants []string
+	}{
+		"media-gfx-viewer-live-update.yaml": {
+			wants: []string{
+				`epn: viewer`,
+				`description: "Views things"`,
+				`ebuild_file="${ebuild_dir}/${{ env.epn }}-9999.ebuild"`,
+				`echo 'inherit cmake git-r3'`,
+				`echo 'EGIT_REPO_URI="https://github.com/${{ env.github_owner }}/${{ env.github_repo }}.git"'`,
+				`echo 'LICENSE="Apache-2.0"'`,
+				`echo 'RDEPEND="${DEPEND} sys-libs/glibc"'`,
+			},
+			notWants: []string{"KEYWORDS", "SRC_URI", "schedule"},
+		},
+		"app-misc-gotool-live-update.yaml": {
+			wants: []string{
+				`echo 'inherit go-module git-r3'`,
+				`echo '  go-module_live_vendor'`,
+				`echo '  ego build -ldflags "-X main.version=${PV}" -o bin/ ./cmd/gotool'`,
+				`echo 'LICENSE="MIT"'`,
+			},
+		},
+		"app-misc-rusttool-live-update.yaml": {
+			wants:    []string{`echo 'inherit autotools git-r3'`, `echo '  eautoreconf'`, `echo 'LICENSE="|| ( MIT Apache-2.0 )"'`},
+			notWants: []string{"cargo"},
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, filename))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range test.wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", filename, want)
+			}
+		}
+		for _, notWant := range test.notWants {
+			if strings.Contains(string(b), notWant) {
+				t.Errorf("%s contains %q", filename, notWant)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "media-gfx-viewer-bin-update.yaml")); err != nil {
+		t.Errorf("the versioned workflow wasn't written with the live one: %v", err)
+	}
+
+	ic := &InputConfig{Type: "Gitea Binary Release", Workarounds: map[string]string{"Live Ebuild": "make"}}
+	if err := ic.Validate(); err == nil {
+		t.Errorf("Validate() should fail for workaround Live Ebuild on a Gitea type")
+	}
+}
@@ -0,0 +1,110 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type GenerateGithubLlamafileTemplateData struct {
+	*GenerateGithubWorkflowBase
+}
+
+// LlamafileResource is a release file of the entry and where it is installed.
+type LlamafileResource struct {
+	ReleaseFilename string
+	InstalledName   string
+	InstallPath     string
+	// Keywords the release file is only used on, empty if it is used on all the keywords of the entry.
+	Keywords []string
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) WorkflowName() string {
+	return fmt.Sprintf("%s/%s update", ggltd.Category, ggltd.PackageName())
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) KeywordList() []string {
+	keywords := make([]string, 0)
+	for programName := range ggltd.Programs {
+		for key := range ggltd.Programs[programName].Binary {
+			keywords = append(keywords, key)
+		}
+	}
+	sort.Strings(keywords)
+	return slices.Compact(keywords)
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) Dependencies() []string {
+	dependencies := make([]string, 0)
+	for programName := range ggltd.Programs {
+		dependencies = append(dependencies, ggltd.Programs[programName].Dependencies...)
+	}
+	sort.Strings(dependencies)
+	return slices.Compact(dependencies)
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) MaskedKeywords() string {
+	list := ggltd.KeywordList()
+	for i := range list {
+		list[i] = "~" + strings.TrimPrefix(list[i], "~")
+	}
+	return strings.Join(list, " ")
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) TemplateFileName() string {
+	return "github-llamafile.tmpl"
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) WorkflowFileName() string {
+	return fmt.Sprintf("%s-%s-update.yaml", ggltd.Category, ggltd.PackageName())
+}
+
+func (ggltd *GenerateGithubLlamafileTemplateData) PackageName() string {
+	return strings.TrimSuffix(ggltd.EbuildName, ".ebuild")
+}
+
+// Resources are the release files of the programs in the order of their release filenames. A llamafile runs on each
+// keyword so the same release file is usually used on all of them.
+func (ggltd *GenerateGithubLlamafileTemplateData) Resources() []*LlamafileResource {
+	byReleaseFilename := map[string]*LlamafileResource{}
+	for _, program := range ggltd.Programs {
+		for keyword, binary := range program.Binary {
+			if len(binary) == 0 {
+				continue
+			}
+			resource, ok := byReleaseFilename[binary[0]]
+			if !ok {
+				resource = &LlamafileResource{
+					ReleaseFilename: binary[0],
+					InstalledName:   binary[len(binary)-1],
+					InstallPath:     program.InstallDirectory(),
+				}
+				byReleaseFilename[binary[0]] = resource
+			}
+			resource.Keywords = append(resource.Keywords, keyword)
+		}
+	}
+	keywords := ggltd.KeywordList()
+	result := make([]*LlamafileResource, 0, len(byReleaseFilename))
+	for _, resource := range byReleaseFilename {
+		sort.Strings(resource.Keywords)
+		if slices.Equal(resource.Keywords, keywords) {
+			resource.Keywords = nil
+		}
+		result = append(result, resource)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ReleaseFilename < result[j].ReleaseFilename
+	})
+	return result
+}
+
+// UseCondition is the `use` test of the keywords the release file is only used on.
+func (lr *LlamafileResource) UseCondition() string {
+	conditions := make([]string, 0, len(lr.Keywords))
+	for _, keyword := range lr.Keywords {
+		conditions = append(conditions, "use "+keyword)
+	}
+	return strings.Join(conditions, " || ")
+}
@@ -0,0 +1,9 @@
+package arrans_overlay_workflow_builder
+
+type GenerateGithubRustSourceTemplateData struct {
+	*GenerateGithubSourceTemplateData
+}
+
+func (ggrstd *GenerateGithubRustSourceTemplateData) TemplateFileName() string {
+	return "github-rust-source.tmpl"
+}
@@ -0,0 +1,55 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type GenerateGithubSourceTemplateData struct {
+	*GenerateGithubWorkflowBase
+}
+
+func (ggstd *GenerateGithubSourceTemplateData) TemplateFileName() string {
+	return "github-source.tmpl"
+}
+
+func (ggstd *GenerateGithubSourceTemplateData) WorkflowName() string {
+	return fmt.Sprintf("%s/%s update", ggstd.Category, ggstd.PackageName())
+}
+
+func (ggstd *GenerateGithubSourceTemplateData) WorkflowFileName() string {
+	return fmt.Sprintf("%s-%s-update.yaml", ggstd.Category, ggstd.PackageName())
+}
+
+func (ggstd *GenerateGithubSourceTemplateData) PackageName() string {
+	return strings.TrimSuffix(ggstd.EbuildName, ".ebuild")
+}
+
+// Inherit is the eclass for the build system, the default phases of `make` don't need one.
+func (ggstd *GenerateGithubSourceTemplateData) Inherit() string {
+	if ggstd.BuildSystem == "make" {
+		return ""
+	}
+	return ggstd.BuildSystem
+}
+
+// SourceArchiveUrl is SourceUrl with ${VERSION} and ${TAG} replaced with the workflow's shell variables, or the archive
+// GitHub makes of the tag if it isn't set.
+func (ggstd *GenerateGithubSourceTemplateData) SourceArchiveUrl() string {
+	if ggstd.SourceUrl == "" {
+		return "https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/archive/refs/tags/${tag}.tar.gz"
+	}
+	return strings.NewReplacer("${VERSION}", "${version}", "${TAG}", "${tag}").Replace(ggstd.SourceUrl)
+}
+
+// RuntimeDependencies are the Dependencies of every program, they are added to DEPEND for RDEPEND.
+func (ggstd *GenerateGithubSourceTemplateData) RuntimeDependencies() []string {
+	deps := make([]string, 0)
+	for programName := range ggstd.Programs {
+		deps = append(deps, ggstd.Programs[programName].Dependencies...)
+	}
+	sort.Strings(deps)
+	return slices.CompactFunc(deps, strings.EqualFold)
+}
@@ -5,6 +5,7 @@ import (
 	"embed"
 	"fmt"
 	"github.com/stoewer/go-strcase"
+	"html"
 	"io/fs"
 	"log"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"strings"
 	"text/template"
 	"time"
+	"unicode"
 )
 
 var (
@@ -28,22 +30,27 @@ type ExternalResource struct {
 }
 
 func GenerateGithubWorkflows(file, outputDir, version string) error {
-	b, err := os.ReadFile(file)
-	if err != nil {
-		return fmt.Errorf("reading %s: %w", file, err)
-	}
-	inputConfigs, err := ParseInputConfigReader(bytes.NewReader(b))
+	inputConfigs, err := ReadConfigurationTree(file)
 	if err != nil {
 		return fmt.Errorf("parsing %s: %w", file, err)
 	}
 	return GenerateGithubWorkflowsFromInputConfigs(file, inputConfigs, outputDir, version)
 }
 
+// GenerateGithubWorkflowsFromDir generates the workflows for every `.config` file in the directory.
+func GenerateGithubWorkflowsFromDir(dir, outputDir, version string) error {
+	inputConfigs, err := ReadConfigurationDir(dir)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", dir, err)
+	}
+	return GenerateGithubWorkflowsFromInputConfigs(dir, inputConfigs, outputDir, version)
+}
+
 func GenerateGithubWorkflowsFromInputConfigs(file string, inputConfigs []*InputConfig, outputDir, version string) error {
 	missing := false
 	for _, inputConfig := range inputConfigs {
 		if inputConfig.Category == "" {
-			log.Printf("%s needs a category", inputConfig.EbuildName)
+			log.Printf("%s needs a category", inputConfig.Location())
 			missing = true
 		}
 	}
@@ -79,6 +86,18 @@ func ParseWorkflowTemplates() (*template.Template, error) {
 				})
 			},
 			"quoteStr": strconv.Quote,
+			"assetUrlVariable": func(releaseFilename string) string {
+				return "asset_url_" + strings.Map(func(r rune) rune {
+					if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+						return r
+					}
+					return '_'
+				}, releaseFilename)
+			},
+			"xmlescaped": html.EscapeString,
+			"shellsinglequoted": func(s string) string {
+				return strings.ReplaceAll(s, "'", `'\''`)
+			},
 			"actionvardoublequoted": func(s string) string {
 				return os.Expand(s, func(s string) string {
 					switch s {
@@ -142,14 +161,61 @@ func ParseWorkflowTemplates() (*template.Template, error) {
 
 type GenerateGithubWorkflowBase struct {
 	*InputConfig
-	Version    string
-	Now        time.Time
+	Version string
+	Now     time.Time
+	// ConfigFile is the file the entry was read from.
 	ConfigFile string
 }
 
+// ReleaseDownloadBaseUrl is the repository's URL in the workflow, release files are under its
+// `/releases/download/<tag>/`. GitLab entries don't use it as their release files can be anywhere.
+func (ggwb *GenerateGithubWorkflowBase) ReleaseDownloadBaseUrl() string {
+	if ggwb.IsGitea() {
+		return "${{ env.gitea_url }}/${{ env.gitea_owner }}/${{ env.gitea_repo }}"
+	}
+	return "https://github.com/${{ env.github_owner }}/${{ env.github_repo }}"
+}
+
+// ReleaseFileBaseUrl is the URL the release files are under in the workflow, the entry's ReleaseUrl or otherwise the
+// release of the tag. ${VERSION} is the version as it is in the tag.
+func (ggwb *GenerateGithubWorkflowBase) ReleaseFileBaseUrl() string {
+	if ggwb.ReleaseUrl != "" {
+		return strings.TrimSuffix(strings.NewReplacer("${VERSION}", "${originalVersion}", "${TAG}", "${tag}").Replace(ggwb.ReleaseUrl), "/")
+	}
+	return ggwb.ReleaseDownloadBaseUrl() + "/releases/download/${tag}"
+}
+
+// EbuildVariable returns the entry's value for the ebuild variable, or the value the template uses if it doesn't set one.
+func (ggwb *GenerateGithubWorkflowBase) EbuildVariable(name, templateValue string) string {
+	if value, ok := ggwb.EbuildVariables[name]; ok {
+		return value
+	}
+	return templateValue
+}
+
+// EbuildLicense is the LICENSE of the ebuild, the entry's LICENSE ebuild variable, otherwise the Gentoo name of its
+// License if it is known, otherwise MIT.
+func (ggwb *GenerateGithubWorkflowBase) EbuildLicense() string {
+	if license := GentooLicense(ggwb.License); license != "" {
+		return ggwb.EbuildVariable("LICENSE", license)
+	}
+	return ggwb.EbuildVariable("LICENSE", "MIT")
+}
+
+// ExtraEbuildVariables returns the entry's ebuild variables other than the ones the template writes itself.
+func (ggwb *GenerateGithubWorkflowBase) ExtraEbuildVariables(written ...string) map[string]string {
+	result := map[string]string{}
+	for name, value := range ggwb.EbuildVariables {
+		if !slices.Contains(written, name) {
+			result[name] = value
+		}
+	}
+	return result
+}
+
 func (ic *InputConfig) GenerateGithubWorkflow(file string, now time.Time, templates *template.Template, outputDir, version string) error {
 	if err := ic.Validate(); err != nil {
-		return fmt.Errorf("for %s validating config: %w", ic.EbuildName, err)
+		return fmt.Errorf("for %s validating config: %w", ic.Location(), err)
 	}
 	out := bytes.NewBuffer(nil)
 	var workflowName string
@@ -157,6 +223,9 @@ func (ic *InputConfig) GenerateGithubWorkflow(file string, now time.Time, templa
 		WorkflowFileName() string
 		TemplateFileName() string
 	}
+	if ic.SourceFile != "" {
+		file = ic.SourceFile
+	}
 	base := &GenerateGithubWorkflowBase{
 		Version:     version,
 		Now:         now,
@@ -164,14 +233,34 @@ func (ic *InputConfig) GenerateGithubWorkflow(file string, now time.Time, templa
 		InputConfig: ic,
 	}
 	switch ic.Type {
-	case "Github AppImage Release":
+	case "Github AppImage Release", "GitLab AppImage Release", "Gitea AppImage Release":
 		data = &GenerateGithubAppImageTemplateData{
 			GenerateGithubWorkflowBase: base,
 		}
-	case "Github Binary Release":
+	case "Github Binary Release", "GitLab Binary Release", "Gitea Binary Release":
 		data = &GenerateGithubBinaryTemplateData{
 			GenerateGithubWorkflowBase: base,
 		}
+	case "Github Llamafile Release":
+		data = &GenerateGithubLlamafileTemplateData{
+			GenerateGithubWorkflowBase: base,
+		}
+	case "Github Source Release":
+		data = &GenerateGithubSourceTemplateData{
+			GenerateGithubWorkflowBase: base,
+		}
+	case "Github Go Source Release":
+		data = &GenerateGithubGoSourceTemplateData{
+			GenerateGithubSourceTemplateData: &GenerateGithubSourceTemplateData{
+				GenerateGithubWorkflowBase: base,
+			},
+		}
+	case "Github Rust Source Release":
+		data = &GenerateGithubRustSourceTemplateData{
+			GenerateGithubSourceTemplateData: &GenerateGithubSourceTemplateData{
+				GenerateGithubWorkflowBase: base,
+			},
+		}
 	default:
 		return fmt.Errorf("unknown type %s", ic.Type)
 	}
@@ -184,12 +273,32 @@ func (ic *InputConfig) GenerateGithubWorkflow(file string, now time.Time, templa
 		return fmt.Errorf("writing %s: %w", n, err)
 	}
 	fmt.Printf("Written: %s\n", n)
+	if ic.LiveEbuildBuildSystem() != "" {
+		return ic.generateGithubLiveWorkflow(base, templates, outputDir)
+	}
+	return nil
+}
+
+// generateGithubLiveWorkflow writes the workflow which creates the entry's live ebuild.
+func (ic *InputConfig) generateGithubLiveWorkflow(base *GenerateGithubWorkflowBase, templates *template.Template, outputDir string) error {
+	out := bytes.NewBuffer(nil)
+	data := &GenerateGithubLiveTemplateData{
+		GenerateGithubWorkflowBase: base,
+	}
+	if err := templates.ExecuteTemplate(out, data.TemplateFileName(), data); err != nil {
+		return fmt.Errorf("for %s excuting live template: %w", ic.EbuildName, err)
+	}
+	n := filepath.Join(outputDir, data.WorkflowFileName())
+	if err := os.WriteFile(n, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", n, err)
+	}
+	fmt.Printf("Written: %s\n", n)
 	return nil
 }
 
 func (ic *InputConfig) Cron() string {
 	i := uint64(0)
-	for _, r := range ic.GithubRepo {
+	for _, r := range ic.RepoName() {
 		i += uint64(r)
 	}
 	minute := i % 60
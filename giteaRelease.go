@@ -0,0 +1,23 @@
+package arrans_overlay_workflow_builder
+
+// GenerateBinaryGiteaReleaseConfigEntry detects a "Gitea Binary Release" entry, Gitea's API is compatible enough with
+// GitHub's for the repository and its releases that the GitHub detection is used against the instance in the URL.
+func GenerateBinaryGiteaReleaseConfigEntry(giteaRepo, tagOverride, prefix string) (*InputConfig, error) {
+	return generateBinaryRepoReleaseConfigEntry(giteaRepo, tagOverride, prefix, "Gitea Binary Release")
+}
+
+// GenerateAppImageGiteaReleaseConfigEntry detects a "Gitea AppImage Release" entry the same way.
+func GenerateAppImageGiteaReleaseConfigEntry(giteaRepo, tagOverride, tagPrefix string) (*InputConfig, error) {
+	return generateAppImageRepoReleaseConfigEntry(giteaRepo, tagOverride, tagPrefix, "Gitea AppImage Release")
+}
+
+// ConfigAddGiteaReleases detects an entry of the Gitea type from the repository's releases and appends it to the
+// configuration file. This works for Forgejo and Codeberg too.
+func ConfigAddGiteaReleases(toConfig, sourceType, giteaRepo, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("Gitea", toConfig, sourceType, giteaRepo, tagOverride, tagPrefix)
+}
+
+// ConfigViewGiteaReleases shows the entry of the Gitea type which would be added for the repository's releases.
+func ConfigViewGiteaReleases(sourceType, giteaRepo, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("Gitea", sourceType, giteaRepo, tagOverride, tagPrefix)
+}
@@ -0,0 +1,176 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeGiteaServer serves the repository `forgejo/runner` with a v1.2.0 release of a tar.gz for amd64 and arm64 from
+// the GitHub compatible paths of the Gitea API.
+func newFakeGiteaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	archive := newTestBinaryArchive(t, "runner")
+
+	var server *httptest.Server
+	release := func() map[string]any {
+		var assets []map[string]any
+		for _, name := range []string{"runner_1.2.0_linux_amd64.tar.gz", "runner_1.2.0_linux_arm64.tar.gz", "checksums.txt"} {
+			assets = append(assets, map[string]any{"name": name, "browser_download_url": server.URL + "/forgejo/runner/releases/download/v1.2.0/" + name})
+		}
+		return map[string]any{"name": "v1.2.0", "tag_name": "v1.2.0", "assets": assets}
+	}
+	writeJson := func(w http.ResponseWriter, v any) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/forgejo/runner":
+			writeJson(w, map[string]any{
+				"name":        "runner",
+				"full_name":   "forgejo/runner",
+				"description": "A daemon that runs Forgejo Actions",
+				"website":     "",
+				"html_url":    server.URL + "/forgejo/runner",
+			})
+		case "/api/v1/repos/forgejo/runner/releases":
+			writeJson(w, []any{
+				map[string]any{"name": "nightly", "tag_name": "nightly"},
+				release(),
+				map[string]any{"name": "v1.1.0", "tag_name": "v1.1.0"},
+			})
+		case "/api/v1/repos/forgejo/runner/releases/tags/v1.2.0":
+			writeJson(w, release())
+		case "/forgejo/runner/releases/download/v1.2.0/runner_1.2.0_linux_amd64.tar.gz", "/forgejo/runner/releases/download/v1.2.0/runner_1.2.0_linux_arm64.tar.gz":
+			_, _ = w.Write(archive)
+		case "/forgejo/runner/releases/download/v1.2.0/checksums.txt":
+			_, _ = w.Write([]byte("0000  runner_1.2.0_linux_amd64.tar.gz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGenerateBinaryGiteaReleaseConfigEntry(t *testing.T) {
+	server := newFakeGiteaServer(t)
+	for _, tagOverride := range []string{"", "v1.2.0"} {
+		t.Run("tag "+tagOverride, func(t *testing.T) {
+			ic, err := GenerateBinaryGiteaReleaseConfigEntry(server.URL+"/forgejo/runner", tagOverride, "")
+			if err != nil {
+				t.Fatalf("GenerateBinaryGiteaReleaseConfigEntry() error = %v", err)
+			}
+			got := []string{ic.Type, ic.EbuildName, ic.Description, ic.Homepage, ic.GiteaProjectUrl, ic.GiteaBaseUrl, ic.GiteaOwner, ic.GiteaRepo, ic.GithubProjectUrl}
+			want := []string{"Gitea Binary Release", "runner-bin", "A daemon that runs Forgejo Actions", server.URL + "/forgejo/runner", server.URL + "/forgejo/runner", server.URL, "forgejo", "runner", ""}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("entry fields mismatch (-want +got):\n%s", diff)
+			}
+			if len(ic.Programs) == 0 {
+				t.Fatalf("no programs detected")
+			}
+			for name, program := range ic.Programs {
+				if diff := cmp.Diff([]string{"amd64", "arm64"}, slices.Sorted(maps.Keys(program.Binary))); diff != "" {
+					t.Errorf("%s Binary keywords mismatch (-want +got):\n%s", name, diff)
+				}
+			}
+		})
+	}
+	if _, err := GenerateBinaryGiteaReleaseConfigEntry(server.URL+"/forgejo/missing", "", ""); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("GenerateBinaryGiteaReleaseConfigEntry() of a missing repository error = %v", err)
+	}
+}
+
+const testGiteaConfigData = `Type Gitea Binary Release
+Id 5
+GiteaProjectUrl https://codeberg.org/forgejo/runner.git
+Category dev-util
+Binary amd64=>forgejo-runner-${VERSION}-linux-amd64 > forgejo-runner
+
+Type Gitea AppImage Release
+GiteaProjectUrl https://git.example.org/tools/viewer/
+Category media-gfx
+Binary amd64=>viewer-${VERSION}-x86_64.AppImage > viewer
+`
+
+func TestParseGiteaInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGiteaConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got [][]string
+	for _, ic := range ics {
+		got = append(got, []string{ic.EbuildName, ic.RepoName(), ic.GiteaBaseUrl, ic.GiteaOwner, ic.GiteaRepo})
+	}
+	want := [][]string{
+		{"runner-bin.ebuild", "runner", "https://codeberg.org", "forgejo", "runner"},
+		{"viewer-appimage.ebuild", "viewer", "https://git.example.org", "tools", "viewer"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parsed entries mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(ics[0].String(), "GiteaProjectUrl https://codeberg.org/forgejo/runner.git\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	for _, config := range []string{
+		"Type Gitea Binary Release\nGithubProjectUrl https://github.com/forgejo/runner\n",
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/forgejo/runner\nGiteaProjectUrl https://codeberg.org/forgejo/runner\n",
+		"Type Gitea Binary Release\nGiteaProjectUrl codeberg.org/forgejo/runner\n",
+	} {
+		if _, err := ParseInputConfigReader(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseInputConfigReader(%q) should fail", config)
+		}
+	}
+}
+
+func TestGenerateGiteaWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGiteaConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, ic := range ics {
+		if err := ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+	}
+	for filename, wants := range map[string][]string{
+		"dev-util-runner-bin-update.yaml": {
+			"  gitea_url: https://codeberg.org\n  gitea_owner: forgejo\n  gitea_repo: runner\n",
+			`tags=$(curl -s "${{ env.gitea_url }}/api/v1/repos/${{ env.gitea_owner }}/${{ env.gitea_repo }}/releases?limit=50" | jq -r '.[].tag_name')`,
+			`${{ env.gitea_url }}/${{ env.gitea_owner }}/${{ env.gitea_repo }}/releases/download/${tag}/forgejo-runner-\${PV}-linux-amd64 -> \${P}-forgejo-runner-\${PV}-linux-amd64`,
+			`g2 manifest upsert-from-url "${{ env.gitea_url }}/${{ env.gitea_owner }}/${{ env.gitea_repo }}/releases/download/${tag}/forgejo-runner-${version}-linux-amd64"`,
+		},
+		"media-gfx-viewer-appimage-update.yaml": {
+			"  gitea_url: https://git.example.org\n",
+			`( ${{ env.gitea_url }}/${{ env.gitea_owner }}/${{ env.gitea_repo }}/releases/download/${tag}/viewer-\${PV}-x86_64.AppImage -> \${P}-viewer-\${PV}-x86_64.AppImage )`,
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, filename))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", filename, want)
+			}
+		}
+		if strings.Contains(string(b), "github_owner") {
+			t.Errorf("%s refers to a github repo", filename)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"github.com/stoewer/go-strcase"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GitlabClient is the little of the GitLab REST API needed to read a project's releases, it works with gitlab.com and
+// self-hosted instances.
+type GitlabClient struct {
+	// BaseUrl is the instance such as https://gitlab.com
+	BaseUrl string
+	// Token is sent as the `PRIVATE-TOKEN` if it is set.
+	Token      string
+	HttpClient *http.Client
+}
+
+// NewGitlabClient creates a client for the GitLab instance, the token is read from `GITLAB_TOKEN` if it is set.
+func NewGitlabClient(baseUrl string) *GitlabClient {
+	return &GitlabClient{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		Token:      os.Getenv("GITLAB_TOKEN"),
+		HttpClient: http.DefaultClient,
+	}
+}
+
+type GitlabLicense struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Nickname string `json:"nickname"`
+}
+
+type GitlabProject struct {
+	Name              string         `json:"name"`
+	Path              string         `json:"path"`
+	PathWithNamespace string         `json:"path_with_namespace"`
+	Description       string         `json:"description"`
+	WebUrl            string         `json:"web_url"`
+	License           *GitlabLicense `json:"license"`
+}
+
+// GitlabReleaseLink is an asset link of a release, these are the release files.
+type GitlabReleaseLink struct {
+	Name           string `json:"name"`
+	Url            string `json:"url"`
+	DirectAssetUrl string `json:"direct_asset_url"`
+	LinkType       string `json:"link_type"`
+}
+
+// DownloadUrl prefers the permanent direct asset URL over the URL the link points to.
+func (grl *GitlabReleaseLink) DownloadUrl() string {
+	if grl.DirectAssetUrl != "" {
+		return grl.DirectAssetUrl
+	}
+	return grl.Url
+}
+
+type GitlabRelease struct {
+	Name    string `json:"name"`
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []*GitlabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+func (gc *GitlabClient) get(ctx context.Context, apiPath string, v any) error {
+	_, err := gc.getPage(ctx, apiPath, v)
+	return err
+}
+
+// getPage is get for a paginated list, it returns the `X-Next-Page` header which is empty on the last page.
+func (gc *GitlabClient) getPage(ctx context.Context, apiPath string, v any) (string, error) {
+	u := gc.BaseUrl + "/api/v4/" + apiPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	if gc.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", gc.Token)
+	}
+	resp, err := gc.HttpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing %s: %s", u, err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("decoding %s: %w", u, err)
+	}
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+func gitlabProjectId(projectPath string) string {
+	return url.PathEscape(projectPath)
+}
+
+// GetProject returns the project, including its license.
+func (gc *GitlabClient) GetProject(ctx context.Context, projectPath string) (*GitlabProject, error) {
+	var project GitlabProject
+	if err := gc.get(ctx, "projects/"+gitlabProjectId(projectPath)+"?license=true", &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// ListReleases returns every release of the project, newest first, following the pages until `X-Next-Page` is empty.
+func (gc *GitlabClient) ListReleases(ctx context.Context, projectPath string) ([]*GitlabRelease, error) {
+	var releases []*GitlabRelease
+	for page := "1"; page != ""; {
+		var pageReleases []*GitlabRelease
+		nextPage, err := gc.getPage(ctx, "projects/"+gitlabProjectId(projectPath)+"/releases?per_page=100&page="+url.QueryEscape(page), &pageReleases)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, pageReleases...)
+		page = nextPage
+	}
+	return releases, nil
+}
+
+// GetLatestRelease returns the project's latest release.
+func (gc *GitlabClient) GetLatestRelease(ctx context.Context, projectPath string) (*GitlabRelease, error) {
+	var release GitlabRelease
+	if err := gc.get(ctx, "projects/"+gitlabProjectId(projectPath)+"/releases/permalink/latest", &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// GetReleaseByTag returns the release for the tag.
+func (gc *GitlabClient) GetReleaseByTag(ctx context.Context, projectPath, tag string) (*GitlabRelease, error) {
+	var release GitlabRelease
+	if err := gc.get(ctx, "projects/"+gitlabProjectId(projectPath)+"/releases/"+url.PathEscape(tag), &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// NewInputConfigurationFromGitlab is NewInputConfigurationFromRepo for a project on a GitLab instance.
+func NewInputConfigurationFromGitlab(projectUrl, tagOverride, tagPrefix, ebuildSuffix, sourceType string) (string, *InputConfig, []string, []string, *GitlabRelease, error) {
+	baseUrl, projectPath, err := util.ExtractGitlabProject(projectUrl)
+	if err != nil {
+		return "", nil, nil, nil, nil, fmt.Errorf("gitlab url parse: %w", err)
+	}
+	client := NewGitlabClient(baseUrl)
+	log.Printf("Getting details for %s on %s", projectPath, baseUrl)
+	ctx := context.Background()
+	project, err := client.GetProject(ctx, projectPath)
+	if err != nil {
+		return "", nil, nil, nil, nil, fmt.Errorf("gitlab project fetch: %w", err)
+	}
+	licenseName := DefaultLicense
+	if project.License != nil && project.License.Name != "" {
+		licenseName = project.License.Name
+		// The key is the lower case SPDX identifier, which maps to the Gentoo license name written to LICENSE
+		if license, ok := gentooLicenseMap[strings.ToLower(project.License.Key)]; ok {
+			licenseName = license
+		}
+	}
+	description := project.Description
+	if description == "" {
+		description = "TODO"
+	}
+	ic := &InputConfig{
+		Type:              sourceType,
+		GitlabProjectUrl:  projectUrl,
+		GitlabBaseUrl:     baseUrl,
+		GitlabProjectPath: projectPath,
+		Description:       description,
+		Homepage:          project.WebUrl,
+		Workarounds:       map[string]string{},
+		Programs:          map[string]*Program{},
+		License:           licenseName,
+	}
+	repoName := ic.RepoName()
+	ic.EbuildName = strcase.KebabCase(fmt.Sprintf("%s%s", strings.ReplaceAll(repoName, ".", "-"), ebuildSuffix))
+	var versions = []string{}
+	var tags = []string{}
+	var releaseInfo *GitlabRelease
+	if tagOverride == "" {
+		releasesList, err := client.ListReleases(ctx, projectPath)
+		if err != nil {
+			return "", nil, nil, nil, nil, fmt.Errorf("gitlab list releases fetch: %w", err)
+		}
+		for _, release := range releasesList {
+			tag := release.TagName
+			if tagPrefix != "" {
+				if !strings.HasPrefix(tag, tagPrefix) {
+					continue
+				}
+				tag = strings.TrimPrefix(tag, tagPrefix)
+			}
+			v, err := semver.NewVersion(tag)
+			if err != nil {
+				continue
+			}
+			if v.Prerelease() != "" {
+				ic.Workarounds[semanticVersionPrereleaseHack1Workaround.Name] = ""
+			}
+			if releaseInfo == nil {
+				releaseInfo = release
+			}
+		}
+		if releaseInfo == nil {
+			releaseInfo, err = client.GetLatestRelease(ctx, projectPath)
+			if err != nil {
+				return "", nil, nil, nil, nil, fmt.Errorf("gitlab latest release fetch: %w", err)
+			}
+		}
+	} else {
+		releaseInfo, err = client.GetReleaseByTag(ctx, projectPath, tagOverride)
+		if err != nil {
+			return "", nil, nil, nil, nil, fmt.Errorf("gitlab release %s fetch: %w", tagOverride, err)
+		}
+	}
+
+	originalTag := releaseInfo.TagName
+	tag := originalTag
+	if tagPrefix != "" {
+		if !strings.HasPrefix(tag, tagPrefix) {
+			return "", nil, nil, nil, nil, fmt.Errorf("gitlab release tag %s doesn't have prefix %s", tag, tagPrefix)
+		}
+		tag = strings.TrimPrefix(tag, tagPrefix)
+		ic.Workarounds[tagPrefixWorkaround.Name] = tagPrefix
+	}
+	v, err := semver.NewVersion(tag)
+	if err != nil {
+		return "", nil, nil, nil, nil, fmt.Errorf("gitlab release tag parse %s: %w", tag, err)
+	}
+	if v.Prerelease() != "" {
+		ic.Workarounds[semanticVersionPrereleaseHack1Workaround.Name] = ""
+	}
+	tags = append(tags, originalTag)
+	if strings.HasPrefix(tag, "v") {
+		versions = append(versions, v.String())
+	} else {
+		ic.Workarounds[semanticVersionWithoutVWorkaround.Name] = ""
+	}
+
+	log.Printf("Latest release %s", originalTag)
+	return repoName, ic, versions, tags, releaseInfo, nil
+}
+
+func GenerateBinaryGitlabReleaseConfigEntry(projectUrl, tagOverride, prefix string) (*InputConfig, error) {
+	repoName, ic, versions, tags, releaseInfo, err := NewInputConfigurationFromGitlab(projectUrl, tagOverride, prefix, "-bin", "GitLab Binary Release")
+	if err != nil {
+		return nil, err
+	}
+	var files []*BinaryReleaseFileInfo
+	for _, link := range releaseInfo.Assets.Links {
+		files = append(files, &BinaryReleaseFileInfo{
+			Filename:    link.Name,
+			DownloadUrl: link.DownloadUrl(),
+		})
+	}
+	return detectBinaryReleaseConfigEntry(repoName, ic, versions, tags, files)
+}
+
+func GenerateAppImageGitlabReleaseConfigEntry(projectUrl, tagOverride, prefix string) (*InputConfig, error) {
+	repoName, ic, versions, tags, releaseInfo, err := NewInputConfigurationFromGitlab(projectUrl, tagOverride, prefix, "-appimage", "GitLab AppImage Release")
+	if err != nil {
+		return nil, err
+	}
+	var files []*AppImageFileInfo
+	for _, link := range releaseInfo.Assets.Links {
+		files = append(files, &AppImageFileInfo{
+			Filename:    link.Name,
+			DownloadUrl: link.DownloadUrl(),
+		})
+	}
+	return detectAppImageReleaseConfigEntry(repoName, ic, versions, tags, files)
+}
+
+// ConfigAddGitlabReleases detects an entry of the GitLab type from the project's releases and appends it to the
+// configuration file.
+func ConfigAddGitlabReleases(toConfig, sourceType, projectUrl, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("GitLab", toConfig, sourceType, projectUrl, tagOverride, tagPrefix)
+}
+
+// ConfigViewGitlabReleases shows the entry of the GitLab type which would be added for the project's releases.
+func ConfigViewGitlabReleases(sourceType, projectUrl, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("GitLab", sourceType, projectUrl, tagOverride, tagPrefix)
+}
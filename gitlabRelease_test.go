@@ -0,0 +1,224 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBinaryArchive returns a tar.gz of the test binary named name, it is an ELF file with known dependencies.
+func newTestBinaryArchive(t *testing.T, name string) []byte {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	binary, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var archive bytes.Buffer
+	gw, _ := gzip.NewWriterLevel(&archive, gzip.BestSpeed)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar WriteHeader() error = %v", err)
+	}
+	if _, err := tw.Write(binary); err != nil {
+		t.Fatalf("tar Write() error = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+	return archive.Bytes()
+}
+
+// newFakeGitlabServer serves the project `tools/cli/glab` with a v1.2.0 release of a tar.gz for amd64 and arm64 on the
+// second page of releases. Only the amd64 link has a direct asset URL, its other URL doesn't work.
+func newFakeGitlabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	archive := newTestBinaryArchive(t, "glab")
+
+	var server *httptest.Server
+	release := func() map[string]any {
+		return map[string]any{
+			"name":     "v1.2.0",
+			"tag_name": "v1.2.0",
+			"assets": map[string]any{
+				"links": []map[string]string{
+					{"name": "glab_1.2.0_linux_amd64.tar.gz", "url": server.URL + "/uploads/1a2b/missing.tar.gz", "direct_asset_url": server.URL + "/tools/cli/glab/-/releases/v1.2.0/downloads/glab_1.2.0_linux_amd64.tar.gz"},
+					{"name": "glab_1.2.0_linux_arm64.tar.gz", "url": server.URL + "/uploads/3c4d/glab_1.2.0_linux_arm64.tar.gz"},
+					{"name": "checksums.txt", "url": server.URL + "/uploads/5e6f/checksums.txt"},
+				},
+			},
+		}
+	}
+	writeJson := func(w http.ResponseWriter, v any) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/tools%2Fcli%2Fglab":
+			writeJson(w, map[string]any{
+				"name":                "glab",
+				"path":                "glab",
+				"path_with_namespace": "tools/cli/glab",
+				"description":         "A GitLab CLI tool",
+				"web_url":             server.URL + "/tools/cli/glab",
+				"license":             map[string]string{"key": "apache-2.0", "name": "Apache License 2.0", "nickname": ""},
+			})
+		case "/api/v4/projects/tools%2Fcli%2Fglab/releases":
+			if r.URL.Query().Get("page") != "2" {
+				w.Header().Set("X-Next-Page", "2")
+				writeJson(w, []any{
+					map[string]any{"name": "nightly", "tag_name": "nightly"},
+				})
+				return
+			}
+			writeJson(w, []any{
+				release(),
+				map[string]any{"name": "v1.1.0", "tag_name": "v1.1.0"},
+			})
+		case "/api/v4/projects/tools%2Fcli%2Fglab/releases/v1.2.0":
+			writeJson(w, release())
+		case "/tools/cli/glab/-/releases/v1.2.0/downloads/glab_1.2.0_linux_amd64.tar.gz", "/uploads/3c4d/glab_1.2.0_linux_arm64.tar.gz":
+			_, _ = w.Write(archive)
+		case "/uploads/5e6f/checksums.txt":
+			_, _ = w.Write([]byte("0000  glab_1.2.0_linux_amd64.tar.gz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGenerateBinaryGitlabReleaseConfigEntry(t *testing.T) {
+	server := newFakeGitlabServer(t)
+	for _, tagOverride := range []string{"", "v1.2.0"} {
+		t.Run("tag "+tagOverride, func(t *testing.T) {
+			ic, err := GenerateBinaryGitlabReleaseConfigEntry(server.URL+"/tools/cli/glab", tagOverride, "")
+			if err != nil {
+				t.Fatalf("GenerateBinaryGitlabReleaseConfigEntry() error = %v", err)
+			}
+			if diff := cmp.Diff([]string{"GitLab Binary Release", "glab-bin", "A GitLab CLI tool", "Apache-2.0", server.URL + "/tools/cli/glab"}, []string{ic.Type, ic.EbuildName, ic.Description, ic.License, ic.Homepage}); diff != "" {
+				t.Errorf("entry fields mismatch (-want +got):\n%s", diff)
+			}
+			program, ok := ic.Programs["glab"]
+			if !ok {
+				t.Fatalf("no glab program in %v", ic.ProgramsString())
+			}
+			want := map[string][]string{
+				"amd64": {"glab_${VERSION}_linux_amd64.tar.gz", "glab", "glab"},
+				"arm64": {"glab_${VERSION}_linux_arm64.tar.gz", "glab", "glab"},
+			}
+			if diff := cmp.Diff(want, program.Binary); diff != "" {
+				t.Errorf("Binary mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := GenerateBinaryGitlabReleaseConfigEntry(server.URL+"/tools/cli/missing", "", ""); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("GenerateBinaryGitlabReleaseConfigEntry() of a missing project error = %v", err)
+	}
+}
+
+const testGitlabConfigData = `Type GitLab Binary Release
+Id 4
+GitlabProjectUrl https://gitlab.example.org/tools/cli/glab/
+Category dev-util
+Binary amd64=>glab_${VERSION}_linux_amd64.tar.gz > bin/glab > glab
+
+Type GitLab AppImage Release
+GitlabProjectUrl https://gitlab.com/inkscape/inkscape
+Category media-gfx
+Workaround Semantic Version Prerelease Hack 1
+Binary amd64=>Inkscape-${VERSION}-x86_64.AppImage > inkscape
+`
+
+func TestParseGitlabInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGitlabConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got [][]string
+	for _, ic := range ics {
+		got = append(got, []string{ic.EbuildName, ic.RepoName(), ic.GitlabBaseUrl, ic.GitlabProjectPath, ic.GitlabApiProjectUrl()})
+	}
+	want := [][]string{
+		{"glab-bin.ebuild", "glab", "https://gitlab.example.org", "tools/cli/glab", "https://gitlab.example.org/api/v4/projects/tools%2Fcli%2Fglab"},
+		{"inkscape-appimage.ebuild", "inkscape", "https://gitlab.com", "inkscape/inkscape", "https://gitlab.com/api/v4/projects/inkscape%2Finkscape"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parsed entries mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(ics[0].String(), "GitlabProjectUrl https://gitlab.example.org/tools/cli/glab/\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	for _, config := range []string{
+		"Type GitLab Binary Release\nGithubProjectUrl https://github.com/cli/cli\n",
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/cli/cli\nGitlabProjectUrl https://gitlab.com/gitlab-org/cli\n",
+		"Type GitLab Binary Release\nGitlabProjectUrl https://gitlab.com/cli\n",
+	} {
+		if _, err := ParseInputConfigReader(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseInputConfigReader(%q) should fail", config)
+		}
+	}
+}
+
+func TestGenerateGitlabWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGitlabConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, ic := range ics {
+		if err := ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+	}
+	for filename, wants := range map[string][]string{
+		"dev-util-glab-bin-update.yaml": {
+			"  gitlab_api: https://gitlab.example.org/api/v4/projects/tools%2Fcli%2Fglab\n",
+			`releases=$(curl -s "${{ env.gitlab_api }}/releases?per_page=100")`,
+			`assetName="glab_${version}_linux_amd64.tar.gz"`,
+			`asset_url_glab___VERSION__linux_amd64_tar_gz="$(echo "${releases}" | jq -r --arg tag "${tag}" --arg name "${assetName}"`,
+			`echo "  amd64? (  ${asset_url_glab___VERSION__linux_amd64_tar_gz} -> \${P}-glab_\${PV}_linux_amd64.tar.gz  )  "`,
+			`g2 manifest upsert-from-url "${asset_url_glab___VERSION__linux_amd64_tar_gz}" "${{ env.epn }}-${version}-glab_${version}_linux_amd64.tar.gz"`,
+		},
+		"media-gfx-inkscape-appimage-update.yaml": {
+			"  gitlab_project: inkscape/inkscape\n",
+			`assetName="Inkscape-${originalVersion}-x86_64.AppImage"`,
+			`echo "  amd64? ( ${asset_url_Inkscape___VERSION__x86_64_AppImage} -> \${P}-Inkscape-\${PV}-x86_64.AppImage )"`,
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, filename))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", filename, want)
+			}
+		}
+		if strings.Contains(string(b), "github_owner") {
+			t.Errorf("%s refers to a github repo", filename)
+		}
+	}
+}
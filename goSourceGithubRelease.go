@@ -0,0 +1,243 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"gopkg.in/yaml.v3"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// goreleaserVersionTemplate is the goreleaser template for the version without the leading v, the only template
+	// which can be worked out from the ebuild.
+	goreleaserVersionTemplate = regexp.MustCompile(`\{\{-?\s*\.Version\s*-?\}\}`)
+	// goreleaserConfigFilenames are the names goreleaser looks for its configuration under.
+	goreleaserConfigFilenames = []string{".goreleaser.yml", ".goreleaser.yaml", "goreleaser.yml", "goreleaser.yaml"}
+)
+
+// GoSource is what was found in the source archive of a Go module.
+type GoSource struct {
+	// Module is the module path from go.mod.
+	Module string
+	// GoVersion is the `go` directive of go.mod, the oldest Go which can build the module.
+	GoVersion string
+	// Vendored is true if the dependencies are in the vendor directory.
+	Vendored bool
+	// Packages are the `main` of each goreleaser build, empty if there is no goreleaser configuration.
+	Packages []string
+	// Ldflags are the `-X` ldflags of the goreleaser builds with the version template replaced with ${VERSION}.
+	Ldflags string
+}
+
+// goreleaserConfig is the part of a goreleaser configuration file which says how the programs are built.
+type goreleaserConfig struct {
+	Builds []struct {
+		Main    string               `yaml:"main"`
+		Ldflags goreleaserStringList `yaml:"ldflags"`
+		Skip    any                  `yaml:"skip"`
+	} `yaml:"builds"`
+}
+
+// goreleaserStringList is a goreleaser field which can be either a string or a list of strings.
+type goreleaserStringList []string
+
+func (gsl *goreleaserStringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*gsl = []string{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*gsl = list
+	return nil
+}
+
+func ConfigAddGoSourceGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("Github", toConfig, "Github Go Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+func ConfigViewGoSourceGithubReleases(gitRepo, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("Github", "Github Go Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+// GenerateGoSourceGithubReleaseConfigEntry reads go.mod and the goreleaser configuration from the source archive GitHub
+// makes of the release's tag. The go.sum is read by the workflow for each version.
+func GenerateGoSourceGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string) (*InputConfig, error) {
+	_, ic, _, _, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "", "Github Go Source Release")
+	if err != nil {
+		return config, err
+	}
+	archiveUrl := fmt.Sprintf("https://github.com/%s/%s/archive/refs/tags/%s.tar.gz", ic.GithubOwner, ic.GithubRepo, releaseInfo.GetTagName())
+	log.Printf("Downloading %s", archiveUrl)
+	tempFile, err := util.DownloadUrlToTempFile(archiveUrl)
+	if err != nil {
+		return nil, fmt.Errorf("downloading source archive: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tempFile); err != nil {
+			log.Printf("Error removing temp file: %s", err)
+		}
+	}()
+	f, err := os.Open(tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening source archive: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Printf("Error closing file: %s: %s", tempFile, err)
+		}
+	}()
+	goSource, err := DetectGoSource(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archiveUrl, err)
+	}
+	log.Printf("Go module %s", goSource.Module)
+	goSource.Apply(ic)
+	return ic, nil
+}
+
+// Apply sets the Go source fields of the entry to what was found in the source.
+func (gs *GoSource) Apply(ic *InputConfig) {
+	if gs.Vendored {
+		ic.GoDependencies = "vendor"
+	}
+	if gs.GoVersion != "" {
+		ic.BDepend = append(ic.BDepend, ">=dev-lang/go-"+gs.GoVersion)
+	}
+	if !slices.Equal(gs.Packages, []string{"."}) {
+		ic.GoPackages = gs.Packages
+	}
+	ic.GoLdflags = gs.Ldflags
+}
+
+// DetectGoSource reads go.mod, the vendor directory and the goreleaser configuration from the top directory of the
+// tar.gz of a Go module's source.
+func DetectGoSource(r io.Reader) (*GoSource, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip file: %w", err)
+	}
+	tr := tar.NewReader(gr)
+	result := &GoSource{}
+	var goMod, goreleaser []byte
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading next tar file: %w", err)
+		}
+		if header.FileInfo().IsDir() {
+			continue
+		}
+		// GitHub puts everything in a `repo-version` directory
+		name := strings.TrimPrefix(header.Name, "./")
+		if _, rest, found := strings.Cut(name, "/"); found {
+			name = rest
+		}
+		switch {
+		case name == "go.mod":
+			if goMod, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+			}
+		case name == "vendor/modules.txt":
+			result.Vendored = true
+		case slices.Contains(goreleaserConfigFilenames, name) && goreleaser == nil:
+			if goreleaser, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+			}
+		}
+	}
+	if goMod == nil {
+		return nil, fmt.Errorf("no go.mod found")
+	}
+	result.Module, result.GoVersion = parseGoMod(goMod)
+	if result.Module == "" {
+		return nil, fmt.Errorf("go.mod has no module directive")
+	}
+	if goreleaser != nil {
+		config := &goreleaserConfig{}
+		if err := yaml.Unmarshal(goreleaser, config); err != nil {
+			return nil, fmt.Errorf("parsing goreleaser configuration: %w", err)
+		}
+		var ldflags []string
+		for _, build := range config.Builds {
+			if skip, ok := build.Skip.(bool); ok && skip {
+				continue
+			}
+			main := build.Main
+			if main == "" {
+				main = "."
+			}
+			if !slices.Contains(result.Packages, main) {
+				result.Packages = append(result.Packages, main)
+			}
+			for _, flag := range goreleaserLdflags(build.Ldflags) {
+				if !slices.Contains(ldflags, flag) {
+					ldflags = append(ldflags, flag)
+				}
+			}
+		}
+		result.Ldflags = strings.Join(ldflags, " ")
+	}
+	return result, nil
+}
+
+// parseGoMod returns the module path and `go` directive of a go.mod file.
+func parseGoMod(b []byte) (module string, goVersion string) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = strings.Trim(fields[1], "\"`")
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	return module, goVersion
+}
+
+// goreleaserLdflags returns the `-X` flags which only use the version template, with it replaced by ${VERSION}. The
+// others, such as the commit or date, can't be worked out from the ebuild and `-s -w` are left to portage.
+func goreleaserLdflags(ldflags []string) []string {
+	var result []string
+	var fields []string
+	for _, ldflag := range ldflags {
+		// Replaced before splitting as the templates can have spaces in them
+		ldflag = goreleaserVersionTemplate.ReplaceAllLiteralString(ldflag, "${VERSION}")
+		fields = append(fields, strings.Fields(ldflag)...)
+	}
+	for i := 0; i < len(fields); i++ {
+		value, found := strings.CutPrefix(fields[i], "-X=")
+		if !found {
+			if fields[i] != "-X" || i+1 >= len(fields) {
+				continue
+			}
+			i++
+			value = fields[i]
+		}
+		if strings.Contains(value, "{{") {
+			continue
+		}
+		result = append(result, "-X "+value)
+	}
+	return result
+}
@@ -0,0 +1,238 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/google/go-cmp/cmp"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestArchiveFromDir is a tar.gz of the fixture directory laid out the way GitHub makes source archives, with the
+// files in a directory named after the fixture.
+func newTestArchiveFromDir(t *testing.T, dir string) *bytes.Buffer {
+	t.Helper()
+	var archive bytes.Buffer
+	gw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gw)
+	parent := filepath.Dir(dir)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(parent, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("archiving %s: %v", dir, err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+	return &archive
+}
+
+func TestDetectGoSource(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		dir     string
+		want    *GoSource
+		wantErr bool
+	}{
+		{
+			name: "goreleaser",
+			dir:  "testdata/go-source/g2-1.2.0",
+			want: &GoSource{
+				Module:    "github.com/arran4/g2",
+				GoVersion: "1.22",
+				Packages:  []string{"./cmd/g2"},
+				Ldflags:   "-X main.version=${VERSION}",
+			},
+		},
+		{
+			name: "vendored",
+			dir:  "testdata/go-source/tool-0.3.1",
+			want: &GoSource{
+				Module:    "example.com/tool",
+				GoVersion: "1.21.5",
+				Vendored:  true,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DetectGoSource(newTestArchiveFromDir(t, test.dir))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DetectGoSource() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("DetectGoSource() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := DetectGoSource(newTestSourceArchive(t, "Makefile", "main.c")); err == nil {
+		t.Errorf("DetectGoSource() without a go.mod should fail")
+	}
+}
+
+func TestGoreleaserLdflags(t *testing.T) {
+	got := goreleaserLdflags([]string{"-s -w", "-X=main.Version={{.Version}}", "-X main.tag=v{{- .Version -}}", "-X main.commit={{.ShortCommit}}", "-extldflags -static", "-X"})
+	want := []string{"-X main.Version=${VERSION}", "-X main.tag=v${VERSION}"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("goreleaserLdflags() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGoSourceApply(t *testing.T) {
+	ic := &InputConfig{Type: "Github Go Source Release"}
+	(&GoSource{Module: "example.com/tool", GoVersion: "1.21.5", Vendored: true, Packages: []string{"."}}).Apply(ic)
+	if !strings.Contains(ic.String(), "BDepend >=dev-lang/go-1.21.5\nGoDependencies vendor\n") || strings.Contains(ic.String(), "GoPackages") {
+		t.Errorf("String() = %s", ic.String())
+	}
+}
+
+const testGoSourceConfigData = `Type Github Go Source Release
+GithubProjectUrl https://github.com/arran4/g2
+Category dev-util
+BDepend >=dev-lang/go-1.22
+GoPackages ./cmd/g2
+GoLdflags -X main.version=${VERSION} -X main.builtBy=gentoo
+EbuildVariable LICENSE => BSD
+
+Type Github Go Source Release
+GithubProjectUrl https://github.com/example/tool
+GoDependencies vendor
+
+Type Github Go Source Release
+GithubProjectUrl https://github.com/example/big
+Category app-misc
+GoDependencies tarball
+GoDependencyTarball https://example.org/distfiles/big-${VERSION}-deps.tar.xz
+`
+
+func TestParseGoSourceInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGoSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got [][]string
+	for _, ic := range ics {
+		got = append(got, []string{ic.EbuildName, ic.GoDependencyMode(), ic.GoDependencyTarball, strings.Join(ic.GoPackages, " "), ic.GoLdflags})
+	}
+	want := [][]string{
+		{"g2.ebuild", "ego-sum", "", "./cmd/g2", "-X main.version=${VERSION} -X main.builtBy=gentoo"},
+		{"tool.ebuild", "vendor", "", "", ""},
+		{"big.ebuild", "tarball", "https://example.org/distfiles/big-${VERSION}-deps.tar.xz", "", ""},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parsed entries mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(ics[0].String(), "BDepend >=dev-lang/go-1.22\nGoPackages ./cmd/g2\nGoLdflags -X main.version=${VERSION} -X main.builtBy=gentoo\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	for _, config := range []string{
+		"Type Github Go Source Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem make\n",
+		"Type Github Go Source Release\nGithubProjectUrl https://github.com/example/tool\nGoDependencies modcache\n",
+		"Type Github Go Source Release\nGithubProjectUrl https://github.com/example/tool\nGoDependencies tarball\n",
+		"Type Github Go Source Release\nGithubProjectUrl https://github.com/example/tool\nGoDependencyTarball https://example.org/deps.tar.xz\n",
+		"Type Github Source Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem make\nGoPackages ./cmd/tool\n",
+	} {
+		if _, err := ParseInputConfigReader(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseInputConfigReader(%q) should fail", config)
+		}
+	}
+}
+
+func TestGenerateGithubGoSourceWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testGoSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, ic := range ics {
+		if err := ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+	}
+	for filename, test := range map[string]struct {
+		wants    []string
+		notWants []string
+	}{
+		"dev-util-g2-update.yaml": {
+			wants: []string{
+				`echo 'inherit go-module'`,
+				`ego_sum="$(tar -xOf "${source_archive}" "${source_dir}/go.sum" | awk '{ print $1 " " $2 }' | sort -u)"`,
+				`echo 'go-module_set_globals'`,
+				`echo 'SRC_URI+=" ${EGO_SUM_SRC_URI}"'`,
+				`echo 'LICENSE="BSD"'`,
+				`echo 'BDEPEND=">=dev-lang/go-1.22"'`,
+				`echo '  ego build -ldflags "-X main.version=${PV} -X main.builtBy=gentoo" -o bin/ ./cmd/g2'`,
+				`g2 manifest upsert-from-url "https://proxy.golang.org/${escaped}/@v/${file}" "${escaped//\//%2F}%2F@v%2F${file}" "${ebuild_dir}/Manifest"`,
+			},
+		},
+		"app-misc-tool-update.yaml": {
+			wants:    []string{`echo '  ego build -o bin/ .'`},
+			notWants: []string{"EGO_SUM", "deps_url", "proxy.golang.org"},
+		},
+		"app-misc-big-update.yaml": {
+			wants: []string{
+				`deps_url="https://example.org/distfiles/big-${version}-deps.tar.xz"`,
+				`echo "SRC_URI+=\" ${deps_url}\""`,
+				`g2 manifest upsert-from-url "${deps_url}" "${deps_url##*/}" "${ebuild_dir}/Manifest"`,
+			},
+			notWants: []string{"EGO_SUM"},
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, filename))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range test.wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", filename, want)
+			}
+		}
+		for _, notWant := range test.notWants {
+			if strings.Contains(string(b), notWant) {
+				t.Errorf("%s contains %q", filename, notWant)
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package arrans_overlay_workflow_builder
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -11,8 +10,12 @@ import (
 	"github.com/stoewer/go-strcase"
 	"io"
 	"log"
+	"maps"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"unicode"
@@ -20,17 +23,90 @@ import (
 
 var (
 	DefaultDesktopFileEnabled = false
+	// InputConfigTypes are the values `Type` can take.
+	InputConfigTypes = []string{
+		"Github AppImage Release",
+		"Github Binary Release",
+		"GitLab AppImage Release",
+		"GitLab Binary Release",
+		"Gitea AppImage Release",
+		"Gitea Binary Release",
+		"Github Source Release",
+		"Github Go Source Release",
+		"Github Rust Source Release",
+		"Github Llamafile Release",
+	}
+	// SourceBuildSystems are the values `BuildSystem` can take, each is built with the eclass of the same name except
+	// `make` which uses the default phases.
+	SourceBuildSystems = []string{
+		"autotools",
+		"cmake",
+		"meson",
+		"make",
+	}
+	// LiveEbuildBuildSystems are the values the Live Ebuild workaround takes, SourceBuildSystems or the Go and Rust
+	// eclasses by their language's build tool.
+	LiveEbuildBuildSystems = append(slices.Clone(SourceBuildSystems), "go", "cargo")
+	// languageSourceEclasses are the eclasses of the source types which build with the language's eclass instead of a
+	// BuildSystem.
+	languageSourceEclasses = map[string]string{
+		"Github Go Source Release":   "go-module",
+		"Github Rust Source Release": "cargo",
+	}
+	// SourceArchiveExtensions are the extensions SourceUrl can have, tar archives the workflow can list with `tar -tf`.
+	SourceArchiveExtensions = []string{
+		".tar.gz",
+		".tgz",
+		".tar.xz",
+		".tar.bz2",
+		".tar.zst",
+	}
+	// GoDependencyModes are the values `GoDependencies` can take: `ego-sum` lists every module in go.sum as an EGO_SUM
+	// entry, `vendor` uses the vendor directory in the source and `tarball` downloads GoDependencyTarball.
+	GoDependencyModes = []string{
+		"ego-sum",
+		"vendor",
+		"tarball",
+	}
+	// goSourceFields are the entry fields only the Go source type uses.
+	goSourceFields = []string{
+		"GoDependencies",
+		"GoDependencyTarball",
+		"GoPackages",
+		"GoLdflags",
+	}
+	// ProjectUrlFields are the fields an entry's project URL can be in, each type uses one of them.
+	ProjectUrlFields = []string{
+		"GithubProjectUrl",
+		"GitlabProjectUrl",
+		"GiteaProjectUrl",
+	}
 )
 
+// ProjectUrlField returns the field of ProjectUrlFields which entries of the type use.
+func ProjectUrlField(entryType string) string {
+	switch {
+	case strings.HasPrefix(entryType, "GitLab "):
+		return "GitlabProjectUrl"
+	case strings.HasPrefix(entryType, "Gitea "):
+		return "GiteaProjectUrl"
+	}
+	return "GithubProjectUrl"
+}
+
 type Program struct {
-	ProgramName            string
-	Binary                 map[string][]string
-	DesktopFile            string
-	Icons                  []string
-	Documents              map[string][][]string
-	ManualPage             map[string][][]string
-	ShellCompletionScripts map[string]map[string][]string
-	Dependencies           []string
+	ProgramName            string                         `json:"ProgramName,omitempty" yaml:"ProgramName,omitempty"`
+	Description            string                         `json:"Description,omitempty" yaml:"Description,omitempty"`
+	Homepage               string                         `json:"Homepage,omitempty" yaml:"Homepage,omitempty"`
+	License                string                         `json:"License,omitempty" yaml:"License,omitempty"`
+	InstallPath            string                         `json:"InstallPath,omitempty" yaml:"InstallPath,omitempty"`
+	Binary                 map[string][]string            `json:"Binary,omitempty" yaml:"Binary,omitempty"`
+	DesktopFile            string                         `json:"DesktopFile,omitempty" yaml:"DesktopFile,omitempty"`
+	Icons                  []string                       `json:"Icons,omitempty" yaml:"Icons,omitempty"`
+	Documents              map[string][][]string          `json:"Documents,omitempty" yaml:"Documents,omitempty"`
+	ManualPage             map[string][][]string          `json:"ManualPage,omitempty" yaml:"ManualPage,omitempty"`
+	ShellCompletionScripts map[string]map[string][]string `json:"ShellCompletionScripts,omitempty" yaml:"ShellCompletionScripts,omitempty"`
+	Dependencies           []string                       `json:"Dependencies,omitempty" yaml:"Dependencies,omitempty"`
 }
 
 func (p *Program) HasDesktopFile() bool {
@@ -53,6 +129,41 @@ func (p *Program) InstalledFilename() string {
 	return ""
 }
 
+// DefaultInstallPath is where programs without an `InstallPath` are installed.
+const DefaultInstallPath = "/opt/bin"
+
+// InstallDirectory returns the directory the program's binary is installed into.
+func (p *Program) InstallDirectory() string {
+	if p.InstallPath == "" {
+		return DefaultInstallPath
+	}
+	return p.InstallPath
+}
+
+// HasDetails is true if the program has its own description, homepage or license.
+func (p *Program) HasDetails() bool {
+	return p.Description != "" || p.Homepage != "" || p.License != ""
+}
+
+// Details summarises the program's own description, homepage and license for a comment in the ebuild.
+func (p *Program) Details() string {
+	name := p.ProgramName
+	if name == "" {
+		name = p.InstalledFilename()
+	}
+	var details []string
+	if p.Description != "" {
+		details = append(details, p.Description)
+	}
+	if p.Homepage != "" {
+		details = append(details, "Homepage: "+p.Homepage)
+	}
+	if p.License != "" {
+		details = append(details, "License: "+p.License)
+	}
+	return fmt.Sprintf("%s: %s", name, strings.Join(details, ", "))
+}
+
 func (p *Program) IsArchived(arch string) bool {
 	return len(p.Binary[arch]) > 2
 }
@@ -62,6 +173,18 @@ func (p *Program) String() string {
 	if p.ProgramName != "" {
 		sb.WriteString(fmt.Sprintf("ProgramName %s\n", p.ProgramName))
 	}
+	if p.Description != "" {
+		sb.WriteString(fmt.Sprintf("ProgramDescription %s\n", p.Description))
+	}
+	if p.Homepage != "" {
+		sb.WriteString(fmt.Sprintf("ProgramHomepage %s\n", p.Homepage))
+	}
+	if p.License != "" {
+		sb.WriteString(fmt.Sprintf("ProgramLicense %s\n", p.License))
+	}
+	if p.InstallPath != "" {
+		sb.WriteString(fmt.Sprintf("InstallPath %s\n", p.InstallPath))
+	}
 	if p.DesktopFile != "" {
 		sb.WriteString(fmt.Sprintf("DesktopFile %s\n", p.DesktopFile))
 	}
@@ -122,6 +245,10 @@ func DoubleMapStringer(sb *strings.Builder, key string, valueMap map[string]map[
 
 func (p *Program) IsEmpty() bool {
 	return len(p.Binary) == 0 &&
+		len(p.Description) == 0 &&
+		len(p.Homepage) == 0 &&
+		len(p.License) == 0 &&
+		len(p.InstallPath) == 0 &&
 		len(p.DesktopFile) == 0 &&
 		len(p.Icons) == 0 &&
 		len(p.Documents) == 0 &&
@@ -212,18 +339,146 @@ func (p *Program) ShellCompletion(shell string) (result []*KeywordedFilenameRefe
 
 // InputConfig represents a single configuration entry.
 type InputConfig struct {
-	EntryNumber      int
-	Type             string
-	GithubProjectUrl string
-	Category         string
-	EbuildName       string
-	Description      string
-	Homepage         string
-	GithubRepo       string
-	GithubOwner      string
-	License          string
-	Workarounds      map[string]string
-	Programs         map[string]*Program
+	// EntryNumber is the entry's `Id`, it doesn't change when the entry is renamed so other commands and the generated
+	// workflows can refer to it. 0 is an entry without an `Id`.
+	EntryNumber      int    `json:"Id,omitempty" yaml:"Id,omitempty"`
+	Type             string `json:"Type" yaml:"Type"`
+	GithubProjectUrl string `json:"GithubProjectUrl,omitempty" yaml:"GithubProjectUrl,omitempty"`
+	// GitlabProjectUrl is used instead of GithubProjectUrl by the GitLab types, it can be on gitlab.com or a
+	// self-hosted instance.
+	GitlabProjectUrl string `json:"GitlabProjectUrl,omitempty" yaml:"GitlabProjectUrl,omitempty"`
+	// GiteaProjectUrl is used instead of GithubProjectUrl by the Gitea types, it can be on any Gitea API compatible forge
+	// such as Codeberg or a self-hosted Forgejo.
+	GiteaProjectUrl string `json:"GiteaProjectUrl,omitempty" yaml:"GiteaProjectUrl,omitempty"`
+	Category        string `json:"Category,omitempty" yaml:"Category,omitempty"`
+	EbuildName      string `json:"EbuildName,omitempty" yaml:"EbuildName,omitempty"`
+	Description     string `json:"Description,omitempty" yaml:"Description,omitempty"`
+	Homepage        string `json:"Homepage,omitempty" yaml:"Homepage,omitempty"`
+	GithubRepo      string `json:"-" yaml:"-"`
+	GithubOwner     string `json:"-" yaml:"-"`
+	GitlabBaseUrl   string `json:"-" yaml:"-"`
+	// GitlabProjectPath is the project's path on the GitLab instance including any subgroups, such as `group/project`.
+	GitlabProjectPath string `json:"-" yaml:"-"`
+	GiteaBaseUrl      string `json:"-" yaml:"-"`
+	GiteaOwner        string `json:"-" yaml:"-"`
+	GiteaRepo         string `json:"-" yaml:"-"`
+	License           string `json:"License,omitempty" yaml:"License,omitempty"`
+	// BuildSystem is one of SourceBuildSystems, it is only used by the source types.
+	BuildSystem string `json:"BuildSystem,omitempty" yaml:"BuildSystem,omitempty"`
+	// SourceUrl is where the source types download the source from instead of the archive GitHub makes of the tag,
+	// ${VERSION} and ${TAG} are replaced.
+	SourceUrl string `json:"SourceUrl,omitempty" yaml:"SourceUrl,omitempty"`
+	// ReleaseUrl is where the binary and AppImage types download the release files from instead of the release of the
+	// tag, ${VERSION} and ${TAG} are replaced and the release filename is added after it.
+	ReleaseUrl string `json:"ReleaseUrl,omitempty" yaml:"ReleaseUrl,omitempty"`
+	// Depend and BDepend are the DEPEND and BDEPEND of the source types, RDEPEND is DEPEND and the programs'
+	// Dependencies.
+	Depend  []string `json:"Depend,omitempty" yaml:"Depend,omitempty"`
+	BDepend []string `json:"BDepend,omitempty" yaml:"BDepend,omitempty"`
+	// GoDependencies is one of GoDependencyModes, `ego-sum` if empty. GoDependencyTarball is the URL of the dependency
+	// tarball for the `tarball` mode where ${VERSION} and ${TAG} are replaced. Both are only used by the Go source type.
+	GoDependencies      string `json:"GoDependencies,omitempty" yaml:"GoDependencies,omitempty"`
+	GoDependencyTarball string `json:"GoDependencyTarball,omitempty" yaml:"GoDependencyTarball,omitempty"`
+	// GoPackages are the packages built by the Go source type, `.` if empty.
+	GoPackages []string `json:"GoPackages,omitempty" yaml:"GoPackages,omitempty"`
+	// GoLdflags is passed to `go build` by the Go source type, ${VERSION} is replaced with the ebuild's version.
+	GoLdflags   string            `json:"GoLdflags,omitempty" yaml:"GoLdflags,omitempty"`
+	Workarounds map[string]string `json:"Workarounds,omitempty" yaml:"Workarounds,omitempty"`
+	// EbuildVariables are extra variables written to the ebuild, or replacements for the template's LICENSE, SLOT,
+	// KEYWORDS, DEPEND and RESTRICT.
+	EbuildVariables map[string]string   `json:"EbuildVariables,omitempty" yaml:"EbuildVariables,omitempty"`
+	Programs        map[string]*Program `json:"Programs,omitempty" yaml:"Programs,omitempty"`
+	// SourceFile and SourceLine are where the entry was read from, SourceFile is empty if it wasn't read from a file.
+	SourceFile string `json:"-" yaml:"-"`
+	SourceLine int    `json:"-" yaml:"-"`
+}
+
+// Location is the ebuild name followed by the file and line the entry was read from if it was read from a file.
+func (ic *InputConfig) Location() string {
+	if ic.SourceFile == "" {
+		return ic.EbuildName
+	}
+	return fmt.Sprintf("%s (%s:%d)", ic.EbuildName, ic.SourceFile, ic.SourceLine)
+}
+
+// IsGitlab is true if the entry's releases are on a GitLab instance rather than GitHub.
+func (ic *InputConfig) IsGitlab() bool {
+	return strings.HasPrefix(ic.Type, "GitLab ")
+}
+
+// IsSource is true if the entry's ebuild builds the release from source rather than installing release files.
+func (ic *InputConfig) IsSource() bool {
+	return strings.HasSuffix(ic.Type, " Source Release")
+}
+
+// IsLlamafile is true if the entry's release files are llamafiles, Actually Portable Executables which are installed
+// as they are downloaded.
+func (ic *InputConfig) IsLlamafile() bool {
+	return strings.HasSuffix(ic.Type, " Llamafile Release")
+}
+
+// UsesReleaseUrl is true if the entry's type can download its release files from ReleaseUrl.
+func (ic *InputConfig) UsesReleaseUrl() bool {
+	return strings.HasSuffix(ic.Type, " Binary Release") || strings.HasSuffix(ic.Type, " AppImage Release")
+}
+
+// SourceArchiveExtension is the extension of the source archive, `.tar.gz` for the archive GitHub makes of the tag and
+// otherwise the one of SourceArchiveExtensions SourceUrl ends with, or empty if none do.
+func (ic *InputConfig) SourceArchiveExtension() string {
+	if ic.SourceUrl == "" {
+		return ".tar.gz"
+	}
+	for _, ext := range SourceArchiveExtensions {
+		if strings.HasSuffix(ic.SourceUrl, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// GoDependencyMode is GoDependencies or `ego-sum` if it isn't set.
+func (ic *InputConfig) GoDependencyMode() string {
+	if ic.GoDependencies == "" {
+		return "ego-sum"
+	}
+	return ic.GoDependencies
+}
+
+// IsGoSource is true if the entry's ebuild builds a Go module from source with the go-module eclass.
+func (ic *InputConfig) IsGoSource() bool {
+	return strings.HasSuffix(ic.Type, " Go Source Release")
+}
+
+// IsGitea is true if the entry's releases are on a Gitea API compatible forge rather than GitHub.
+func (ic *InputConfig) IsGitea() bool {
+	return strings.HasPrefix(ic.Type, "Gitea ")
+}
+
+// ProjectUrl is the value of the entry's ProjectUrlField.
+func (ic *InputConfig) ProjectUrl() string {
+	switch {
+	case ic.IsGitlab():
+		return ic.GitlabProjectUrl
+	case ic.IsGitea():
+		return ic.GiteaProjectUrl
+	}
+	return ic.GithubProjectUrl
+}
+
+// RepoName is the name of the GitHub or Gitea repository or the GitLab project without its group.
+func (ic *InputConfig) RepoName() string {
+	switch {
+	case ic.IsGitlab():
+		return path.Base(ic.GitlabProjectPath)
+	case ic.IsGitea():
+		return ic.GiteaRepo
+	}
+	return ic.GithubRepo
+}
+
+// GitlabApiProjectUrl is the GitLab API URL of the project, the project path is escaped so it can be used as the id.
+func (ic *InputConfig) GitlabApiProjectUrl() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", ic.GitlabBaseUrl, url.QueryEscape(ic.GitlabProjectPath))
 }
 
 func (ic *InputConfig) GetPrograms() map[string]*Program {
@@ -242,71 +497,52 @@ func (ic *InputConfig) String() string {
 	if ic.Type != "" {
 		sb.WriteString(fmt.Sprintf("Type %s\n", ic.Type))
 	}
-	switch ic.Type {
-	case "Github AppImage Release":
-		if ic.GithubProjectUrl != "" {
-			sb.WriteString(fmt.Sprintf("GithubProjectUrl %s\n", ic.GithubProjectUrl))
-		}
-		if ic.Category != "" {
-			sb.WriteString(fmt.Sprintf("Category %s\n", ic.Category))
-		}
-		if ic.EbuildName != "" {
-			sb.WriteString(fmt.Sprintf("EbuildName %s\n", ic.EbuildName))
-		}
-		if ic.Description != "" {
-			sb.WriteString(fmt.Sprintf("Description %s\n", ic.Description))
-		}
-		if ic.Homepage != "" {
-			sb.WriteString(fmt.Sprintf("Homepage %s\n", ic.Homepage))
-		}
-		if ic.License != "" {
-			sb.WriteString(fmt.Sprintf("License %s\n", ic.License))
-		}
-		workarounds := ic.WorkaroundString()
-		for _, workaround := range workarounds {
-			if len(ic.Workarounds[workaround]) == 0 {
-				sb.WriteString(fmt.Sprintf("Workaround %s\n", workaround))
-			} else {
-				sb.WriteString(fmt.Sprintf("Workaround %s => %s\n", workaround, ic.Workarounds[workaround]))
-			}
-		}
-		programs := ic.ProgramsString()
-		for _, programName := range programs {
-			sb.WriteString(ic.Programs[programName].String())
-		}
-	case "Github Binary Release":
-		if ic.GithubProjectUrl != "" {
-			sb.WriteString(fmt.Sprintf("GithubProjectUrl %s\n", ic.GithubProjectUrl))
-		}
-		if ic.Category != "" {
-			sb.WriteString(fmt.Sprintf("Category %s\n", ic.Category))
-		}
-		if ic.EbuildName != "" {
-			sb.WriteString(fmt.Sprintf("EbuildName %s\n", ic.EbuildName))
-		}
-		if ic.Description != "" {
-			sb.WriteString(fmt.Sprintf("Description %s\n", ic.Description))
-		}
-		if ic.Homepage != "" {
-			sb.WriteString(fmt.Sprintf("Homepage %s\n", ic.Homepage))
-		}
-		if ic.License != "" {
-			sb.WriteString(fmt.Sprintf("License %s\n", ic.License))
-		}
-		workarounds := ic.WorkaroundString()
-		for _, workaround := range workarounds {
-			if len(ic.Workarounds[workaround]) == 0 {
-				sb.WriteString(fmt.Sprintf("Workaround %s\n", workaround))
-			} else {
-				sb.WriteString(fmt.Sprintf("Workaround %s => %s\n", workaround, ic.Workarounds[workaround]))
-			}
-		}
-		programs := ic.ProgramsString()
-		for _, programName := range programs {
-			sb.WriteString(ic.Programs[programName].String())
+	if ic.EntryNumber != 0 {
+		sb.WriteString(fmt.Sprintf("Id %d\n", ic.EntryNumber))
+	}
+	if !slices.Contains(InputConfigTypes, ic.Type) {
+		sb.WriteString("# Unknown type\n")
+		return sb.String()
+	}
+	writeField := func(key, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("%s %s\n", key, value))
 		}
+	}
+	switch urlField := ProjectUrlField(ic.Type); urlField {
+	case "GitlabProjectUrl":
+		writeField(urlField, ic.GitlabProjectUrl)
+	case "GiteaProjectUrl":
+		writeField(urlField, ic.GiteaProjectUrl)
 	default:
-		sb.WriteString("# Unknown type\n")
+		writeField(urlField, ic.GithubProjectUrl)
+	}
+	writeField("Category", ic.Category)
+	writeField("EbuildName", ic.EbuildName)
+	writeField("Description", ic.Description)
+	writeField("Homepage", ic.Homepage)
+	writeField("License", ic.License)
+	writeField("BuildSystem", ic.BuildSystem)
+	writeField("SourceUrl", ic.SourceUrl)
+	writeField("ReleaseUrl", ic.ReleaseUrl)
+	writeField("Depend", strings.Join(ic.Depend, " "))
+	writeField("BDepend", strings.Join(ic.BDepend, " "))
+	writeField("GoDependencies", ic.GoDependencies)
+	writeField("GoDependencyTarball", ic.GoDependencyTarball)
+	writeField("GoPackages", strings.Join(ic.GoPackages, " "))
+	writeField("GoLdflags", ic.GoLdflags)
+	for _, workaround := range ic.WorkaroundString() {
+		if len(ic.Workarounds[workaround]) == 0 {
+			sb.WriteString(fmt.Sprintf("Workaround %s\n", workaround))
+		} else {
+			sb.WriteString(fmt.Sprintf("Workaround %s => %s\n", workaround, ic.Workarounds[workaround]))
+		}
+	}
+	for _, name := range ic.EbuildVariableNames() {
+		sb.WriteString(fmt.Sprintf("EbuildVariable %s => %s\n", name, ic.EbuildVariables[name]))
+	}
+	for _, programName := range ic.ProgramsString() {
+		sb.WriteString(ic.Programs[programName].String())
 	}
 
 	return sb.String()
@@ -332,31 +568,154 @@ func (ic *InputConfig) WorkaroundString() []string {
 
 // ParseInputConfigReader parses the given configuration file and returns a slice of InputConfig structures.
 func ParseInputConfigReader(file io.Reader) ([]*InputConfig, error) {
+	return (&InputConfigParser{}).Parse(file)
+}
+
+// InputConfigParser parses configuration files, the zero value parses a single file without support for `Include`.
+type InputConfigParser struct {
+	// Filename is used in errors and recorded as the source of each entry.
+	Filename string
+	// Include is called with the line number and pattern of each `Include` line and the defaults at that point, the
+	// entries it returns are added in place of the line.
+	Include func(lineNumber int, pattern string, defaults *InputConfigDefaults) ([]*InputConfig, error)
+	// Defaults are the defaults before the first `Defaults` block, such as those of the file which included this one.
+	Defaults *InputConfigDefaults
+	// ConfigVersion is the version of the format when there is no `ConfigVersion` line, 0 is version 1. Entries in an
+	// older format are migrated as they are read.
+	ConfigVersion int
+}
+
+// lineError names the file and line in the error if there is a filename, otherwise just the line.
+func (icp *InputConfigParser) lineError(lineNumber int, format string, a ...any) error {
+	if icp.Filename != "" {
+		return fmt.Errorf("%s:%d: %w", icp.Filename, lineNumber, fmt.Errorf(format, a...))
+	}
+	return fmt.Errorf("line %d: %w", lineNumber, fmt.Errorf(format, a...))
+}
+
+func (icp *InputConfigParser) setSource(ic *InputConfig, lineNumber int) {
+	if icp.Filename == "" {
+		return
+	}
+	ic.SourceFile = icp.Filename
+	ic.SourceLine = lineNumber
+}
+
+func (icp *InputConfigParser) Parse(file io.Reader) ([]*InputConfig, error) {
 	var configs []*InputConfig
 	var parseFields map[string][]string
 	var parseProgramFields map[string]map[string][]string
-	scanner := bufio.NewScanner(file)
+	doc, err := ParseConfigDocument(file)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := icp.migratedLines(doc)
+	if err != nil {
+		return nil, err
+	}
 	breakCount := 0
 	var lastProgramName string
 	var lineNumber = 0
+	var entryLineNumber = 0
+	defaults := icp.Defaults
+	var defaultsFields map[string][]string
+	var defaultsLineNumber = 0
+	var versionLineNumber = 0
+	inVersionBlock := false
+	started := false
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		lineNumber++
+	for _, configLine := range lines {
+		line := strings.TrimSpace(configLine.Text)
+		if configLine.Number > 0 {
+			lineNumber = configLine.Number
+		}
 		if strings.HasPrefix(line, "#") {
 			continue
 		}
 
+		if _, ok := cutKey(line, "ConfigVersion"); ok {
+			if versionLineNumber > 0 {
+				return nil, icp.lineError(lineNumber, "ConfigVersion is already set on line %d", versionLineNumber)
+			}
+			if started {
+				return nil, icp.lineError(lineNumber, "ConfigVersion must come before the first entry, Defaults or Include")
+			}
+			versionLineNumber = lineNumber
+			inVersionBlock = true
+			continue
+		}
 		if line == "" {
+			inVersionBlock = false
+		} else {
+			if inVersionBlock {
+				return nil, icp.lineError(lineNumber, "ConfigVersion must be separated from the rest of the file by a blank line")
+			}
+			started = true
+		}
+
+		if value, ok := cutKey(line, "Defaults"); ok {
+			if parseFields != nil {
+				return nil, icp.lineError(lineNumber, "Defaults must be separated from the entry starting on line %d by a blank line", entryLineNumber)
+			}
+			if defaultsFields != nil {
+				return nil, icp.lineError(lineNumber, "Defaults is already started on line %d", defaultsLineNumber)
+			}
+			if value != "" {
+				return nil, icp.lineError(lineNumber, "Defaults doesn't take a value, put the fields on the lines after it")
+			}
+			defaultsFields = map[string][]string{}
+			defaultsLineNumber = lineNumber
+			continue
+		}
+
+		if defaultsFields != nil && line != "" {
+			configLine := &ConfigLine{Text: line}
+			if !slices.Contains(defaultsFieldKeys, configLine.Key()) {
+				return nil, icp.lineError(lineNumber, "%s can't be used in Defaults, use one of: %s", configLine.Key(), strings.Join(defaultsFieldKeys, ", "))
+			}
+			defaultsFields[configLine.Key()] = append(defaultsFields[configLine.Key()], configLine.Value())
+			continue
+		}
+
+		if pattern, ok := cutKey(line, "Include"); ok {
+			if parseFields != nil {
+				return nil, icp.lineError(lineNumber, "Include must be separated from the entry starting on line %d by a blank line", entryLineNumber)
+			}
+			if icp.Include == nil {
+				return nil, icp.lineError(lineNumber, "Include isn't supported here")
+			}
+			if pattern == "" {
+				return nil, icp.lineError(lineNumber, "Include has no file or pattern")
+			}
+			included, err := icp.Include(lineNumber, pattern, defaults)
+			if err != nil {
+				return nil, icp.lineError(lineNumber, "Include %s: %w", pattern, err)
+			}
+			configs = append(configs, included...)
+			continue
+		}
+
+		if line == "" {
+			if defaultsFields != nil {
+				merged, err := defaults.Merge(defaultsFields)
+				if err != nil {
+					return nil, icp.lineError(defaultsLineNumber, "Defaults: %w", err)
+				}
+				defaults = merged
+				defaultsFields = nil
+				continue
+			}
 			if breakCount < 0 || parseFields == nil {
 				continue
 			}
 			breakCount++
-			var err error
-			configs, err = CreateSanitizeAndAppendInputConfig(parseFields, parseProgramFields, configs)
+			defaults.applyDependencies(parseFields, parseProgramFields)
+			appended, err := CreateSanitizeAndAppendInputConfig(parseFields, parseProgramFields, configs)
 			if err != nil {
-				return nil, fmt.Errorf("line %d: sanitiization issue with %d: %w", lineNumber, len(configs), err)
+				return nil, icp.lineError(lineNumber, "sanitiization issue with entry %d starting on line %d: %w", len(configs)+1, entryLineNumber, err)
 			}
+			configs = appended
+			icp.setSource(configs[len(configs)-1], entryLineNumber)
 			parseFields = nil
 			parseProgramFields = nil
 			lastProgramName = ""
@@ -364,22 +723,40 @@ func ParseInputConfigReader(file io.Reader) ([]*InputConfig, error) {
 		}
 
 		if parseFields == nil {
+			entryLineNumber = lineNumber
 			parseFields = map[string][]string{
 				"Type":                  nil,
+				"Id":                    nil,
 				"GithubProjectUrl":      nil,
-				"Category":              {DefaultCategory},
+				"GitlabProjectUrl":      nil,
+				"GiteaProjectUrl":       nil,
+				"Category":              {defaults.category()},
 				"EbuildName":            nil,
 				"Description":           nil,
 				"Homepage":              nil,
-				"License":               {DefaultLicense},
+				"License":               {defaults.license()},
+				"BuildSystem":           nil,
+				"SourceUrl":             nil,
+				"ReleaseUrl":            nil,
+				"Depend":                nil,
+				"BDepend":               nil,
+				"GoDependencies":        nil,
+				"GoDependencyTarball":   nil,
+				"GoPackages":            nil,
+				"GoLdflags":             nil,
 				"ProgramName":           nil,
+				"ProgramDescription":    nil,
+				"ProgramHomepage":       nil,
+				"ProgramLicense":        nil,
+				"InstallPath":           nil,
 				"DesktopFile":           nil,
 				"Icons":                 nil,
 				"ManualPage":            nil,
 				"Document":              nil,
 				"ShellCompletionScript": nil,
 				"Dependencies":          nil,
-				"Workaround":            nil,
+				"Workaround":            defaults.workaroundLines(),
+				"EbuildVariable":        defaults.ebuildVariableLines(),
 				"Binary":                nil,
 			}
 			parseProgramFields = map[string]map[string][]string{}
@@ -403,6 +780,10 @@ func ParseInputConfigReader(file io.Reader) ([]*InputConfig, error) {
 						lastProgramName = value
 						parseProgramFields[lastProgramName] = map[string][]string{
 							"ProgramName":           {value},
+							"ProgramDescription":    nil,
+							"ProgramHomepage":       nil,
+							"ProgramLicense":        nil,
+							"InstallPath":           nil,
 							"DesktopFile":           nil,
 							"Dependencies":          nil,
 							"Icons":                 nil,
@@ -436,6 +817,10 @@ func ParseInputConfigReader(file io.Reader) ([]*InputConfig, error) {
 						lastProgramName = value
 						parseProgramFields[lastProgramName] = map[string][]string{
 							"ProgramName":           {value},
+							"ProgramDescription":    nil,
+							"ProgramHomepage":       nil,
+							"ProgramLicense":        nil,
+							"InstallPath":           nil,
 							"DesktopFile":           nil,
 							"Icons":                 nil,
 							"ManualPage":            nil,
@@ -453,26 +838,52 @@ func ParseInputConfigReader(file io.Reader) ([]*InputConfig, error) {
 		}
 
 		if !matched {
-			return nil, fmt.Errorf("invalid line: %s", line)
+			return nil, icp.lineError(lineNumber, "invalid line: %s", line)
 		}
 		breakCount = 0
 	}
 
-	if parseFields != nil {
-		var err error
-		configs, err = CreateSanitizeAndAppendInputConfig(parseFields, parseProgramFields, configs)
-		if err != nil {
-			return nil, fmt.Errorf("sanitiization issue with last(%d): %w", len(configs), err)
+	if defaultsFields != nil {
+		if _, err := defaults.Merge(defaultsFields); err != nil {
+			return nil, icp.lineError(defaultsLineNumber, "Defaults: %w", err)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if parseFields != nil {
+		defaults.applyDependencies(parseFields, parseProgramFields)
+		appended, err := CreateSanitizeAndAppendInputConfig(parseFields, parseProgramFields, configs)
+		if err != nil {
+			return nil, icp.lineError(entryLineNumber, "sanitiization issue with last entry %d: %w", len(configs)+1, err)
+		}
+		configs = appended
+		icp.setSource(configs[len(configs)-1], entryLineNumber)
 	}
 
 	return configs, nil
 }
 
+// migratedLines returns the lines of the document with the entries migrated to the current format.
+func (icp *InputConfigParser) migratedLines(doc *ConfigDocument) ([]*ConfigLine, error) {
+	version, versionLine, err := doc.ConfigVersion()
+	if err != nil {
+		return nil, icp.lineError(versionLine.Number, "%w", err)
+	}
+	if versionLine == nil && icp.ConfigVersion > 0 {
+		version = icp.ConfigVersion
+	}
+	var lines []*ConfigLine
+	for _, block := range doc.Blocks {
+		if version < CurrentConfigVersion && block.IsEntry() {
+			if _, err := MigrateConfigBlock(block, version); err != nil {
+				return nil, icp.lineError(block.FirstLineNumber(), "%w", err)
+			}
+		}
+		lines = append(lines, block.Separator...)
+		lines = append(lines, block.Lines...)
+	}
+	return append(lines, doc.Trailing...), nil
+}
+
 func CreateSanitizeAndAppendInputConfig(parsedFields map[string][]string, parsedProgramFields map[string]map[string][]string, configs []*InputConfig) ([]*InputConfig, error) {
 	var err error
 	currentConfig := &InputConfig{}
@@ -480,9 +891,25 @@ func CreateSanitizeAndAppendInputConfig(parsedFields map[string][]string, parsed
 	if err != nil {
 		return nil, fmt.Errorf("on Type: %v: %w", parsedFields["Type"], err)
 	}
-	currentConfig.GithubProjectUrl, err = onlyOrFail(parsedFields["GithubProjectUrl"])
+	id, err := emptyOrOnlyOrFail(parsedFields["Id"])
+	if err != nil {
+		return nil, fmt.Errorf("on Id: %v: %w", parsedFields["Id"], err)
+	}
+	if id != "" {
+		currentConfig.EntryNumber, err = ParseEntryId(id)
+		if err != nil {
+			return nil, fmt.Errorf("on Id: %w", err)
+		}
+	}
+	urlField := ProjectUrlField(currentConfig.Type)
+	for _, field := range ProjectUrlFields {
+		if field != urlField && len(parsedFields[field]) > 0 {
+			return nil, fmt.Errorf("on %s: %s uses %s", field, currentConfig.Type, urlField)
+		}
+	}
+	projectUrl, err := onlyOrFail(parsedFields[urlField])
 	if err != nil {
-		return nil, fmt.Errorf("on GithubProjectUrl: %v: %w", parsedFields["GithubProjectUrl"], err)
+		return nil, fmt.Errorf("on %s: %v: %w", urlField, parsedFields[urlField], err)
 	}
 	currentConfig.Category, err = emptyOrLast(parsedFields["Category"])
 	if err != nil {
@@ -504,31 +931,153 @@ func CreateSanitizeAndAppendInputConfig(parsedFields map[string][]string, parsed
 	if err != nil {
 		return nil, fmt.Errorf("on License: %v: %w", parsedFields["License"], err)
 	}
-	currentConfig.GithubOwner, currentConfig.GithubRepo, err = util.ExtractGithubOwnerRepo(currentConfig.GithubProjectUrl)
-	if err != nil {
-		return nil, fmt.Errorf("github url parser: %w", err)
+	switch {
+	case currentConfig.IsGitlab():
+		currentConfig.GitlabProjectUrl = projectUrl
+		currentConfig.GitlabBaseUrl, currentConfig.GitlabProjectPath, err = util.ExtractGitlabProject(projectUrl)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab url parser: %w", err)
+		}
+	case currentConfig.IsGitea():
+		currentConfig.GiteaProjectUrl = projectUrl
+		currentConfig.GiteaBaseUrl, currentConfig.GiteaOwner, currentConfig.GiteaRepo, err = util.ExtractGiteaOwnerRepo(projectUrl)
+		if err != nil {
+			return nil, fmt.Errorf("gitea url parser: %w", err)
+		}
+	default:
+		currentConfig.GithubProjectUrl = projectUrl
+		currentConfig.GithubOwner, currentConfig.GithubRepo, err = util.ExtractGithubOwnerRepo(projectUrl)
+		if err != nil {
+			return nil, fmt.Errorf("github url parser: %w", err)
+		}
 	}
 	currentConfig.Workarounds, err = parseOptionalMapType1(parsedFields["Workaround"])
 	if err != nil {
 		return nil, fmt.Errorf("on Workarounds: %v: %w", parsedFields["Workaround"], err)
 	}
+	for _, name := range cutWorkaroundRemovals(currentConfig.Workarounds) {
+		delete(currentConfig.Workarounds, name)
+	}
+	currentConfig.EbuildVariables, err = parseEbuildVariables(parsedFields["EbuildVariable"])
+	if err != nil {
+		return nil, fmt.Errorf("on EbuildVariable: %v: %w", parsedFields["EbuildVariable"], err)
+	}
+	if !currentConfig.IsSource() {
+		for _, field := range []string{"BuildSystem", "SourceUrl", "Depend", "BDepend"} {
+			if len(parsedFields[field]) > 0 {
+				return nil, fmt.Errorf("on %s: only used by the source types", field)
+			}
+		}
+	} else {
+		currentConfig.SourceUrl, err = emptyOrOnlyOrFail(parsedFields["SourceUrl"])
+		if err != nil {
+			return nil, fmt.Errorf("on SourceUrl: %v: %w", parsedFields["SourceUrl"], err)
+		}
+		if currentConfig.SourceUrl != "" && currentConfig.SourceArchiveExtension() == "" {
+			return nil, fmt.Errorf("on SourceUrl: %s isn't a tar archive, it needs to end with one of: %s", currentConfig.SourceUrl, strings.Join(SourceArchiveExtensions, ", "))
+		}
+	}
+	if !currentConfig.UsesReleaseUrl() {
+		if len(parsedFields["ReleaseUrl"]) > 0 {
+			return nil, fmt.Errorf("on ReleaseUrl: only used by the binary and AppImage types")
+		}
+	} else {
+		currentConfig.ReleaseUrl, err = emptyOrOnlyOrFail(parsedFields["ReleaseUrl"])
+		if err != nil {
+			return nil, fmt.Errorf("on ReleaseUrl: %v: %w", parsedFields["ReleaseUrl"], err)
+		}
+	}
+	if !currentConfig.IsGoSource() {
+		for _, field := range goSourceFields {
+			if len(parsedFields[field]) > 0 {
+				return nil, fmt.Errorf("on %s: only used by the Go source type", field)
+			}
+		}
+	}
 	switch currentConfig.Type {
-	case "Github AppImage Release":
+	case "Github AppImage Release", "GitLab AppImage Release", "Gitea AppImage Release":
 		if currentConfig.EbuildName == "" {
-			currentConfig.EbuildName = currentConfig.GithubRepo
+			currentConfig.EbuildName = currentConfig.RepoName()
 		}
 		currentConfig.EbuildName = util.TrimSuffixes(strings.TrimSuffix(currentConfig.EbuildName, ".ebuild"), "-appimage", "-AppImage") + "-appimage.ebuild"
 		if currentConfig.Programs == nil {
 			currentConfig.Programs = map[string]*Program{}
 		}
-	case "Github Binary Release":
+	case "Github Binary Release", "GitLab Binary Release", "Gitea Binary Release", "Github Llamafile Release":
 		if currentConfig.EbuildName == "" {
-			currentConfig.EbuildName = currentConfig.GithubRepo
+			currentConfig.EbuildName = currentConfig.RepoName()
 		}
 		currentConfig.EbuildName = util.TrimSuffixes(strings.TrimSuffix(currentConfig.EbuildName, ".ebuild"), "-bin") + "-bin.ebuild"
 		if currentConfig.Programs == nil {
 			currentConfig.Programs = map[string]*Program{}
 		}
+	case "Github Source Release":
+		if currentConfig.EbuildName == "" {
+			currentConfig.EbuildName = currentConfig.RepoName()
+		}
+		currentConfig.EbuildName = strings.TrimSuffix(currentConfig.EbuildName, ".ebuild") + ".ebuild"
+		currentConfig.BuildSystem, err = onlyOrFail(parsedFields["BuildSystem"])
+		if err != nil {
+			return nil, fmt.Errorf("on BuildSystem: %v: %w", parsedFields["BuildSystem"], err)
+		}
+		if !slices.Contains(SourceBuildSystems, currentConfig.BuildSystem) {
+			return nil, fmt.Errorf("on BuildSystem: unknown build system %s, use one of: %s", currentConfig.BuildSystem, strings.Join(SourceBuildSystems, ", "))
+		}
+		currentConfig.Depend, err = emptyOrAppendStringArray(nil, parsedFields["Depend"])
+		if err != nil {
+			return nil, fmt.Errorf("on Depend: %v: %w", parsedFields["Depend"], err)
+		}
+		currentConfig.BDepend, err = emptyOrAppendStringArray(nil, parsedFields["BDepend"])
+		if err != nil {
+			return nil, fmt.Errorf("on BDepend: %v: %w", parsedFields["BDepend"], err)
+		}
+		if currentConfig.Programs == nil {
+			currentConfig.Programs = map[string]*Program{}
+		}
+	case "Github Go Source Release", "Github Rust Source Release":
+		if currentConfig.EbuildName == "" {
+			currentConfig.EbuildName = currentConfig.RepoName()
+		}
+		currentConfig.EbuildName = strings.TrimSuffix(currentConfig.EbuildName, ".ebuild") + ".ebuild"
+		if len(parsedFields["BuildSystem"]) > 0 {
+			return nil, fmt.Errorf("on BuildSystem: not used by %s, it is always built with %s", currentConfig.Type, languageSourceEclasses[currentConfig.Type])
+		}
+		currentConfig.Depend, err = emptyOrAppendStringArray(nil, parsedFields["Depend"])
+		if err != nil {
+			return nil, fmt.Errorf("on Depend: %v: %w", parsedFields["Depend"], err)
+		}
+		currentConfig.BDepend, err = emptyOrAppendStringArray(nil, parsedFields["BDepend"])
+		if err != nil {
+			return nil, fmt.Errorf("on BDepend: %v: %w", parsedFields["BDepend"], err)
+		}
+		if currentConfig.Programs == nil {
+			currentConfig.Programs = map[string]*Program{}
+		}
+		if !currentConfig.IsGoSource() {
+			break
+		}
+		currentConfig.GoDependencies, err = emptyOrOnlyOrFail(parsedFields["GoDependencies"])
+		if err != nil {
+			return nil, fmt.Errorf("on GoDependencies: %v: %w", parsedFields["GoDependencies"], err)
+		}
+		if currentConfig.GoDependencies != "" && !slices.Contains(GoDependencyModes, currentConfig.GoDependencies) {
+			return nil, fmt.Errorf("on GoDependencies: unknown mode %s, use one of: %s", currentConfig.GoDependencies, strings.Join(GoDependencyModes, ", "))
+		}
+		currentConfig.GoDependencyTarball, err = emptyOrOnlyOrFail(parsedFields["GoDependencyTarball"])
+		if err != nil {
+			return nil, fmt.Errorf("on GoDependencyTarball: %v: %w", parsedFields["GoDependencyTarball"], err)
+		}
+		if (currentConfig.GoDependencyMode() == "tarball") != (currentConfig.GoDependencyTarball != "") {
+			return nil, fmt.Errorf("on GoDependencyTarball: needed by, and only used by, GoDependencies tarball")
+		}
+		currentConfig.GoPackages, err = emptyOrAppendStringArray(nil, parsedFields["GoPackages"])
+		if err != nil {
+			return nil, fmt.Errorf("on GoPackages: %v: %w", parsedFields["GoPackages"], err)
+		}
+		currentConfig.GoLdflags, err = emptyOrOnlyOrFail(parsedFields["GoLdflags"])
+		if err != nil {
+			return nil, fmt.Errorf("on GoLdflags: %v: %w", parsedFields["GoLdflags"], err)
+		}
 	default:
 		return nil, fmt.Errorf("uknown type: %s", currentConfig.Type)
 	}
@@ -561,6 +1110,28 @@ func (ic *InputConfig) CreateAndSanitizeInputConfigProgram(programName string, p
 		ProgramName: programName,
 	}
 	var err error
+	program.Description, err = emptyOrOnlyOrFail(programFields["ProgramDescription"])
+	if err != nil {
+		return nil, fmt.Errorf("on ProgramDescription: %v: %w", programFields["ProgramDescription"], err)
+	}
+	program.Homepage, err = emptyOrOnlyOrFail(programFields["ProgramHomepage"])
+	if err != nil {
+		return nil, fmt.Errorf("on ProgramHomepage: %v: %w", programFields["ProgramHomepage"], err)
+	}
+	program.License, err = emptyOrOnlyOrFail(programFields["ProgramLicense"])
+	if err != nil {
+		return nil, fmt.Errorf("on ProgramLicense: %v: %w", programFields["ProgramLicense"], err)
+	}
+	program.InstallPath, err = emptyOrOnlyOrFail(programFields["InstallPath"])
+	if err != nil {
+		return nil, fmt.Errorf("on InstallPath: %v: %w", programFields["InstallPath"], err)
+	}
+	if program.InstallPath != "" {
+		if err := ValidateInstallPath(program.InstallPath); err != nil {
+			return nil, fmt.Errorf("on InstallPath: %w", err)
+		}
+		program.InstallPath = path.Clean(program.InstallPath)
+	}
 	program.Dependencies, err = emptyOrAppendStringArray(program.Dependencies, programFields["Dependencies"])
 	if err != nil {
 		return nil, fmt.Errorf("on Dependencies: %v: %w", programFields["Dependencies"], err)
@@ -570,7 +1141,7 @@ func (ic *InputConfig) CreateAndSanitizeInputConfigProgram(programName string, p
 		return nil, fmt.Errorf("on Binary: %v: %w", programFields["Binary"], err)
 	}
 	switch ic.Type {
-	case "Github AppImage Release":
+	case "Github AppImage Release", "GitLab AppImage Release", "Gitea AppImage Release":
 		program.DesktopFile, err = emptyOrOnlyOrFail(programFields["DesktopFile"])
 		if err != nil {
 			return nil, fmt.Errorf("on DesktopFile: %v: %w", programFields["DesktopFile"], err)
@@ -580,12 +1151,26 @@ func (ic *InputConfig) CreateAndSanitizeInputConfigProgram(programName string, p
 			return nil, fmt.Errorf("on Icons: %v: %w", programFields["Icons"], err)
 		}
 		if DefaultDesktopFileEnabled && program.DesktopFile == "" {
-			program.DesktopFile = ic.GithubRepo
+			program.DesktopFile = ic.RepoName()
+		}
+		if program.DesktopFile != "" {
+			program.DesktopFile = util.TrimSuffixes(program.DesktopFile, ".desktop") + ".desktop"
+		}
+	case "Github Binary Release", "GitLab Binary Release", "Gitea Binary Release":
+		// The desktop file and icons of a program repackaged from a .deb
+		program.DesktopFile, err = emptyOrOnlyOrFail(programFields["DesktopFile"])
+		if err != nil {
+			return nil, fmt.Errorf("on DesktopFile: %v: %w", programFields["DesktopFile"], err)
 		}
 		if program.DesktopFile != "" {
 			program.DesktopFile = util.TrimSuffixes(program.DesktopFile, ".desktop") + ".desktop"
 		}
-	case "Github Binary Release":
+		if len(programFields["Icons"]) > 0 {
+			program.Icons, err = emptyOrAppendStringArray(program.Icons, programFields["Icons"])
+			if err != nil {
+				return nil, fmt.Errorf("on Icons: %v: %w", programFields["Icons"], err)
+			}
+		}
 		program.Documents, err = parseMapDoubleStringListType1(programFields["Document"])
 		if err != nil {
 			return nil, fmt.Errorf("on Document: %v: %w", programFields["Document"], err)
@@ -598,6 +1183,10 @@ func (ic *InputConfig) CreateAndSanitizeInputConfigProgram(programName string, p
 		if err != nil {
 			return nil, fmt.Errorf("on ShellCompletionScript: %v: %w", programFields["ShellCompletionScript"], err)
 		}
+	case "Github Source Release", "Github Go Source Release", "Github Rust Source Release":
+		// The build system installs the programs, only their details and Dependencies are used.
+	case "Github Llamafile Release":
+		// A llamafile is a single executable, only its Binary lines are used.
 	default:
 		return nil, fmt.Errorf("uknown type: %s", ic.Type)
 	}
@@ -605,45 +1194,152 @@ func (ic *InputConfig) CreateAndSanitizeInputConfigProgram(programName string, p
 }
 
 func (ic *InputConfig) WorkaroundSemanticVersionWithoutV() bool {
-	if ic.Workarounds == nil {
-		return false
-	}
-	_, ok := ic.Workarounds["Semantic Version Without V"]
+	_, ok := workaroundValue(ic.Workarounds, semanticVersionWithoutVWorkaround)
 	return ok
 }
 
 func (ic *InputConfig) WorkaroundSemanticVersionPrereleaseHack1() bool {
-	if ic.Workarounds == nil {
-		return false
-	}
-	_, ok := ic.Workarounds["Semantic Version Prerelease Hack 1"]
+	_, ok := workaroundValue(ic.Workarounds, semanticVersionPrereleaseHack1Workaround)
 	return ok
 }
 
 func (ic *InputConfig) WorkaroundTagPrefix() string {
-	if ic.Workarounds == nil {
+	if v, ok := workaroundValue(ic.Workarounds, tagPrefixWorkaround); ok {
+		return v.(string)
+	}
+	return ""
+}
+
+func (ic *InputConfig) WorkaroundTagsWithoutReleases() bool {
+	_, ok := workaroundValue(ic.Workarounds, tagsWithoutReleasesWorkaround)
+	return ok
+}
+
+// LiveEbuildBuildSystem is how the live ebuild is built if the entry has the Live Ebuild workaround, otherwise empty.
+func (ic *InputConfig) LiveEbuildBuildSystem() string {
+	if v, ok := workaroundValue(ic.Workarounds, liveEbuildWorkaround); ok {
+		return v.(string)
+	}
+	return ""
+}
+
+// RollingTag is the tag the entry tracks if it has the Rolling Tag workaround, otherwise nil.
+func (ic *InputConfig) RollingTag() *RollingTag {
+	if v, ok := workaroundValue(ic.Workarounds, rollingTagWorkaround); ok {
+		return v.(*RollingTag)
+	}
+	return nil
+}
+
+// RollingTagBuildNumberPattern is a sed pattern matching the name of the first release file with a ${BUILD} number,
+// which it captures. It is empty if no release filename has a ${BUILD}.
+func (ic *InputConfig) RollingTagBuildNumberPattern() string {
+	rt := ic.RollingTag()
+	if rt == nil {
 		return ""
 	}
-	s := ic.Workarounds["Tag Prefix"]
-	return s
+	for _, programName := range slices.Sorted(maps.Keys(ic.Programs)) {
+		binary := ic.Programs[programName].Binary
+		for _, keyword := range slices.Sorted(maps.Keys(binary)) {
+			if len(binary[keyword]) == 0 || !strings.Contains(binary[keyword][0], "${BUILD}") {
+				continue
+			}
+			var sb strings.Builder
+			for i, part := range strings.Split(binary[keyword][0], "${BUILD}") {
+				if i > 0 {
+					sb.WriteString(`\([0-9]\+\)`)
+				}
+				sb.WriteString(sedQuote(strings.ReplaceAll(part, "${TAG}", rt.Tag)))
+			}
+			return sb.String()
+		}
+	}
+	return ""
+}
+
+// sedQuote escapes the characters which are special in a sed basic regular expression delimited by /.
+func sedQuote(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.*[]^$/`, r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// HasWorkaround is true if the entry has the workaround, it lets templates check workarounds without a hook of their
+// own.
+func (ic *InputConfig) HasWorkaround(name string) bool {
+	_, ok := ic.Workarounds[name]
+	return ok
 }
 
 func (ic *InputConfig) Validate() error {
 	// TODO more validation
-	for workaround := range ic.Workarounds {
-		switch workaround {
-		case "Semantic Version Without V":
-		case "Semantic Version Prerelease Hack 1":
-		case "Tag Prefix":
-		case "Programs as Alternatives":
-		default:
-			return fmt.Errorf("unknown workaround: %s", workaround)
+	if err := validateWorkarounds(ic.Workarounds); err != nil {
+		return err
+	}
+	if ic.WorkaroundTagsWithoutReleases() && !ic.IsSource() {
+		switch {
+		case !ic.UsesReleaseUrl():
+			return fmt.Errorf("workaround %s is only used by the source, binary and AppImage types", tagsWithoutReleasesWorkaround.Name)
+		case ic.IsGitlab() || ic.IsGitea():
+			return fmt.Errorf("workaround %s is only used by the GitHub types", tagsWithoutReleasesWorkaround.Name)
+		case ic.RollingTag() != nil:
+			return fmt.Errorf("workaround %s can't be used with workaround %s, which versions the release of one tag", tagsWithoutReleasesWorkaround.Name, rollingTagWorkaround.Name)
+		case ic.ReleaseUrl == "":
+			return fmt.Errorf("workaround %s has no releases to download the release files from, set ReleaseUrl to where they are", tagsWithoutReleasesWorkaround.Name)
 		}
 	}
+	if ic.ReleaseUrl != "" && ic.IsGitlab() {
+		return fmt.Errorf("ReleaseUrl isn't used by the GitLab types, the release files are the links of the release")
+	}
+	if ic.LiveEbuildBuildSystem() != "" && (ic.IsGitlab() || ic.IsGitea()) {
+		return fmt.Errorf("workaround %s is only used by the GitHub types", liveEbuildWorkaround.Name)
+	}
+	if rt := ic.RollingTag(); rt != nil {
+		switch {
+		case ic.IsSource(), ic.IsLlamafile():
+			return fmt.Errorf("workaround %s is only used by the binary and AppImage types", rollingTagWorkaround.Name)
+		case rt.BuildNumber() && ic.RollingTagBuildNumberPattern() == "":
+			return fmt.Errorf("workaround %s takes the version from the build number, put ${BUILD} in a release filename where it is", rollingTagWorkaround.Name)
+		}
+	}
+	if ic.IsLlamafile() {
+		binaries := 0
+		for _, program := range ic.Programs {
+			for keyword, binary := range program.Binary {
+				binaries++
+				if len(binary) != 2 {
+					return fmt.Errorf("program %s's %s Binary is installed straight from the release file, use `Binary %s=>release-filename > installed-name`", program.ProgramName, keyword, keyword)
+				}
+			}
+		}
+		if binaries == 0 {
+			return fmt.Errorf("there are no Binary lines, add `Binary amd64=>release-filename > installed-name` for each llamafile")
+		}
+	}
+	for _, name := range ic.EbuildVariableNames() {
+		if slices.Contains(generatedEbuildVariables, name) {
+			return fmt.Errorf("EbuildVariable %s is generated from the rest of the entry and can't be set", name)
+		}
+	}
+	return nil
+}
+
+// ValidateInstallPath returns an error if the path can't be used as the directory a program is installed into.
+func ValidateInstallPath(installPath string) error {
+	switch {
+	case !path.IsAbs(installPath):
+		return fmt.Errorf("install path %s must be absolute, such as %s", installPath, DefaultInstallPath)
+	case strings.ContainsAny(installPath, " \t'\"\\$`"):
+		return fmt.Errorf("install path %s can't contain spaces, quotes, `$` or backslashes", installPath)
+	}
 	return nil
 }
 
-//nolint:unused
 func parseMapType1(a []string) (map[string]string, error) {
 	result := make(map[string]string, len(a))
 	for i, v := range a {
@@ -694,7 +1390,7 @@ func parseDoubleMapStringListType1(a []string) (map[string]map[string][]string,
 			return nil, fmt.Errorf("entry %d, can't split %#v", i, line)
 		}
 		keySplit := strings.Split(kvSplit[0], ":")
-		if len(kvSplit) != 2 {
+		if len(keySplit) != 2 {
 			return nil, fmt.Errorf("entry %d, can't split key %#v", i, kvSplit[0])
 		}
 		for _, eps := range strings.Split(strings.TrimSpace(kvSplit[1]), ">") {
@@ -722,6 +1418,15 @@ func parseOptionalMapType1(a []string) (map[string]string, error) {
 	return result, nil
 }
 
+// cutKey returns the rest of the line if it starts with the key as a whole word.
+func cutKey(line, key string) (string, bool) {
+	value, ok := strings.CutPrefix(line, key)
+	if !ok || (value != "" && !unicode.IsSpace(rune(value[0]))) {
+		return "", false
+	}
+	return strings.TrimSpace(value), true
+}
+
 func emptyOrOnlyOrFail(i []string) (string, error) {
 	switch len(i) {
 	case 0:
@@ -739,9 +1444,7 @@ func emptyOrAppendStringArray(o []string, i []string) ([]string, error) {
 	}
 	if len(i) > 0 {
 		for _, e := range i {
-			for _, s := range strings.Split(e, " ") {
-				o = append(o, strings.TrimSpace(s))
-			}
+			o = append(o, strings.Fields(e)...)
 		}
 	}
 	return o, nil
@@ -767,53 +1470,60 @@ func onlyOrFail(i []string) (string, error) {
 	}
 }
 
+// AppendToConfigurationFile adds the entry to the end of the configuration file, the rest of the file is kept as is.
 func AppendToConfigurationFile(config string, ic *InputConfig) error {
-	f, err := os.OpenFile(config, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	doc, err := LoadConfigDocument(config)
 	if err != nil {
-		return fmt.Errorf("opening configuration file to append: %w", err)
+		return fmt.Errorf("loading configuration file to append: %w", err)
 	}
-
-	if _, err := f.WriteString("\n" + ic.String() + "\n"); err != nil {
+	if len(doc.Blocks) == 0 {
+		doc.SetConfigVersion(CurrentConfigVersion)
+	}
+	doc.AppendEntry(ic)
+	if err := doc.Save(); err != nil {
 		return fmt.Errorf("writing: %w", err)
 	}
-
-	defer func() {
-		if err := f.Close(); err != nil {
-			log.Printf("Error closing file: %s", err)
-		}
-	}()
 	return nil
 }
 
+// ReadConfigurationFile reads the configuration file and any files it includes, a missing file is treated as empty.
 func ReadConfigurationFile(configFn string) ([]*InputConfig, error) {
-	var config []*InputConfig
-	f, err := os.Open(configFn)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return nil, fmt.Errorf("opening configuration file: %w", err)
-	} else if err == nil {
-		config, err = ParseInputConfigReader(f)
-		if err != nil {
-			return nil, fmt.Errorf("parsing configuration file: %w", err)
-		}
-		defer func() {
-			if err := f.Close(); err != nil {
-				log.Printf("Error closing file: %s", err)
-			}
-		}()
-	} else {
-		config = make([]*InputConfig, 0)
+	if _, err := os.Stat(configFn); errors.Is(err, os.ErrNotExist) {
+		return make([]*InputConfig, 0), nil
+	}
+	config, err := ReadConfigurationTree(configFn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing configuration file: %w", err)
 	}
 	return config, nil
 }
 
 func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix, sourceType string) (string, *InputConfig, []string, []string, *github.RepositoryRelease, *InputConfig, error) {
 	client := github.NewClient(nil)
-	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
-		client = client.WithAuthToken(token)
-	}
-	ownerName, repoName, err := util.ExtractGithubOwnerRepo(gitRepo)
-	if err != nil {
-		return "", nil, nil, nil, nil, nil, fmt.Errorf("github url parse: %w", err)
+	isGitea := strings.HasPrefix(sourceType, "Gitea ")
+	var ownerName, repoName, giteaBaseUrl string
+	var err error
+	if isGitea {
+		// Gitea, Forgejo and Codeberg serve a GitHub compatible API for repositories and releases under /api/v1/
+		giteaBaseUrl, ownerName, repoName, err = util.ExtractGiteaOwnerRepo(gitRepo)
+		if err != nil {
+			return "", nil, nil, nil, nil, nil, fmt.Errorf("gitea url parse: %w", err)
+		}
+		client.BaseURL, err = url.Parse(giteaBaseUrl + "/api/v1/")
+		if err != nil {
+			return "", nil, nil, nil, nil, nil, fmt.Errorf("gitea api url parse: %w", err)
+		}
+		if token, ok := os.LookupEnv("GITEA_TOKEN"); ok {
+			client = client.WithAuthToken(token)
+		}
+	} else {
+		if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+			client = client.WithAuthToken(token)
+		}
+		ownerName, repoName, err = util.ExtractGithubOwnerRepo(gitRepo)
+		if err != nil {
+			return "", nil, nil, nil, nil, nil, fmt.Errorf("github url parse: %w", err)
+		}
 	}
 	log.Printf("Getting details for %s's %s", ownerName, repoName)
 	ctx := context.Background()
@@ -824,6 +1534,10 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 	var licenseName *string
 	if repo.License != nil {
 		licenseName = repo.License.Name
+		// The SPDX identifier maps to the Gentoo license name written to LICENSE
+		if spdxId := repo.License.GetSPDXID(); spdxId != "" && spdxId != "NOASSERTION" {
+			licenseName = &spdxId
+		}
 	}
 	ebuildNamePart := strings.ReplaceAll(repoName, ".", "-")
 	ic := &InputConfig{
@@ -839,6 +1553,16 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 		Programs:    map[string]*Program{},
 		License:     util.StringOrDefault(licenseName, "unknown"),
 	}
+	if isGitea {
+		ic.GithubProjectUrl, ic.GithubOwner, ic.GithubRepo = "", "", ""
+		ic.GiteaProjectUrl = gitRepo
+		ic.GiteaBaseUrl = giteaBaseUrl
+		ic.GiteaOwner = ownerName
+		ic.GiteaRepo = repoName
+		if ic.Homepage == "" {
+			ic.Homepage = repo.GetHTMLURL()
+		}
+	}
 	var versions = []string{}
 	var tags = []string{}
 	if tagOverride != "" {
@@ -865,12 +1589,23 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 				continue
 			}
 			if v.Prerelease() != "" {
-				ic.Workarounds["Semantic Version Prerelease Hack 1"] = ""
+				ic.Workarounds[semanticVersionPrereleaseHack1Workaround.Name] = ""
 			}
 			if releaseInfo == nil {
 				releaseInfo = release
 			}
 		}
+		if releaseInfo == nil && len(releasesList) == 0 && ic.IsSource() {
+			log.Printf("%s has no releases, using its tags", repoName)
+			releaseInfo, err = latestTagRelease(ctx, client, ownerName, repoName, tagPrefix)
+			if err != nil {
+				return "", nil, nil, nil, nil, nil, err
+			}
+			ic.Workarounds[tagsWithoutReleasesWorkaround.Name] = ""
+		}
+		if releaseInfo == nil && len(releasesList) == 0 && ic.UsesReleaseUrl() {
+			return "", nil, nil, nil, nil, nil, fmt.Errorf("%s has no releases, if its release files are somewhere else write the entry by hand with `Workaround %s` and a ReleaseUrl of where they are", repoName, tagsWithoutReleasesWorkaround.Name)
+		}
 		if releaseInfo == nil {
 			releaseInfo, _, err = client.Repositories.GetLatestRelease(ctx, ownerName, repoName)
 			if err != nil {
@@ -885,7 +1620,7 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 				return "", nil, nil, nil, nil, nil, fmt.Errorf("github latest release tag %s doesn't have prefix %s", tag, tagPrefix)
 			}
 			tag = strings.TrimPrefix(tag, tagPrefix)
-			ic.Workarounds["Tag Prefix"] = tagPrefix
+			ic.Workarounds[tagPrefixWorkaround.Name] = tagPrefix
 		}
 		v, err := semver.NewVersion(tag)
 		if err != nil {
@@ -896,15 +1631,21 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 			versions = []string{v.String()}
 		} else {
 			tags = []string{originalTag}
-			ic.Workarounds["Semantic Version Without V"] = ""
+			ic.Workarounds[semanticVersionWithoutVWorkaround.Name] = ""
 		}
 	} else {
 		releaseInfo, _, err = client.Repositories.GetReleaseByTag(ctx, ownerName, repoName, tagOverride)
+		if err != nil && ic.IsSource() {
+			// The source types only need the tag
+			log.Printf("No release for tag %s, using the tag: %s", tagOverride, err)
+			releaseInfo, err = &github.RepositoryRelease{TagName: github.String(tagOverride)}, nil
+			ic.Workarounds[tagsWithoutReleasesWorkaround.Name] = ""
+		}
 		if err != nil {
 			return "", nil, nil, nil, nil, nil, fmt.Errorf("github latest release fetch: %w", err)
 		}
 		if !strings.HasPrefix(tagOverride, "v") {
-			ic.Workarounds["Semantic Version Without V"] = ""
+			ic.Workarounds[semanticVersionWithoutVWorkaround.Name] = ""
 		}
 		tag := releaseInfo.GetTagName()
 		if tagPrefix != "" {
@@ -912,17 +1653,56 @@ func NewInputConfigurationFromRepo(gitRepo, tagOverride, tagPrefix, ebuildSuffix
 				return "", nil, nil, nil, nil, nil, fmt.Errorf("github latest release tag %s doesn't have prefix %s", tag, tagPrefix)
 			}
 			tag = strings.TrimPrefix(tag, tagPrefix)
-			ic.Workarounds["Tag Prefix"] = tagPrefix
+			ic.Workarounds[tagPrefixWorkaround.Name] = tagPrefix
 		}
 		v, err := semver.NewVersion(tag)
 		if err != nil {
 			return "", nil, nil, nil, nil, nil, fmt.Errorf("github latest release tag parse %s: %w", tag, err)
 		}
 		if v.Prerelease() != "" {
-			ic.Workarounds["Semantic Version Prerelease Hack 1"] = ""
+			ic.Workarounds[semanticVersionPrereleaseHack1Workaround.Name] = ""
+		}
+		if strings.HasPrefix(tag, "v") {
+			versions = []string{v.String()}
 		}
 	}
 
 	log.Printf("Latest release %v", versions)
 	return repoName, ic, versions, tags, releaseInfo, nil, nil
 }
+
+// latestTagRelease stands in for the latest release of a project which only pushes tags, it is the tag with the prefix
+// which is the highest semantic version. Tags are listed by name rather than version so every page is read.
+func latestTagRelease(ctx context.Context, client *github.Client, ownerName, repoName, tagPrefix string) (*github.RepositoryRelease, error) {
+	var tags []*github.RepositoryTag
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Repositories.ListTags(ctx, ownerName, repoName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("github list tags fetch: %w", err)
+		}
+		tags = append(tags, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	var latest *github.RepositoryTag
+	var latestVersion *semver.Version
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.GetName(), tagPrefix) {
+			continue
+		}
+		v, err := semver.NewVersion(strings.TrimPrefix(tag.GetName(), tagPrefix))
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = tag, v
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%s has no releases and no tags which are semantic versions", repoName)
+	}
+	return &github.RepositoryRelease{TagName: latest.Name}, nil
+}
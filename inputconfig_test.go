@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"strings"
 	"testing"
 )
 
@@ -220,6 +221,34 @@ func TestParseConfigFile(t *testing.T) {
 	}
 }
 
+func TestParseListFieldSpacing(t *testing.T) {
+	ics, err := ParseInputConfigReader(bytes.NewBufferString("Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nDependencies sys-libs/glibc   dev-libs/openssl\tdev-libs/libgit2\nBinary amd64=>g2_linux_amd64.tar.gz > g2\n"))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	var got []string
+	for _, p := range ics[0].Programs {
+		got = append(got, p.Dependencies...)
+	}
+	// Splitting on single spaces made the repeated spaces empty dependencies in RDEPEND and kept the tab in one
+	want := []string{"sys-libs/glibc", "dev-libs/openssl", "dev-libs/libgit2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseShellCompletionScriptKeyword(t *testing.T) {
+	for _, keyword := range []string{"amd64", "amd64:bash:zsh"} {
+		t.Run(keyword, func(t *testing.T) {
+			// Checking the number of parts of the value rather than the key let a keyword without a shell panic
+			_, err := ParseInputConfigReader(bytes.NewBufferString("Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nBinary amd64=>g2_linux_amd64.tar.gz > g2\nShellCompletionScript " + keyword + "=>g2_linux_amd64.tar.gz > g2.bash > g2\n"))
+			if err == nil || !strings.Contains(err.Error(), "can't split key") {
+				t.Errorf("ParseInputConfigReader() error = %v, want a can't split key error", err)
+			}
+		})
+	}
+}
+
 func TestConfigString(t *testing.T) {
 	for _, test := range []struct {
 		name   string
@@ -346,3 +375,54 @@ Binary x86=>goreleaser_Linux_i386.tar.gz > goreleaser > goreleaser
 		})
 	}
 }
+
+const testProgramDetailsData = `Type Github Binary Release
+GithubProjectUrl https://github.com/probonopd/go-appimage
+Category app-misc
+Description Go implementation of AppImage tools
+License MIT License
+Workaround Programs as Alternatives => amd64:appimaged amd64:appimagetool
+ProgramName appimaged
+ProgramDescription Optional AppImage daemon for desktop integration
+InstallPath /usr/bin
+Binary amd64=>appimaged-${VERSION}-x86_64.tar.gz > appimaged > appimaged
+ProgramName appimagetool
+ProgramDescription Tool to generate AppImages from AppDirs
+ProgramHomepage https://github.com/probonopd/go-appimage/tree/master/src/appimagetool
+ProgramLicense Apache License 2.0
+Binary amd64=>appimagetool-${VERSION}-x86_64.tar.gz > appimagetool > appimagetool
+`
+
+func TestParseProgramDetails(t *testing.T) {
+	ics, err := ParseInputConfigReader(bytes.NewReader([]byte(testProgramDetailsData)))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	appimaged := ics[0].Programs["appimaged"]
+	appimagetool := ics[0].Programs["appimagetool"]
+	if appimaged == nil || appimagetool == nil {
+		t.Fatalf("Programs = %v, want appimaged and appimagetool", ics[0].Programs)
+	}
+	if appimaged.Description != "Optional AppImage daemon for desktop integration" || appimaged.InstallDirectory() != "/usr/bin" {
+		t.Errorf("appimaged = %+v", appimaged)
+	}
+	if appimagetool.License != "Apache License 2.0" || appimagetool.InstallDirectory() != DefaultInstallPath {
+		t.Errorf("appimagetool = %+v", appimagetool)
+	}
+	if ics[0].Description != "Go implementation of AppImage tools" || ics[0].License != "MIT License" {
+		t.Errorf("entry Description = %s, License = %s, want the entry's own", ics[0].Description, ics[0].License)
+	}
+	reparsed, err := ParseInputConfigReader(bytes.NewReader([]byte(ics[0].String())))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() of String() error = %v", err)
+	}
+	if diff := cmp.Diff(ics[0].Programs, reparsed[0].Programs); diff != "" {
+		t.Errorf("Programs after String() mismatch (-want +got):\n%s", diff)
+	}
+	for _, installPath := range []string{"bin", "/opt/my bin", "/opt/$HOME"} {
+		input := fmt.Sprintf("Type Github Binary Release\nGithubProjectUrl https://github.com/arran4/g2\nInstallPath %s\nBinary amd64=>g2 > g2\n", installPath)
+		if _, err := ParseInputConfigReader(bytes.NewReader([]byte(input))); err == nil {
+			t.Errorf("ParseInputConfigReader() with InstallPath %q should fail", installPath)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"strings"
+)
+
+var (
+	// gentooLicenseMap maps the lower case SPDX identifiers, GitHub and GitLab license keys and names, and Gentoo
+	// license names of common licenses to the Gentoo license name.
+	gentooLicenseMap = map[string]string{
+		"mit":                                    "MIT",
+		"mit license":                            "MIT",
+		"apache-2.0":                             "Apache-2.0",
+		"apache license 2.0":                     "Apache-2.0",
+		"gpl-2":                                  "GPL-2",
+		"gpl-2.0":                                "GPL-2",
+		"gpl-2.0-only":                           "GPL-2",
+		"gnu general public license v2.0":        "GPL-2",
+		"gpl-2+":                                 "GPL-2+",
+		"gpl-2.0-or-later":                       "GPL-2+",
+		"gpl-3":                                  "GPL-3",
+		"gpl-3.0":                                "GPL-3",
+		"gpl-3.0-only":                           "GPL-3",
+		"gnu general public license v3.0":        "GPL-3",
+		"gpl-3+":                                 "GPL-3+",
+		"gpl-3.0-or-later":                       "GPL-3+",
+		"lgpl-2.1":                               "LGPL-2.1",
+		"lgpl-2.1-only":                          "LGPL-2.1",
+		"gnu lesser general public license v2.1": "LGPL-2.1",
+		"lgpl-2.1+":                              "LGPL-2.1+",
+		"lgpl-2.1-or-later":                      "LGPL-2.1+",
+		"lgpl-3":                                 "LGPL-3",
+		"lgpl-3.0":                               "LGPL-3",
+		"lgpl-3.0-only":                          "LGPL-3",
+		"gnu lesser general public license v3.0": "LGPL-3",
+		"lgpl-3+":                                "LGPL-3+",
+		"lgpl-3.0-or-later":                      "LGPL-3+",
+		"agpl-3":                                 "AGPL-3",
+		"agpl-3.0":                               "AGPL-3",
+		"agpl-3.0-only":                          "AGPL-3",
+		"gnu affero general public license v3.0": "AGPL-3",
+		"agpl-3+":                                "AGPL-3+",
+		"agpl-3.0-or-later":                      "AGPL-3+",
+		"bsd":                                    "BSD",
+		"bsd-3-clause":                           "BSD",
+		"bsd 3-clause \"new\" or \"revised\" license": "BSD",
+		"bsd-2":                                "BSD-2",
+		"bsd-2-clause":                         "BSD-2",
+		"bsd 2-clause \"simplified\" license":  "BSD-2",
+		"mpl-2.0":                              "MPL-2.0",
+		"mozilla public license 2.0":           "MPL-2.0",
+		"isc":                                  "ISC",
+		"isc license":                          "ISC",
+		"unlicense":                            "Unlicense",
+		"the unlicense":                        "Unlicense",
+		"cc0-1.0":                              "CC0-1.0",
+		"creative commons zero v1.0 universal": "CC0-1.0",
+		"boost-1.0":                            "Boost-1.0",
+		"bsl-1.0":                              "Boost-1.0",
+		"boost software license 1.0":           "Boost-1.0",
+		"epl-2.0":                              "EPL-2.0",
+		"eclipse public license 2.0":           "EPL-2.0",
+		"zlib":                                 "ZLIB",
+		"zlib license":                         "ZLIB",
+		"wtfpl":                                "WTFPL-2",
+		"wtfpl-2":                              "WTFPL-2",
+	}
+	// unknownLicenseNames are the names used when the license isn't known.
+	unknownLicenseNames = map[string]struct{}{
+		DefaultLicense: {},
+		"other":        {},
+		"noassertion":  {},
+	}
+)
+
+// GentooLicense returns the Gentoo name for the license, or an empty string if it isn't known. A single word which
+// isn't one of the common licenses is taken to be a Gentoo name already, a license of several words is either a
+// license's name or a LICENSE expression of the common licenses.
+func GentooLicense(license string) string {
+	license = strings.TrimSpace(license)
+	if r, ok := gentooLicenseMap[strings.ToLower(license)]; ok {
+		return r
+	}
+	words := strings.Fields(license)
+	if len(words) == 1 {
+		if _, ok := unknownLicenseNames[strings.ToLower(license)]; ok {
+			return ""
+		}
+		return license
+	}
+	for i, word := range words {
+		switch word {
+		case "||", "(", ")":
+			continue
+		}
+		r, ok := gentooLicenseMap[strings.ToLower(word)]
+		if !ok {
+			return ""
+		}
+		words[i] = r
+	}
+	return strings.Join(words, " ")
+}
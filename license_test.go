@@ -0,0 +1,36 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"testing"
+)
+
+func TestGentooLicense(t *testing.T) {
+	for _, test := range []struct {
+		license string
+		want    string
+	}{
+		{license: "MIT", want: "MIT"},
+		{license: "MIT License", want: "MIT"},
+		{license: "mit", want: "MIT"},
+		{license: "Apache License 2.0", want: "Apache-2.0"},
+		{license: "apache-2.0", want: "Apache-2.0"},
+		{license: "GPL-3.0", want: "GPL-3"},
+		{license: "gpl-3.0-or-later", want: "GPL-3+"},
+		{license: "GNU General Public License v2.0", want: "GPL-2"},
+		{license: "BSD-3-Clause", want: "BSD"},
+		{license: `BSD 2-Clause "Simplified" License`, want: "BSD-2"},
+		{license: "BSL-1.0", want: "Boost-1.0"},
+		{license: "|| ( MIT Apache-2.0 )", want: "|| ( MIT Apache-2.0 )"},
+		{license: "mit apache-2.0", want: "MIT Apache-2.0"},
+		{license: "Artistic-2", want: "Artistic-2"},
+		{license: "unknown", want: ""},
+		{license: "Other", want: ""},
+		{license: "NOASSERTION", want: ""},
+		{license: "Some Company License", want: ""},
+		{license: "", want: ""},
+	} {
+		if got := GentooLicense(test.license); got != test.want {
+			t.Errorf("GentooLicense(%q) = %q, want %q", test.license, got, test.want)
+		}
+	}
+}
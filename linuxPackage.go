@@ -0,0 +1,164 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"io"
+	"log"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// packageBinaryDirectories are the directories of a .deb or .rpm which are on the PATH
+	packageBinaryDirectories = []string{"usr/bin", "usr/sbin", "bin", "sbin", "usr/games", "usr/local/bin"}
+	// packageManualPageDirectory is a section directory of the manual pages of a package, like usr/share/man/man1
+	packageManualPageDirectory = regexp.MustCompile(`^usr/share/man/man([1-9])$`)
+	// PackageIconLocations are the Icons of a program from a .deb or .rpm, the hicolor theme in usr/share/icons or
+	// usr/share/pixmaps
+	PackageIconLocations = []string{"hicolor-apps", "pixmaps"}
+)
+
+// packageFiles collects the files of the payload of a .deb or .rpm, the files the package installs.
+type packageFiles struct {
+	container *BinaryReleaseFileInfo
+	files     map[string]*BinaryReleaseFileInfo
+	symlinks  map[string]string
+	ordered   []*BinaryReleaseFileInfo
+}
+
+func newPackageFiles(container *BinaryReleaseFileInfo) *packageFiles {
+	return &packageFiles{
+		container: container,
+		files:     map[string]*BinaryReleaseFileInfo{},
+		symlinks:  map[string]string{},
+	}
+}
+
+// packagePath is the path of a payload file without the leading `./` or `/` packages are built with.
+func packagePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// addFile saves a regular file of the payload to a temp file.
+func (pf *packageFiles) addFile(name string, executable bool, r io.Reader) error {
+	name = packagePath(name)
+	tmpFile, err := util.SaveReaderToTempFile(r)
+	if err != nil {
+		return fmt.Errorf("extracting file %s: %w", name, err)
+	}
+	dir, fn := path.Split(name)
+	pf.files[name] = &BinaryReleaseFileInfo{
+		Container:       pf.container,
+		ArchivePathname: name,
+		DirectoryName:   dir,
+		Filename:        fn,
+		tempFile:        tmpFile,
+		DownloadUrl:     pf.container.DownloadUrl,
+		ExecutableBit:   executable,
+	}
+	pf.ordered = append(pf.ordered, pf.files[name])
+	return nil
+}
+
+// addSymlink records a symlink of the payload, absolute links are to paths in the package.
+func (pf *packageFiles) addSymlink(name, linkname string) {
+	name = packagePath(name)
+	if strings.HasPrefix(linkname, "/") {
+		pf.symlinks[name] = packagePath(linkname)
+	} else {
+		pf.symlinks[name] = path.Join(path.Dir(name), linkname)
+	}
+}
+
+// Files are the regular files of the payload followed by the symlinks to them. Programs in /opt are often linked into
+// /usr/bin, the link is the installed name and the target is what is installed.
+func (pf *packageFiles) Files() []*BinaryReleaseFileInfo {
+	result := slices.Clone(pf.ordered)
+	for _, name := range slices.Sorted(maps.Keys(pf.symlinks)) {
+		target, ok := pf.files[pf.symlinks[name]]
+		if !ok {
+			continue
+		}
+		dir, fn := path.Split(name)
+		result = append(result, &BinaryReleaseFileInfo{
+			Container:       pf.container,
+			ArchivePathname: target.ArchivePathname,
+			DirectoryName:   dir,
+			Filename:        fn,
+			tempFile:        target.tempFile,
+			DownloadUrl:     pf.container.DownloadUrl,
+			ExecutableBit:   target.ExecutableBit,
+		})
+	}
+	return result
+}
+
+// FindPackageFiles sorts the files of a .deb or .rpm by where they are installed rather than by their names, binaries
+// are the executables on the PATH, the desktop files, icons and manual pages are in their usual places under usr/share.
+func (bases BinaryReleaseFiles) FindPackageFiles(pkg *BinaryReleaseFileInfo, root *FileTypes) *FileTypes {
+	result := &FileTypes{
+		CompressedArchives:       []*BinaryReleaseFileInfo{},
+		Binaries:                 []*BinaryReleaseFileInfo{},
+		MightBeBinaries:          []*BinaryReleaseFileInfo{},
+		ManualPages:              []*BinaryReleaseFileInfo{},
+		ShellCompletionScripts:   []*BinaryReleaseFileInfo{},
+		CompressedArchiveContent: map[string]*FileTypes{},
+		Root:                     root,
+	}
+	for _, base := range bases {
+		dir := strings.TrimSuffix(base.DirectoryName, "/")
+		compiled := &BinaryReleaseFileInfo{
+			Container:        pkg,
+			ProgramName:      base.Filename,
+			OriginalFilename: base.Filename,
+			ArchivePathname:  base.ArchivePathname,
+			InstalledName:    base.Filename,
+			Filename:         base.Filename,
+			DirectoryName:    base.DirectoryName,
+			ExecutableBit:    base.ExecutableBit,
+			Keyword:          pkg.Keyword,
+			KeywordDefaulted: pkg.KeywordDefaulted,
+			OS:               pkg.OS,
+			Toolchain:        pkg.Toolchain,
+			DownloadUrl:      pkg.DownloadUrl,
+			tempFile:         base.tempFile,
+			container:        result,
+		}
+		switch {
+		case slices.Contains(packageBinaryDirectories, dir) && base.ExecutableBit:
+			if ok, err := compiled.CheckMaybe(); err != nil || !ok {
+				log.Printf("%s is not an ELF binary - ignoring", base.ArchivePathname)
+				continue
+			}
+			if !strings.HasPrefix(compiled.ArchivePathname, dir+"/") {
+				log.Printf("%s links to %s, which may need the rest of its directory installed", path.Join(dir, base.Filename), compiled.ArchivePathname)
+			}
+			compiled.Binary = true
+			result.Binaries = append(result.Binaries, compiled)
+		case dir == "usr/share/applications" && strings.HasSuffix(base.Filename, ".desktop"):
+			log.Printf("%s is a desktop file", base.ArchivePathname)
+			result.DesktopFiles = append(result.DesktopFiles, compiled)
+		case dir == "usr/share/icons/hicolor" || strings.HasPrefix(dir, "usr/share/icons/hicolor/"):
+			if !slices.Contains(result.Icons, "hicolor-apps") {
+				result.Icons = append(result.Icons, "hicolor-apps")
+			}
+		case dir == "usr/share/pixmaps":
+			if !slices.Contains(result.Icons, "pixmaps") {
+				result.Icons = append(result.Icons, "pixmaps")
+			}
+		case packageManualPageDirectory.MatchString(dir):
+			log.Printf("%s is a manual page", base.ArchivePathname)
+			compiled.ManualPage, _ = strconv.Atoi(packageManualPageDirectory.FindStringSubmatch(dir)[1])
+			if ext := path.Ext(base.Filename); ext == ".gz" || ext == ".bz2" {
+				compiled.Containers = []string{strings.TrimPrefix(ext, ".")}
+			}
+			result.ManualPages = append(result.ManualPages, compiled)
+		}
+	}
+	return result
+}
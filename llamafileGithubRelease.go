@@ -0,0 +1,148 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"log"
+	"slices"
+	"strings"
+)
+
+const (
+	// LlamafileCategory is the category detected llamafile entries are put in.
+	LlamafileCategory = "dev-ml"
+)
+
+var (
+	// ApeMagics are how an Actually Portable Executable starts. The first is also the MZ header of a Windows
+	// executable, which is why the binfmt_misc handlers of WINE can claim them, the others are APE builds without
+	// Windows support and debug builds.
+	ApeMagics = [][]byte{
+		[]byte("MZqFpD='"),
+		[]byte("jartsr='"),
+		[]byte("APEDBG='"),
+	}
+	// ApeKeywords are the keywords of a llamafile, an APE runs on both.
+	ApeKeywords = []string{
+		"amd64",
+		"arm64",
+	}
+	// llamafileSkippedExtensions are release files which are archives, checksums, or copies named for Windows rather
+	// than llamafiles, they aren't fetched to check.
+	llamafileSkippedExtensions = []string{
+		".zip",
+		".tar",
+		".gz",
+		".tgz",
+		".xz",
+		".bz2",
+		".zst",
+		".exe",
+		".sha256",
+		".sha256sum",
+		".sig",
+		".asc",
+		".txt",
+		".md",
+	}
+)
+
+// IsApe is true if the start of a file is the magic of an Actually Portable Executable.
+func IsApe(prefix []byte) bool {
+	for _, magic := range ApeMagics {
+		if bytes.HasPrefix(prefix, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+func ConfigAddLlamafileGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("Github", toConfig, "Github Llamafile Release", gitRepo, tagOverride, tagPrefix)
+}
+
+func ConfigViewLlamafileGithubReleases(gitRepo, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("Github", "Github Llamafile Release", gitRepo, tagOverride, tagPrefix)
+}
+
+// GenerateLlamafileGithubReleaseConfigEntry finds the llamafiles of the release by the APE magic they start with. They
+// are often several gigabytes so only the start of each release file is fetched.
+func GenerateLlamafileGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string) (*InputConfig, error) {
+	_, ic, versions, tags, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "-bin", "Github Llamafile Release")
+	if err != nil {
+		return config, err
+	}
+	ic.Category = LlamafileCategory
+	if len(versions) == 0 {
+		// Tags without a v are the version
+		for _, tag := range tags {
+			versions = append(versions, strings.TrimPrefix(tag, ic.WorkaroundTagPrefix()))
+		}
+	}
+	var files []*BinaryReleaseFileInfo
+	for _, asset := range releaseInfo.Assets {
+		files = append(files, &BinaryReleaseFileInfo{
+			Filename:    asset.GetName(),
+			DownloadUrl: asset.GetBrowserDownloadURL(),
+		})
+	}
+	return detectLlamafileReleaseConfigEntry(ic, versions, files)
+}
+
+// detectLlamafileReleaseConfigEntry adds a program for each release file which is an APE, the filename decoding of the
+// binary type isn't used as models are named for the model rather than the project.
+func detectLlamafileReleaseConfigEntry(ic *InputConfig, versions []string, files []*BinaryReleaseFileInfo) (*InputConfig, error) {
+	if ic.Programs == nil {
+		ic.Programs = map[string]*Program{}
+	}
+	for _, file := range files {
+		if slices.ContainsFunc(llamafileSkippedExtensions, func(ext string) bool {
+			return strings.HasSuffix(strings.ToLower(file.Filename), ext)
+		}) {
+			log.Printf("Skipping %s", file.Filename)
+			continue
+		}
+		log.Printf("Is %s a llamafile?", file.Filename)
+		prefix, err := util.ReadUrlPrefix(file.DownloadUrl, len(ApeMagics[0]))
+		if err != nil {
+			return nil, fmt.Errorf("reading the start of %s: %w", file.DownloadUrl, err)
+		}
+		if !IsApe(prefix) {
+			log.Printf("Is %s a llamafile? - No", file.Filename)
+			continue
+		}
+		log.Printf("Is %s a llamafile? - Yes", file.Filename)
+		releaseFilename := file.Filename
+		for _, version := range versions {
+			releaseFilename = strings.ReplaceAll(releaseFilename, version, "${VERSION}")
+		}
+		installedName := LlamafileInstalledName(releaseFilename)
+		programName := strings.TrimSuffix(installedName, ".llamafile")
+		if _, ok := ic.Programs[programName]; ok {
+			log.Printf("Already have %s, skipping %s", programName, file.Filename)
+			continue
+		}
+		p := &Program{
+			ProgramName:  programName,
+			Binary:       map[string][]string{},
+			Dependencies: []string{},
+		}
+		for _, keyword := range ApeKeywords {
+			p.Binary[keyword] = []string{releaseFilename, installedName}
+		}
+		ic.Programs[programName] = p
+	}
+	if len(ic.Programs) == 0 {
+		return nil, fmt.Errorf("no llamafiles found, none of the release files start with the APE magic %q", ApeMagics[0])
+	}
+	return ic, nil
+}
+
+// LlamafileInstalledName is the name a release file is installed as, the release filename without the version.
+func LlamafileInstalledName(releaseFilename string) string {
+	for _, version := range []string{"-${VERSION}", "_${VERSION}", ".${VERSION}", "${VERSION}"} {
+		releaseFilename = strings.ReplaceAll(releaseFilename, version, "")
+	}
+	return releaseFilename
+}
@@ -0,0 +1,162 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsApe(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   bool
+	}{
+		{name: "APE", prefix: "MZqFpD='\n\n\x00", want: true},
+		{name: "APE without Windows support", prefix: "jartsr='\n", want: true},
+		{name: "APE debug build", prefix: "APEDBG='\n", want: true},
+		{name: "Windows executable", prefix: "MZ\x90\x00\x03\x00\x00\x00", want: false},
+		{name: "ELF", prefix: "\x7fELF\x02\x01\x01\x00", want: false},
+		{name: "Short", prefix: "MZq", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsApe([]byte(tt.prefix)); got != tt.want {
+				t.Errorf("IsApe(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLlamafileReleaseConfigEntry(t *testing.T) {
+	content := map[string]string{
+		"/llamafile-0.8.13":                        "MZqFpD='\n\nexec 7<> \"$0\"",
+		"/whisperfile-0.8.13":                      "jartsr='\n\nexec 7<> \"$0\"",
+		"/zipalign-0.8.13":                         "\x7fELF\x02\x01\x01\x00",
+		"/Meta-Llama-3-8B-Instruct.Q4_0.llamafile": "MZqFpD='\n\nexec 7<> \"$0\"",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Range"), "bytes=0-7"; got != want {
+			t.Errorf("%s Range = %q, want %q", r.URL.Path, got, want)
+		}
+		if c, ok := content[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(c))
+			return
+		}
+		t.Errorf("%s shouldn't be fetched", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	var files []*BinaryReleaseFileInfo
+	for _, name := range []string{"llamafile-0.8.13", "whisperfile-0.8.13", "zipalign-0.8.13", "llamafile-0.8.13.zip", "Meta-Llama-3-8B-Instruct.Q4_0.llamafile", "llamafile-0.8.13.exe"} {
+		files = append(files, &BinaryReleaseFileInfo{Filename: name, DownloadUrl: server.URL + "/" + name})
+	}
+	ic := &InputConfig{Type: "Github Llamafile Release", GithubRepo: "llamafile", Workarounds: map[string]string{}}
+	ic, err := detectLlamafileReleaseConfigEntry(ic, []string{"0.8.13"}, files)
+	if err != nil {
+		t.Fatalf("detectLlamafileReleaseConfigEntry() error = %v", err)
+	}
+	got := map[string]map[string][]string{}
+	for name, p := range ic.Programs {
+		got[name] = p.Binary
+	}
+	want := map[string]map[string][]string{
+		"llamafile": {
+			"amd64": {"llamafile-${VERSION}", "llamafile"},
+			"arm64": {"llamafile-${VERSION}", "llamafile"},
+		},
+		"whisperfile": {
+			"amd64": {"whisperfile-${VERSION}", "whisperfile"},
+			"arm64": {"whisperfile-${VERSION}", "whisperfile"},
+		},
+		"Meta-Llama-3-8B-Instruct.Q4_0": {
+			"amd64": {"Meta-Llama-3-8B-Instruct.Q4_0.llamafile", "Meta-Llama-3-8B-Instruct.Q4_0.llamafile"},
+			"arm64": {"Meta-Llama-3-8B-Instruct.Q4_0.llamafile", "Meta-Llama-3-8B-Instruct.Q4_0.llamafile"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Binary mismatch (-want +got):\n%s", diff)
+	}
+
+	ic = &InputConfig{Type: "Github Llamafile Release", GithubRepo: "llamafile", Workarounds: map[string]string{}}
+	if _, err := detectLlamafileReleaseConfigEntry(ic, []string{"0.8.13"}, files[2:4]); err == nil {
+		t.Errorf("detectLlamafileReleaseConfigEntry() should fail without a llamafile")
+	}
+}
+
+const testLlamafileConfigData = `Type Github Llamafile Release
+GithubProjectUrl https://github.com/Mozilla-Ocho/llamafile
+Category dev-ml
+License Apache-2.0
+Workaround Semantic Version Without V
+ProgramName llamafile
+Binary amd64=>llamafile-${VERSION} > llamafile
+Binary arm64=>llamafile-${VERSION} > llamafile
+ProgramName zipalign
+Binary amd64=>zipalign-${VERSION} > zipalign
+`
+
+func TestParseLlamafileInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testLlamafileConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if ics[0].EbuildName != "llamafile-bin.ebuild" || !strings.Contains(ics[0].String(), "Binary arm64=>llamafile-${VERSION} > llamafile\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	for _, config := range []string{
+		"Type Github Llamafile Release\nGithubProjectUrl https://github.com/example/model\nBinary amd64=>model.zip > model.llamafile > model.llamafile\n",
+		"Type Github Llamafile Release\nGithubProjectUrl https://github.com/example/model\n",
+		"Type Github Llamafile Release\nGithubProjectUrl https://github.com/example/model\nWorkaround Rolling Tag => nightly published-date\nBinary amd64=>model.llamafile > model.llamafile\n",
+	} {
+		ics, err := ParseInputConfigReader(strings.NewReader(config))
+		if err == nil {
+			err = ics[0].Validate()
+		}
+		if err == nil {
+			t.Errorf("%q should fail", config)
+		}
+	}
+}
+
+func TestGenerateGithubLlamafileWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testLlamafileConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "dev-ml-llamafile-bin-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		`  keywords: ~amd64 ~arm64`,
+		`size="$(echo "${releases}" | jq -r --arg tag "${tag}" --arg name "${assetName}" 'first(.[] | select(.tag_name == $tag) | .assets[] | select(.name == $name) | .size) // empty')"`,
+		`echo 'LICENSE="Apache-2.0"'`,
+		`echo 'RESTRICT="strip"'`,
+		`echo "CHECKREQS_DISK_USR=\"$(( disk_usage / 1048576 + 1 ))M\""`,
+		"echo 'EAPI=8'\n                echo ''\n                echo 'inherit check-reqs'\n",
+		`echo "  https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/releases/download/${tag}/llamafile-\${PV} -> \${P}-llamafile-\${PV}"`,
+		`echo "  amd64? ( https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/releases/download/${tag}/zipalign-\${PV} -> \${P}-zipalign-\${PV} )"`,
+		`echo "  newexe \"\${DISTDIR}/\${P}-llamafile-\${PV}\" 'llamafile' || die \"Failed to install llamafile\""`,
+		`echo '  if use amd64; then'`,
+		`echo '  elog "  echo :APE:M::MZqFpD::/usr/bin/ape: > /proc/sys/fs/binfmt_misc/register"'`,
+		`g2 manifest upsert-from-url "https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/releases/download/${tag}/llamafile-${version}" "${{ env.epn }}-${version}-llamafile-${version}" "${ebuild_dir}/Manifest"`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("dev-ml-llamafile-bin-update.yaml doesn't contain %q", want)
+		}
+	}
+}
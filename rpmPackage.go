@@ -0,0 +1,266 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+	"io"
+	"log"
+	"strconv"
+)
+
+const (
+	// rpmLeadSize is the size of the obsolete lead an RPM starts with
+	rpmLeadSize = 96
+	// rpmTagPayloadCompressor is the header tag of the compression of the cpio payload
+	rpmTagPayloadCompressor = 1125
+	// rpmStringType is the header type of a NUL terminated string
+	rpmStringType = 6
+	// cpioHeaderSize is the size of the header of each file in a `newc` cpio archive
+	cpioHeaderSize = 110
+	// cpioTrailer is the name of the entry ending a cpio archive
+	cpioTrailer = "TRAILER!!!"
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// rpmPayloadReader is the uncompressed cpio payload of an RPM.
+type rpmPayloadReader struct {
+	io.Reader
+	closer func()
+}
+
+func (rpr *rpmPayloadReader) Close() error {
+	if rpr.closer != nil {
+		rpr.closer()
+	}
+	return nil
+}
+
+// OpenRpmPayload returns the uncompressed cpio payload of an RPM, the files which the package installs. The lead and
+// the signature header are skipped and the main header says how the payload is compressed: gzip, bzip2, xz, lzma or
+// zstd.
+func OpenRpmPayload(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	lead := make([]byte, rpmLeadSize)
+	if _, err := io.ReadFull(br, lead); err != nil || !bytes.Equal(lead[:4], rpmLeadMagic) {
+		return nil, fmt.Errorf("not an rpm")
+	}
+	if _, err := readRpmHeader(br, true); err != nil {
+		return nil, fmt.Errorf("reading signature header: %w", err)
+	}
+	tags, err := readRpmHeader(br, false)
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	compressor, ok := tags[rpmTagPayloadCompressor]
+	if !ok {
+		// Older RPMs don't say, they are all gzip
+		compressor = "gzip"
+	}
+	switch compressor {
+	case "gzip":
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("opening gzip payload: %w", err)
+		}
+		return &rpmPayloadReader{Reader: gr}, nil
+	case "bzip2":
+		return &rpmPayloadReader{Reader: bzip2.NewReader(br)}, nil
+	case "xz":
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("opening xz payload: %w", err)
+		}
+		return &rpmPayloadReader{Reader: xr}, nil
+	case "lzma":
+		lr, err := lzma.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("opening lzma payload: %w", err)
+		}
+		return &rpmPayloadReader{Reader: lr}, nil
+	case "zstd":
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("opening zstd payload: %w", err)
+		}
+		return &rpmPayloadReader{Reader: zr, closer: zr.Close}, nil
+	default:
+		return nil, fmt.Errorf("unknown payload compression %s", compressor)
+	}
+}
+
+// readRpmHeader reads an RPM header structure and returns its string tags. The signature header is padded to 8 bytes.
+func readRpmHeader(r io.Reader, padded bool) (map[uint32]string, error) {
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(intro[:4], rpmHeaderMagic) {
+		return nil, fmt.Errorf("bad header magic")
+	}
+	count := binary.BigEndian.Uint32(intro[8:12])
+	size := binary.BigEndian.Uint32(intro[12:16])
+	length := int64(count)*16 + int64(size)
+	if padded {
+		length += (8 - length%8) % 8
+	}
+	// Headers are small, anything large is a corrupt file
+	if length > 64<<20 {
+		return nil, fmt.Errorf("header of %d bytes is too large", length)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	store := b[count*16 : int64(count)*16+int64(size)]
+	result := map[uint32]string{}
+	for i := uint32(0); i < count; i++ {
+		entry := b[i*16 : i*16+16]
+		if binary.BigEndian.Uint32(entry[4:8]) != rpmStringType {
+			continue
+		}
+		offset := binary.BigEndian.Uint32(entry[8:12])
+		if offset >= uint32(len(store)) {
+			return nil, fmt.Errorf("tag %d is outside the header", binary.BigEndian.Uint32(entry[0:4]))
+		}
+		value, _, _ := bytes.Cut(store[offset:], []byte{0})
+		result[binary.BigEndian.Uint32(entry[0:4])] = string(value)
+	}
+	return result, nil
+}
+
+// cpioHeader is a file of a `newc` cpio archive, the format of RPM payloads.
+type cpioHeader struct {
+	Name string
+	Mode uint32
+	Size int64
+}
+
+func (ch *cpioHeader) IsRegular() bool {
+	return ch.Mode&0o170000 == 0o100000
+}
+
+func (ch *cpioHeader) IsSymlink() bool {
+	return ch.Mode&0o170000 == 0o120000
+}
+
+// cpioReader reads a `newc` cpio archive, like tar.Reader Next moves to the next file which is then read from it.
+type cpioReader struct {
+	r         io.Reader
+	remaining int64
+	padding   int64
+}
+
+func newCpioReader(r io.Reader) *cpioReader {
+	return &cpioReader{r: r}
+}
+
+func (cr *cpioReader) Next() (*cpioHeader, error) {
+	if _, err := io.CopyN(io.Discard, cr.r, cr.remaining+cr.padding); err != nil {
+		return nil, fmt.Errorf("skipping file: %w", err)
+	}
+	cr.remaining, cr.padding = 0, 0
+	header := make([]byte, cpioHeaderSize)
+	if _, err := io.ReadFull(cr.r, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch string(header[:6]) {
+	case "070701", "070702":
+	default:
+		return nil, fmt.Errorf("unsupported cpio format %q", header[:6])
+	}
+	// The fields after the magic are 8 hex digits each
+	field := func(i int) (uint32, error) {
+		v, err := strconv.ParseUint(string(header[6+i*8:14+i*8]), 16, 32)
+		return uint32(v), err
+	}
+	mode, err := field(1)
+	if err != nil {
+		return nil, fmt.Errorf("cpio mode: %w", err)
+	}
+	size, err := field(6)
+	if err != nil {
+		return nil, fmt.Errorf("cpio file size: %w", err)
+	}
+	nameSize, err := field(11)
+	if err != nil {
+		return nil, fmt.Errorf("cpio name size: %w", err)
+	}
+	// The header and name are padded to 4 bytes, as is the file
+	name := make([]byte, int64(nameSize)+(4-(cpioHeaderSize+int64(nameSize))%4)%4)
+	if _, err := io.ReadFull(cr.r, name); err != nil {
+		return nil, fmt.Errorf("cpio name: %w", err)
+	}
+	result := &cpioHeader{
+		Name: string(bytes.TrimRight(name[:nameSize], "\x00")),
+		Mode: mode,
+		Size: int64(size),
+	}
+	if result.Name == cpioTrailer {
+		return nil, io.EOF
+	}
+	cr.remaining = result.Size
+	cr.padding = (4 - result.Size%4) % 4
+	return result, nil
+}
+
+func (cr *cpioReader) Read(p []byte) (int, error) {
+	if cr.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > cr.remaining {
+		p = p[:cr.remaining]
+	}
+	n, err := cr.r.Read(p)
+	cr.remaining -= int64(n)
+	return n, err
+}
+
+// readRpm adds the files of the cpio payload of an RPM.
+func (pf *packageFiles) readRpm(r io.Reader) error {
+	payload, err := OpenRpmPayload(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := payload.Close(); err != nil {
+			log.Printf("Error closing rpm payload: %s", err)
+		}
+	}()
+	cr := newCpioReader(payload)
+	for {
+		header, err := cr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading next payload file: %w", err)
+		}
+		switch {
+		case header.IsSymlink():
+			linkname, err := io.ReadAll(cr)
+			if err != nil {
+				return fmt.Errorf("reading link %s: %w", header.Name, err)
+			}
+			pf.addSymlink(header.Name, string(linkname))
+		case header.IsRegular():
+			if err := pf.addFile(header.Name, (header.Mode&0o0500) == 0o0500, cr); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestRpmHeader writes an RPM header structure of string tags, padded to 8 bytes for the signature header.
+func writeTestRpmHeader(buf *bytes.Buffer, tags map[uint32]string, padded bool) {
+	var index, store bytes.Buffer
+	for tag, value := range tags {
+		_ = binary.Write(&index, binary.BigEndian, []uint32{tag, rpmStringType, uint32(store.Len()), 1})
+		store.WriteString(value)
+		store.WriteByte(0)
+	}
+	buf.Write(rpmHeaderMagic)
+	buf.Write(make([]byte, 4))
+	_ = binary.Write(buf, binary.BigEndian, []uint32{uint32(len(tags)), uint32(store.Len())})
+	buf.Write(index.Bytes())
+	buf.Write(store.Bytes())
+	if padded {
+		buf.Write(make([]byte, (8-(index.Len()+store.Len())%8)%8))
+	}
+}
+
+// writeTestCpioFile writes a `newc` cpio entry.
+func writeTestCpioFile(buf *bytes.Buffer, name string, mode uint32, content []byte) {
+	fmt.Fprintf(buf, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x", 0, mode, 0, 0, 1, 0, len(content), 0, 0, 0, 0, len(name)+1, 0)
+	buf.WriteString(name)
+	buf.WriteByte(0)
+	buf.Write(make([]byte, (4-(cpioHeaderSize+len(name)+1)%4)%4))
+	buf.Write(content)
+	buf.Write(make([]byte, (4-len(content)%4)%4))
+}
+
+// newTestRpm is an RPM of the test package files with the payload compressed with the given compression, gzip isn't
+// named in the header as in older RPMs.
+func newTestRpm(t *testing.T, compression string) []byte {
+	t.Helper()
+	var rpm bytes.Buffer
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmLeadMagic)
+	rpm.Write(lead)
+	writeTestRpmHeader(&rpm, map[uint32]string{}, true)
+	tags := map[uint32]string{1000: "viewer"}
+	switch compression {
+	case "xz":
+		tags[rpmTagPayloadCompressor] = "xz"
+	case "zst":
+		tags[rpmTagPayloadCompressor] = "zstd"
+	}
+	writeTestRpmHeader(&rpm, tags, false)
+	var payload bytes.Buffer
+	for _, file := range newTestPackageFiles() {
+		switch {
+		case file.linkname != "":
+			writeTestCpioFile(&payload, file.name, 0o120777, []byte(file.linkname))
+		case strings.HasSuffix(file.name, "/"):
+			writeTestCpioFile(&payload, strings.TrimSuffix(file.name, "/"), 0o040000|uint32(file.mode), nil)
+		default:
+			writeTestCpioFile(&payload, file.name, 0o100000|uint32(file.mode), file.content)
+		}
+	}
+	writeTestCpioFile(&payload, cpioTrailer, 0, nil)
+	cw := newTestCompressWriter(t, &rpm, compression)
+	if _, err := cw.Write(payload.Bytes()); err != nil {
+		t.Fatalf("%s Write() error = %v", compression, err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("%s Close() error = %v", compression, err)
+	}
+	return rpm.Bytes()
+}
+
+func TestOpenRpmPayload(t *testing.T) {
+	for _, compression := range []string{"gz", "xz", "zst"} {
+		t.Run(compression, func(t *testing.T) {
+			payload, err := OpenRpmPayload(bytes.NewReader(newTestRpm(t, compression)))
+			if err != nil {
+				t.Fatalf("OpenRpmPayload() error = %v", err)
+			}
+			defer payload.Close()
+			var got []string
+			gotContent := map[string][]byte{}
+			cr := newCpioReader(payload)
+			for {
+				header, err := cr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("cpio Next() error = %v", err)
+				}
+				content, err := io.ReadAll(cr)
+				if err != nil {
+					t.Fatalf("cpio Read() error = %v", err)
+				}
+				if header.IsSymlink() {
+					got = append(got, header.Name+" -> "+string(content))
+					continue
+				}
+				got = append(got, header.Name)
+				gotContent[header.Name] = content
+			}
+			want := []string{"./usr/bin", "./opt/viewer/viewer", "./usr/bin/viewer -> /opt/viewer/viewer", "./usr/bin/viewer-update", "./usr/share/applications/viewer.desktop", "./usr/share/icons/hicolor/256x256/apps/viewer.png", "./usr/share/man/man1/viewer.1.gz", "./usr/share/doc/viewer/copyright"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("payload files mismatch (-want +got):\n%s", diff)
+			}
+			for _, file := range newTestPackageFiles() {
+				if file.linkname != "" || strings.HasSuffix(file.name, "/") {
+					continue
+				}
+				if !bytes.Equal(gotContent[file.name], file.content) {
+					t.Errorf("payload %s = %q, want %q", file.name, gotContent[file.name], file.content)
+				}
+			}
+		})
+	}
+	if _, err := OpenRpmPayload(bytes.NewReader(newTestDeb(t, "gz"))); err == nil {
+		t.Errorf("OpenRpmPayload() of a .deb should fail")
+	}
+}
+
+func TestDetectRpmBinaryReleaseConfigEntry(t *testing.T) {
+	rpms := map[string][]byte{
+		"/viewer-1.2.0.x86_64.rpm":  newTestRpm(t, "zst"),
+		"/viewer-1.2.0.aarch64.rpm": newTestRpm(t, "gz"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rpm, ok := rpms[r.URL.Path]; ok {
+			_, _ = w.Write(rpm)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	var files []*BinaryReleaseFileInfo
+	for _, name := range []string{"viewer-1.2.0.x86_64.rpm", "viewer-1.2.0.aarch64.rpm"} {
+		files = append(files, &BinaryReleaseFileInfo{Filename: name, DownloadUrl: server.URL + "/" + name})
+	}
+	ic := &InputConfig{Type: "Github Binary Release", GithubRepo: "viewer", Workarounds: map[string]string{}}
+	ic, err := detectBinaryReleaseConfigEntry("viewer", ic, []string{"1.2.0"}, []string{"v1.2.0"}, files)
+	if err != nil {
+		t.Fatalf("detectBinaryReleaseConfigEntry() error = %v", err)
+	}
+	if len(ic.Programs) != 1 {
+		t.Fatalf("Programs = %#v", ic.Programs)
+	}
+	for _, p := range ic.Programs {
+		want := &Program{
+			ProgramName: "viewer",
+			Binary: map[string][]string{
+				"amd64": {"viewer-${VERSION}.x86_64.rpm", "opt/viewer/viewer", "viewer"},
+				"arm64": {"viewer-${VERSION}.aarch64.rpm", "opt/viewer/viewer", "viewer"},
+			},
+			DesktopFile: "viewer.desktop",
+			Icons:       []string{"hicolor-apps"},
+		}
+		got := &Program{ProgramName: p.ProgramName, Binary: p.Binary, DesktopFile: p.DesktopFile, Icons: p.Icons}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("program mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestGenerateRpmBinaryWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(`Type Github Binary Release
+GithubProjectUrl https://github.com/example/viewer
+Category media-gfx
+Binary amd64=>viewer-${VERSION}.x86_64.rpm > opt/viewer/viewer > viewer
+DesktopFile viewer
+`))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "media-gfx-viewer-bin-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		"echo 'EAPI=8'\n                echo ''\n                echo 'inherit rpm xdg-utils'\n",
+		`echo "    rpm_unpack \"\${P}-viewer-\${PV}.x86_64.rpm\""`,
+		`echo '  doins "usr/share/applications/${{ env.desktop_file }}" || die "Failed to install desktop file"'`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("media-gfx-viewer-bin-update.yaml doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(string(b), "unpacker") {
+		t.Errorf("media-gfx-viewer-bin-update.yaml uses unpacker.eclass for an rpm")
+	}
+}
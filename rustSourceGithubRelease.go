@@ -0,0 +1,175 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// RustSource is what was found in the source archive of a Rust crate.
+type RustSource struct {
+	// Package is the `name` of the `[package]` in Cargo.toml.
+	Package string
+	// RustVersion is the `rust-version` of the package, the oldest Rust which can build it.
+	RustVersion string
+	// Crates are the `name@version` of each crates.io package in Cargo.lock, the form the cargo eclass's CRATES uses.
+	Crates []string
+	// GitCrates are the names of the packages in Cargo.lock which come from a git repository, CRATES can't fetch them.
+	GitCrates []string
+}
+
+func ConfigAddRustSourceGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("Github", toConfig, "Github Rust Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+func ConfigViewRustSourceGithubReleases(gitRepo, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("Github", "Github Rust Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+// GenerateRustSourceGithubReleaseConfigEntry reads Cargo.toml and Cargo.lock from the source archive GitHub makes of
+// the release's tag. The CRATES are read from the Cargo.lock by the workflow for each version.
+func GenerateRustSourceGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string) (*InputConfig, error) {
+	_, ic, _, _, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "", "Github Rust Source Release")
+	if err != nil {
+		return config, err
+	}
+	archiveUrl := fmt.Sprintf("https://github.com/%s/%s/archive/refs/tags/%s.tar.gz", ic.GithubOwner, ic.GithubRepo, releaseInfo.GetTagName())
+	log.Printf("Downloading %s", archiveUrl)
+	tempFile, err := util.DownloadUrlToTempFile(archiveUrl)
+	if err != nil {
+		return nil, fmt.Errorf("downloading source archive: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tempFile); err != nil {
+			log.Printf("Error removing temp file: %s", err)
+		}
+	}()
+	f, err := os.Open(tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening source archive: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Printf("Error closing file: %s: %s", tempFile, err)
+		}
+	}()
+	rustSource, err := DetectRustSource(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archiveUrl, err)
+	}
+	log.Printf("Rust package %s with %d crates", rustSource.Package, len(rustSource.Crates))
+	if len(rustSource.GitCrates) > 0 {
+		log.Printf("WARNING: %s come from git repositories and need adding to the ebuild by hand", strings.Join(rustSource.GitCrates, ", "))
+	}
+	rustSource.Apply(ic)
+	return ic, nil
+}
+
+// Apply sets the fields of the entry to what was found in the source.
+func (rs *RustSource) Apply(ic *InputConfig) {
+	if rs.RustVersion != "" {
+		ic.BDepend = append(ic.BDepend, ">=virtual/rust-"+rs.RustVersion)
+	}
+}
+
+// DetectRustSource reads Cargo.toml and Cargo.lock from the top directory of the tar.gz of a Rust crate's source.
+func DetectRustSource(r io.Reader) (*RustSource, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip file: %w", err)
+	}
+	tr := tar.NewReader(gr)
+	var cargoToml, cargoLock []byte
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading next tar file: %w", err)
+		}
+		if header.FileInfo().IsDir() {
+			continue
+		}
+		// GitHub puts everything in a `repo-version` directory
+		name := strings.TrimPrefix(header.Name, "./")
+		if _, rest, found := strings.Cut(name, "/"); found {
+			name = rest
+		}
+		switch name {
+		case "Cargo.toml":
+			if cargoToml, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+			}
+		case "Cargo.lock":
+			if cargoLock, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+			}
+		}
+	}
+	if cargoToml == nil {
+		return nil, fmt.Errorf("no Cargo.toml found")
+	}
+	if cargoLock == nil {
+		return nil, fmt.Errorf("no Cargo.lock found, the CRATES can't be worked out without it")
+	}
+	result := &RustSource{}
+	tables := cargoTomlTables(cargoToml)
+	for _, table := range []string{"package", "workspace.package"} {
+		if len(tables[table]) == 0 {
+			continue
+		}
+		if result.Package == "" {
+			result.Package = tables[table][0]["name"]
+		}
+		if result.RustVersion == "" {
+			result.RustVersion = tables[table][0]["rust-version"]
+		}
+	}
+	for _, lockPackage := range cargoTomlTables(cargoLock)["package"] {
+		source := lockPackage["source"]
+		switch {
+		case strings.HasPrefix(source, "registry+"):
+			result.Crates = append(result.Crates, lockPackage["name"]+"@"+lockPackage["version"])
+		case strings.HasPrefix(source, "git+"):
+			result.GitCrates = append(result.GitCrates, lockPackage["name"])
+		}
+	}
+	return result, nil
+}
+
+// cargoTomlTables reads the `key = "string"` values of each table of a Cargo.toml or Cargo.lock by table name, arrays
+// of tables such as `[[package]]` have an entry for each. Anything other than a string value is skipped, which is all
+// Cargo.lock has and enough of Cargo.toml.
+func cargoTomlTables(b []byte) map[string][]map[string]string {
+	result := map[string][]map[string]string{}
+	var current map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			name := strings.TrimSpace(strings.Trim(line, "[]"))
+			current = map[string]string{}
+			result[name] = append(result[name], current)
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || current == nil {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+			continue
+		}
+		current[strings.TrimSpace(key)] = value[1 : len(value)-1]
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectRustSource(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		dir     string
+		want    *RustSource
+		wantErr bool
+	}{
+		{
+			name: "package",
+			dir:  "testdata/rust-source/tool-0.4.0",
+			want: &RustSource{
+				Package:     "tool",
+				RustVersion: "1.74",
+				Crates:      []string{"anyhow@1.0.86", "clap@4.5.9", "clap_builder@4.5.9"},
+				GitCrates:   []string{"patched"},
+			},
+		},
+		{
+			name: "workspace",
+			dir:  "testdata/rust-source/workspace-2.0.0",
+			want: &RustSource{
+				RustVersion: "1.70.0",
+				Crates:      []string{"log@0.4.22"},
+			},
+		},
+		{
+			name:    "not rust",
+			dir:     "testdata/go-source/tool-0.3.1",
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DetectRustSource(newTestArchiveFromDir(t, test.dir))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DetectRustSource() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("DetectRustSource() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := DetectRustSource(newTestSourceArchive(t, "Cargo.toml", "src/main.rs")); err == nil {
+		t.Errorf("DetectRustSource() without a Cargo.lock should fail")
+	}
+}
+
+const testRustSourceConfigData = `Type Github Rust Source Release
+GithubProjectUrl https://github.com/example/tool
+Category app-misc
+BDepend >=virtual/rust-1.74
+EbuildVariable LICENSE => || ( MIT Apache-2.0 )
+`
+
+func TestParseRustSourceInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testRustSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if ics[0].EbuildName != "tool.ebuild" || !strings.Contains(ics[0].String(), "BDepend >=virtual/rust-1.74\n") {
+		t.Errorf("String() = %s", ics[0].String())
+	}
+	for _, config := range []string{
+		"Type Github Rust Source Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem make\n",
+		"Type Github Rust Source Release\nGithubProjectUrl https://github.com/example/tool\nGoPackages ./cmd/tool\n",
+	} {
+		if _, err := ParseInputConfigReader(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseInputConfigReader(%q) should fail", config)
+		}
+	}
+}
+
+func TestGenerateGithubRustSourceWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testRustSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "app-misc-tool-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		`crates="$(tar -xOf "${source_archive}" "${source_dir}/Cargo.lock" | awk -F ' = ' '`,
+		`echo 'CRATES="'`,
+		`echo 'inherit cargo'`,
+		`echo 'SRC_URI+=" ${CARGO_CRATE_URIS}"'`,
+		`echo 'LICENSE="|| ( MIT Apache-2.0 )"'`,
+		`echo 'BDEPEND=">=virtual/rust-1.74"'`,
+		`g2 manifest upsert-from-url "https://crates.io/api/v1/crates/${crate%@*}/${crate#*@}/download" "${crate%@*}-${crate#*@}.crate" "${ebuild_dir}/Manifest"`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("app-misc-tool-update.yaml doesn't contain %q", want)
+		}
+	}
+}
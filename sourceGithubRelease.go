@@ -0,0 +1,117 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/arran4/arrans_overlay_workflow_builder/util"
+	"io"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+var (
+	// sourceBuildSystemFiles are the files in the top directory of the source which identify each build system, in the
+	// order they are preferred when a project has more than one. A `configure` script without a `configure.ac` is run
+	// by the default phases so it is a `make` project.
+	sourceBuildSystemFiles = []struct {
+		BuildSystem string
+		Filenames   []string
+	}{
+		{"meson", []string{"meson.build"}},
+		{"cmake", []string{"CMakeLists.txt"}},
+		{"autotools", []string{"configure.ac", "configure.in"}},
+		{"make", []string{"configure", "GNUmakefile", "makefile", "Makefile"}},
+	}
+)
+
+func ConfigAddSourceGithubReleases(toConfig, gitRepo, tagOverride, tagPrefix string) error {
+	return configAddForgeReleases("Github", toConfig, "Github Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+func ConfigViewSourceGithubReleases(gitRepo, tagOverride, tagPrefix string) error {
+	return configViewForgeReleases("Github", "Github Source Release", gitRepo, tagOverride, tagPrefix)
+}
+
+// GenerateSourceGithubReleaseConfigEntry detects the build system from the source archive GitHub makes of the release's
+// tag. Depend and BDepend can't be detected so they are left for a human to fill in.
+func GenerateSourceGithubReleaseConfigEntry(gitRepo, tagOverride, prefix string) (*InputConfig, error) {
+	_, ic, _, _, releaseInfo, config, err := NewInputConfigurationFromRepo(gitRepo, tagOverride, prefix, "", "Github Source Release")
+	if err != nil {
+		return config, err
+	}
+	archiveUrl := fmt.Sprintf("https://github.com/%s/%s/archive/refs/tags/%s.tar.gz", ic.GithubOwner, ic.GithubRepo, releaseInfo.GetTagName())
+	log.Printf("Downloading %s", archiveUrl)
+	tempFile, err := util.DownloadUrlToTempFile(archiveUrl)
+	if err != nil {
+		return nil, fmt.Errorf("downloading source archive: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tempFile); err != nil {
+			log.Printf("Error removing temp file: %s", err)
+		}
+	}()
+	f, err := os.Open(tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening source archive: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Printf("Error closing file: %s: %s", tempFile, err)
+		}
+	}()
+	ic.BuildSystem, err = DetectSourceBuildSystem(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archiveUrl, err)
+	}
+	log.Printf("Build system %s", ic.BuildSystem)
+	return ic, nil
+}
+
+// DetectSourceBuildSystem returns which of SourceBuildSystems the tar.gz of a project's source uses, going by the files
+// in the directory the archive's files are in.
+func DetectSourceBuildSystem(r io.Reader) (string, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", fmt.Errorf("opening gzip file: %w", err)
+	}
+	tr := tar.NewReader(gr)
+	var topLevel []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading next tar file: %w", err)
+		}
+		if header.FileInfo().IsDir() {
+			continue
+		}
+		// GitHub puts everything in a `repo-version` directory
+		dir, filename := path.Split(strings.TrimPrefix(header.Name, "./"))
+		if strings.Count(dir, "/") <= 1 {
+			topLevel = append(topLevel, filename)
+		}
+	}
+	for _, bs := range sourceBuildSystemFiles {
+		for _, filename := range bs.Filenames {
+			if slices.Contains(topLevel, filename) {
+				return bs.BuildSystem, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no build system found, looked for: %s", strings.Join(sourceBuildSystemFilenames(), ", "))
+}
+
+func sourceBuildSystemFilenames() []string {
+	var result []string
+	for _, bs := range sourceBuildSystemFiles {
+		result = append(result, bs.Filenames...)
+	}
+	return result
+}
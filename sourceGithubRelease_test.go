@@ -0,0 +1,248 @@
+package arrans_overlay_workflow_builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v62/github"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSourceArchive(t *testing.T, filenames ...string) *bytes.Buffer {
+	t.Helper()
+	var archive bytes.Buffer
+	gw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: "project-1.2.0/", Mode: 0755, Typeflag: tar.TypeDir}); err != nil {
+		t.Fatalf("tar WriteHeader() error = %v", err)
+	}
+	for _, filename := range filenames {
+		if err := tw.WriteHeader(&tar.Header{Name: "project-1.2.0/" + filename, Mode: 0644, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar WriteHeader() error = %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+	return &archive
+}
+
+func TestDetectSourceBuildSystem(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		filenames []string
+		want      string
+		wantErr   bool
+	}{
+		{name: "meson over cmake", filenames: []string{"README.md", "CMakeLists.txt", "meson.build"}, want: "meson"},
+		{name: "cmake", filenames: []string{"src/main.c", "CMakeLists.txt", "Makefile"}, want: "cmake"},
+		{name: "autotools", filenames: []string{"configure.ac", "Makefile.am"}, want: "autotools"},
+		{name: "generated configure", filenames: []string{"configure", "Makefile.in"}, want: "make"},
+		{name: "makefile", filenames: []string{"Makefile", "main.c"}, want: "make"},
+		{name: "only in a sub directory", filenames: []string{"src/meson.build", "docs/Makefile"}, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DetectSourceBuildSystem(newTestSourceArchive(t, test.filenames...))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DetectSourceBuildSystem() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("DetectSourceBuildSystem() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+const testSourceConfigData = `Type Github Source Release
+GithubProjectUrl https://github.com/lojban/jbofihe
+Category app-text
+Description The de facto standard parser and glosser for Lojban.
+BuildSystem autotools
+Depend virtual/libc
+BDepend dev-lang/perl
+BDepend sys-devel/bison sys-devel/flex
+License GPL-2
+Dependencies app-dicts/lojban-dict
+
+Type Github Source Release
+GithubProjectUrl https://github.com/example/tool
+BuildSystem make
+`
+
+func TestParseSourceInputConfig(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	ic := ics[0]
+	got := []string{ic.EbuildName, ic.BuildSystem, strings.Join(ic.Depend, " "), strings.Join(ic.BDepend, " ")}
+	want := []string{"jbofihe.ebuild", "autotools", "virtual/libc", "dev-lang/perl sys-devel/bison sys-devel/flex"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parsed entry mismatch (-want +got):\n%s", diff)
+	}
+	for _, want := range []string{"BuildSystem autotools\nDepend virtual/libc\nBDepend dev-lang/perl sys-devel/bison sys-devel/flex\n", "Dependencies app-dicts/lojban-dict\n"} {
+		if !strings.Contains(ic.String(), want) {
+			t.Errorf("String() = %s, doesn't contain %q", ic.String(), want)
+		}
+	}
+	for _, config := range []string{
+		"Type Github Source Release\nGithubProjectUrl https://github.com/example/tool\n",
+		"Type Github Source Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem scons\n",
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem make\nBinary amd64=>tool > tool\n",
+		"Type Github Source Release\nGithubProjectUrl https://github.com/example/tool\nBuildSystem make\nSourceUrl https://example.org/tool-${VERSION}.zip\n",
+		"Type Github Binary Release\nGithubProjectUrl https://github.com/example/tool\nSourceUrl https://example.org/tool-${VERSION}.tar.gz\nBinary amd64=>tool > tool\n",
+	} {
+		if _, err := ParseInputConfigReader(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseInputConfigReader(%q) should fail", config)
+		}
+	}
+}
+
+func TestGenerateGithubSourceWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(testSourceConfigData))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	for _, ic := range ics {
+		if err := ic.GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+			t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+		}
+	}
+	for filename, test := range map[string]struct {
+		wants    []string
+		notWants []string
+	}{
+		"app-text-jbofihe-update.yaml": {
+			wants: []string{
+				`echo 'inherit autotools'`,
+				`echo "SRC_URI=\"https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/archive/refs/tags/${tag}.tar.gz -> \${P}.tar.gz\""`,
+				`echo 'LICENSE="GPL-2"'`,
+				`echo 'DEPEND="virtual/libc"'`,
+				`echo 'RDEPEND="${DEPEND} app-dicts/lojban-dict"'`,
+				`echo 'BDEPEND="dev-lang/perl sys-devel/bison sys-devel/flex"'`,
+				`echo '  eautoreconf'`,
+				`g2 manifest upsert-from-url "https://github.com/${{ env.github_owner }}/${{ env.github_repo }}/archive/refs/tags/${tag}.tar.gz" "${{ env.epn }}-${version}.tar.gz" "${ebuild_dir}/Manifest"`,
+			},
+		},
+		"app-misc-tool-update.yaml": {
+			wants:    []string{`echo 'RDEPEND="${DEPEND}"'`, `echo 'LICENSE="MIT"'`},
+			notWants: []string{"inherit", "src_prepare"},
+		},
+	} {
+		b, err := os.ReadFile(filepath.Join(outputDir, filename))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		for _, want := range test.wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q", filename, want)
+			}
+		}
+		for _, notWant := range test.notWants {
+			if strings.Contains(string(b), notWant) {
+				t.Errorf("%s contains %q", filename, notWant)
+			}
+		}
+	}
+}
+
+func TestGenerateTagsWithoutReleasesSourceWorkflow(t *testing.T) {
+	ics, err := ParseInputConfigReader(strings.NewReader(`Type Github Source Release
+GithubProjectUrl https://github.com/example/tagged
+BuildSystem make
+SourceUrl https://example.org/dist/tagged-${VERSION}.tar.xz
+Workaround Tags Without Releases
+`))
+	if err != nil {
+		t.Fatalf("ParseInputConfigReader() error = %v", err)
+	}
+	if want := "SourceUrl https://example.org/dist/tagged-${VERSION}.tar.xz\n"; !strings.Contains(ics[0].String(), want) {
+		t.Errorf("String() = %s, doesn't contain %q", ics[0].String(), want)
+	}
+	templates, err := ParseWorkflowTemplates()
+	if err != nil {
+		t.Fatalf("ParseWorkflowTemplates() error = %v", err)
+	}
+	outputDir := t.TempDir()
+	if err := ics[0].GenerateGithubWorkflow("input.config", time.Time{}, templates, outputDir, "test"); err != nil {
+		t.Fatalf("GenerateGithubWorkflow() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outputDir, "app-misc-tagged-update.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		`"https://api.github.com/repos/${{ env.github_owner }}/${{ env.github_repo }}/tags?per_page=100&page=${page}" | jq -r '.[].name')" && [ -n "${page_tags}" ]; do`,
+		`tags="$(echo "${tags}" | sort -rV)"`,
+		`if ! wget "https://example.org/dist/tagged-${version}.tar.xz" -O "${source_archive}"; then`,
+		`echo "Couldn't download the source of ${tag} skipping"`,
+		`source_dir="$(tar -tf "${source_archive}" | sed 's:^\./::' | head -n 1 | cut -d/ -f1)"`,
+		`echo "SRC_URI=\"https://example.org/dist/tagged-${version}.tar.xz -> \${P}.tar.xz\""`,
+		`g2 manifest upsert-from-url "https://example.org/dist/tagged-${version}.tar.xz" "${{ env.epn }}-${version}.tar.xz" "${ebuild_dir}/Manifest"`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("app-misc-tagged-update.yaml doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(string(b), "/releases |") {
+		t.Errorf("app-misc-tagged-update.yaml lists the releases")
+	}
+
+	ic := &InputConfig{Type: "Github Binary Release", Workarounds: map[string]string{"Tags Without Releases": ""}}
+	if err := ic.Validate(); err == nil {
+		t.Errorf("Validate() should fail for workaround Tags Without Releases on a binary type")
+	}
+}
+
+func TestLatestTagRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/example/tagged/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"name": "v1.10.0"}, {"name": "nightly"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2&per_page=100>; rel="next"`, r.Host, r.URL.Path))
+		_, _ = w.Write([]byte(`[{"name": "v1.9.0"}, {"name": "tool-v2.0.0"}]`))
+	}))
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	release, err := latestTagRelease(context.Background(), client, "example", "tagged", "")
+	if err != nil {
+		t.Fatalf("latestTagRelease() error = %v", err)
+	}
+	if got := release.GetTagName(); got != "v1.10.0" {
+		t.Errorf("latestTagRelease() = %s, want v1.10.0", got)
+	}
+	release, err = latestTagRelease(context.Background(), client, "example", "tagged", "tool-")
+	if err != nil {
+		t.Fatalf("latestTagRelease() error = %v", err)
+	}
+	if got := release.GetTagName(); got != "tool-v2.0.0" {
+		t.Errorf("latestTagRelease() with a prefix = %s, want tool-v2.0.0", got)
+	}
+	if _, err := latestTagRelease(context.Background(), client, "example", "tagged", "other-"); err == nil {
+		t.Errorf("latestTagRelease() without a matching tag should fail")
+	}
+}
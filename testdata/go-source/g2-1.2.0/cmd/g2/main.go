@@ -0,0 +1,7 @@
+package main
+
+var version = "dev"
+
+func main() {
+	println(version)
+}
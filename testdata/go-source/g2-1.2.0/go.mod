@@ -0,0 +1,8 @@
+module github.com/arran4/g2
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	golang.org/x/sync v0.7.0
+)
@@ -0,0 +1,5 @@
+module example.com/tool // the tool
+
+go 1.21.5
+
+require github.com/example/dep v0.1.0
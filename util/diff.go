@@ -0,0 +1,126 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnifiedDiff returns a unified diff of the lines of before and after with 3 lines of context, or an empty string if
+// they are the same.
+func UnifiedDiff(beforeName, afterName, before, after string) string {
+	a := splitDiffLines(before)
+	b := splitDiffLines(after)
+	ops := diffLines(a, b)
+	const context = 3
+	var sb strings.Builder
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start >= len(ops) {
+			break
+		}
+		hunkStart := max(start-context, 0)
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= context*2 {
+				break
+			}
+		}
+		hunkEnd := min(end+context, len(ops))
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", beforeName, afterName))
+		}
+		aStart, bStart := ops[hunkStart].aLine, ops[hunkStart].bLine
+		aCount, bCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount))
+		for _, op := range ops[hunkStart:hunkEnd] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteString("\n")
+		}
+		start = hunkEnd
+	}
+	return sb.String()
+}
+
+type diffOp struct {
+	kind byte
+	text string
+	// aLine and bLine are the 1 based line numbers in before and after this operation is at.
+	aLine int
+	bLine int
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines keeps the common prefix and suffix of the lines and uses the longest common subsequence of the lines
+// between them, so the table is only as large as the region which changed.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: ' ', text: a[i], aLine: i + 1, bLine: i + 1})
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ops = append(ops, diffOp{kind: ' ', text: ma[i], aLine: prefix + i + 1, bLine: prefix + j + 1})
+			i++
+			j++
+		case i < len(ma) && (j >= len(mb) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', text: ma[i], aLine: prefix + i + 1, bLine: prefix + j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: mb[j], aLine: prefix + i + 1, bLine: prefix + j + 1})
+			j++
+		}
+	}
+	for k := len(a) - suffix; k < len(a); k++ {
+		ops = append(ops, diffOp{kind: ' ', text: a[k], aLine: k + 1, bLine: k - len(a) + len(b) + 1})
+	}
+	return ops
+}
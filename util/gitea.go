@@ -0,0 +1,29 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ExtractGiteaOwnerRepo extracts the base URL of the instance, the owner and the repository from the URL of a
+// repository on a Gitea compatible forge such as Codeberg or a self-hosted Forgejo.
+func ExtractGiteaOwnerRepo(giteaURL string) (string, string, string, error) {
+	parsedURL, err := url.Parse(giteaURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		return "", "", "", fmt.Errorf("not a valid Gitea URL: %s", giteaURL)
+	}
+
+	// Split the path and get the owner and repo
+	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(pathParts) < 2 || pathParts[0] == "" {
+		return "", "", "", fmt.Errorf("URL does not contain enough parts to extract owner and repo: %s", giteaURL)
+	}
+
+	baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+	return baseURL, pathParts[0], strings.TrimSuffix(pathParts[1], ".git"), nil
+}
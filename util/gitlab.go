@@ -0,0 +1,31 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ExtractGitlabProject extracts the base URL of the GitLab instance and the project path, which includes any subgroups,
+// from a GitLab project URL such as https://gitlab.com/group/subgroup/project. Any host is accepted so self-hosted
+// instances work.
+func ExtractGitlabProject(gitlabURL string) (string, string, error) {
+	parsedURL, err := url.Parse(gitlabURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		return "", "", fmt.Errorf("not a valid GitLab URL: %s", gitlabURL)
+	}
+
+	// Anything after `/-/` is a page of the project rather than part of its path
+	projectPath, _, _ := strings.Cut(strings.Trim(parsedURL.Path, "/"), "/-/")
+	projectPath = strings.TrimSuffix(strings.Trim(projectPath, "/"), ".git")
+	if len(strings.Split(projectPath, "/")) < 2 {
+		return "", "", fmt.Errorf("URL does not contain enough parts to extract group and project: %s", gitlabURL)
+	}
+
+	baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+	return baseURL, projectPath, nil
+}
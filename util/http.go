@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -38,3 +39,32 @@ func DownloadUrlToTempFile(url string) (string, error) {
 
 	return file.Name(), nil
 }
+
+// ReadUrlPrefix reads the first n bytes of the url, only they are requested with a Range header so large files aren't
+// downloaded. Less is returned if the file is shorter.
+func ReadUrlPrefix(url string, n int) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=0-%d", n-1))
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %v", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("File download close issue: %s", err)
+		}
+	}(response.Body)
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("failed to download file: %s", response.Status)
+	}
+	prefix := make([]byte, n)
+	read, err := io.ReadFull(response.Body, prefix)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading %s: %v", url, err)
+	}
+	return prefix[:read], nil
+}
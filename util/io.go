@@ -1,10 +1,12 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 func SaveReaderToTempFile(reader io.Reader) (string, error) {
@@ -35,3 +37,44 @@ type ReaderCloser struct {
 func (oc *ReaderCloser) Close() error {
 	return oc.Closer()
 }
+
+// WriteFileAtomic writes data to a temporary file next to filename and renames it into place, so readers never see a
+// partially written file. The permissions of an existing file are kept, otherwise perm is used.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	if fi, err := os.Stat(filename); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	tmpName := file.Name()
+	cleanup := func() {
+		if err := os.Remove(tmpName); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Temp file remove issue: %s", err)
+		}
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		cleanup()
+		return fmt.Errorf("writing to file: %v: %s", tmpName, err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		cleanup()
+		return fmt.Errorf("syncing file: %v: %s", tmpName, err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("closing file: %v: %s", tmpName, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		cleanup()
+		return fmt.Errorf("setting permissions on %s: %v", tmpName, err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		cleanup()
+		return fmt.Errorf("replacing %s: %v", filename, err)
+	}
+	return nil
+}